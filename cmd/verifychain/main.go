@@ -0,0 +1,89 @@
+// Command verifychain walks the operation hash chains and, optionally, the
+// latest checkpoint. It prints the result as JSON and exits with status 1 when
+// anything is broken, so it can run from cron or CI.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"wallet-go/internal/integrity"
+	"wallet-go/internal/operation"
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/database"
+
+	"github.com/google/uuid"
+)
+
+func main() {
+	walletFlag := flag.String("wallet", "", "verify only this wallet ID")
+	checkpointFlag := flag.String("checkpoint", "", "also verify this checkpoint ID")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Invalid configuration:\n", err)
+	}
+
+	mongoClient, err := database.NewMongoClient(cfg.MongoDB)
+	if err != nil {
+		log.Fatal("Failed to connect to MongoDB:", err)
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	signer, err := integrity.LoadSigner(cfg.Integrity.SigningKeyFile)
+	if err != nil {
+		log.Fatal("Failed to load checkpoint signing key:", err)
+	}
+
+	operationService := operation.NewService(operation.NewStore(mongoClient), nil)
+	service := integrity.NewService(integrity.NewStore(mongoClient), operationService, signer)
+	ctx := context.Background()
+
+	valid := true
+	output := map[string]interface{}{}
+
+	if *walletFlag != "" {
+		walletID, err := uuid.Parse(*walletFlag)
+		if err != nil {
+			log.Fatal("Invalid wallet ID:", err)
+		}
+		result, err := service.VerifyWallet(ctx, walletID)
+		if err != nil {
+			log.Fatal("Failed to verify wallet chain:", err)
+		}
+		valid = valid && result.Valid
+		output["chain"] = result
+	} else {
+		result, err := service.VerifyAll(ctx)
+		if err != nil {
+			log.Fatal("Failed to verify chains:", err)
+		}
+		valid = valid && result.Valid
+		output["chains"] = result
+	}
+
+	if *checkpointFlag != "" {
+		checkpointID, err := uuid.Parse(*checkpointFlag)
+		if err != nil {
+			log.Fatal("Invalid checkpoint ID:", err)
+		}
+		result, err := service.VerifyCheckpoint(ctx, checkpointID)
+		if err != nil {
+			log.Fatal("Failed to verify checkpoint:", err)
+		}
+		valid = valid && result.Valid
+		output["checkpoint"] = result
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(output)
+
+	if !valid {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,363 @@
+// Command walletctl is the support CLI for wallets. It goes through the same
+// services as the API, so validations, operations and audit entries are the
+// same as for a request made by the -actor.
+//
+//	walletctl wallet create -customer <id> [-tz <zone>]
+//	walletctl wallet list
+//	walletctl wallet get -id <wallet-id>
+//	walletctl wallet block|unblock -id <wallet-id> -reason <text>
+//	walletctl wallet adjust -id <wallet-id> -amount <cents> -reason <text>
+//	walletctl operation get -id <operation-id>
+//	walletctl operation list -wallet <wallet-id>
+//	walletctl reconcile [-wallet <wallet-id>]
+//	walletctl dlq list [-status PENDING] [-topic <topic>] [-limit 100]
+//	walletctl dlq get -id <dead-letter-id>
+//	walletctl dlq replay -id <dead-letter-id>
+//	walletctl dlq discard -id <dead-letter-id> -reason <text>
+//	walletctl migrate up|status
+//
+// Global flags go before the resource: -o table|json selects the output and
+// -actor names who is acting (default $USER). The MongoDB and Kafka settings
+// come from the environment or CONFIG_FILE, like the API. reconcile exits
+// with status 1 when a balance does not match its operations.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"wallet-go/internal/bootstrap"
+	"wallet-go/internal/deadletter"
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/errors"
+	"wallet-go/internal/wallet"
+
+	"github.com/google/uuid"
+)
+
+func main() {
+	format := flag.String("o", "table", "output format: table or json")
+	actorFlag := flag.String("actor", os.Getenv("USER"), "name recorded as the actor of changes")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *format != "table" && *format != "json" {
+		fail(fmt.Errorf("invalid output format %q, expected table or json", *format))
+	}
+	if flag.NArg() < 1 {
+		usage()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fail(fmt.Errorf("invalid configuration:\n%w", err))
+	}
+
+	c, err := bootstrap.New(config.NewReloader(cfg))
+	if err != nil {
+		fail(err)
+	}
+	defer c.Close(context.Background())
+
+	// Changes made from the CLI are attributed to the operator
+	ctx := context.Background()
+	if strings.TrimSpace(*actorFlag) != "" {
+		ctx = auth.WithActor(ctx, &auth.Actor{
+			Subject: "walletctl:" + *actorFlag,
+			Kind:    auth.PrincipalKindUser,
+			Roles:   []auth.Role{auth.RoleSupport},
+		})
+	}
+
+	cli := &cli{container: c, out: newOutput(os.Stdout, *format)}
+	resource, args := flag.Arg(0), flag.Args()[1:]
+
+	switch resource {
+	case "wallet":
+		err = cli.wallet(ctx, args)
+	case "operation":
+		err = cli.operation(ctx, args)
+	case "reconcile":
+		err = cli.reconcile(ctx, args)
+	case "dlq":
+		err = cli.deadLetters(ctx, args)
+	case "migrate":
+		err = cli.migrate(ctx, args)
+	default:
+		usage()
+	}
+
+	if err != nil {
+		c.Close(context.Background())
+		fail(err)
+	}
+}
+
+type cli struct {
+	container *bootstrap.Container
+	out       *output
+}
+
+func (c *cli) wallet(ctx context.Context, args []string) error {
+	command, flags := subcommand("wallet", args)
+	id := flags.String("id", "", "wallet ID")
+	customer := flags.String("customer", "", "customer ID of the new wallet")
+	timeZone := flags.String("tz", "", "IANA time zone of the new wallet")
+	amount := flags.Int64("amount", 0, "adjustment in cents, negative to debit")
+	reason := flags.String("reason", "", "reason stored in the audit log")
+	flags.Parse(args[1:])
+
+	service := c.container.WalletService
+
+	switch command {
+	case "create":
+		created, err := service.Create(ctx, wallet.WalletRequest{CustomerID: *customer, TimeZone: *timeZone})
+		if err != nil {
+			return err
+		}
+		return c.out.wallet(created)
+
+	case "list":
+		wallets, err := service.List(ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.wallets(wallets)
+
+	case "get":
+		walletID, err := parseID("wallet", *id)
+		if err != nil {
+			return err
+		}
+		found, err := service.GetByID(ctx, walletID)
+		if err != nil {
+			return err
+		}
+		return c.out.wallet(found)
+
+	case "block", "unblock":
+		walletID, err := parseID("wallet", *id)
+		if err != nil {
+			return err
+		}
+		blocked := command == "block"
+		patched, err := service.Patch(ctx, walletID, wallet.WalletPatch{Blocked: &blocked, Reason: *reason})
+		if err != nil {
+			return err
+		}
+		return c.out.wallet(patched)
+
+	case "adjust":
+		walletID, err := parseID("wallet", *id)
+		if err != nil {
+			return err
+		}
+		adjusted, err := service.Adjust(ctx, walletID, wallet.AdjustmentRequest{AmountInCents: *amount, Reason: *reason})
+		if err != nil {
+			return err
+		}
+		return c.out.wallet(adjusted)
+
+	default:
+		usage()
+		return nil
+	}
+}
+
+func (c *cli) operation(ctx context.Context, args []string) error {
+	command, flags := subcommand("operation", args)
+	id := flags.String("id", "", "operation ID")
+	walletFlag := flags.String("wallet", "", "wallet ID")
+	flags.Parse(args[1:])
+
+	service := c.container.OperationService
+
+	switch command {
+	case "get":
+		operationID, err := parseID("operation", *id)
+		if err != nil {
+			return err
+		}
+		found, err := service.GetByID(ctx, operationID)
+		if err != nil {
+			return err
+		}
+		return c.out.operation(found)
+
+	case "list":
+		walletID, err := parseID("wallet", *walletFlag)
+		if err != nil {
+			return err
+		}
+		operations, err := service.GetByWalletID(ctx, walletID)
+		if err != nil {
+			return err
+		}
+		return c.out.operations(operations)
+
+	default:
+		usage()
+		return nil
+	}
+}
+
+func (c *cli) reconcile(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	walletFlag := flags.String("wallet", "", "reconcile only this wallet ID")
+	flags.Parse(args)
+
+	var walletIDs []uuid.UUID
+	if *walletFlag != "" {
+		walletID, err := parseID("wallet", *walletFlag)
+		if err != nil {
+			return err
+		}
+		walletIDs = append(walletIDs, walletID)
+	}
+
+	result, err := c.container.WalletService.Reconcile(ctx, walletIDs)
+	if err != nil {
+		return err
+	}
+	if err := c.out.reconciliation(result); err != nil {
+		return err
+	}
+
+	if !result.Balanced {
+		c.container.Close(context.Background())
+		os.Exit(1)
+	}
+	return nil
+}
+
+func (c *cli) deadLetters(ctx context.Context, args []string) error {
+	command, flags := subcommand("dlq", args)
+	id := flags.String("id", "", "dead letter ID")
+	status := flags.String("status", string(deadletter.StatusPending), "PENDING, REPLAYED or DISCARDED; empty for all")
+	topic := flags.String("topic", "", "only messages of this topic")
+	limit := flags.Int64("limit", 100, "maximum number of messages")
+	reason := flags.String("reason", "", "why the message is discarded")
+	flags.Parse(args[1:])
+
+	service := c.container.DeadLetterService
+
+	switch command {
+	case "list":
+		deadLetters, err := service.List(ctx, deadletter.DeadLetterFilter{
+			Status: deadletter.Status(strings.ToUpper(*status)),
+			Topic:  *topic,
+			Limit:  *limit,
+		})
+		if err != nil {
+			return err
+		}
+		return c.out.deadLetters(deadLetters)
+
+	case "get", "replay", "discard":
+		deadLetterID, err := parseID("dead letter", *id)
+		if err != nil {
+			return err
+		}
+
+		var result *deadletter.DeadLetter
+		switch command {
+		case "get":
+			result, err = service.GetByID(ctx, deadLetterID)
+		case "replay":
+			// walletctl runs no consumers, so the in-process bus would drop it
+			if c.container.Config.Kafka.Backend == "memory" {
+				return fmt.Errorf("dlq replay needs KAFKA_BACKEND=kafka")
+			}
+			result, err = service.Replay(ctx, deadLetterID)
+		case "discard":
+			result, err = service.Discard(ctx, deadLetterID, *reason)
+		}
+		if err != nil {
+			return err
+		}
+		return c.out.deadLetter(result)
+
+	default:
+		usage()
+		return nil
+	}
+}
+
+func (c *cli) migrate(ctx context.Context, args []string) error {
+	command, flags := subcommand("migrate", args)
+	flags.Parse(args[1:])
+
+	migrator := c.container.Migrator
+
+	switch command {
+	case "up":
+		if _, err := migrator.Up(ctx); err != nil {
+			return err
+		}
+		// Print the whole history, so the output shows what is now applied
+		fallthrough
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.migrations(statuses)
+
+	default:
+		usage()
+		return nil
+	}
+}
+
+// subcommand returns the command of a resource and the flag set for its
+// arguments
+func subcommand(resource string, args []string) (string, *flag.FlagSet) {
+	if len(args) == 0 {
+		usage()
+	}
+	return args[0], flag.NewFlagSet(resource+" "+args[0], flag.ExitOnError)
+}
+
+func parseID(name, value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, fmt.Errorf("a %s ID is required", name)
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid %s ID %q", name, value)
+	}
+	return id, nil
+}
+
+// fail prints the message of service errors without their HTTP details
+func fail(err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		fmt.Fprintln(os.Stderr, "walletctl:", appErr.Message)
+	} else {
+		fmt.Fprintln(os.Stderr, "walletctl:", err)
+	}
+	os.Exit(1)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: walletctl [-o table|json] [-actor name] <command>
+
+  wallet create -customer <id> [-tz <zone>]
+  wallet list
+  wallet get -id <wallet-id>
+  wallet block|unblock -id <wallet-id> -reason <text>
+  wallet adjust -id <wallet-id> -amount <cents> -reason <text>
+  operation get -id <operation-id>
+  operation list -wallet <wallet-id>
+  reconcile [-wallet <wallet-id>]
+  dlq list [-status PENDING|REPLAYED|DISCARDED] [-topic <topic>] [-limit <n>]
+  dlq get|replay -id <dead-letter-id>
+  dlq discard -id <dead-letter-id> -reason <text>
+  migrate up|status`)
+	os.Exit(2)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"wallet-go/internal/deadletter"
+	"wallet-go/internal/migration"
+	"wallet-go/internal/operation"
+	"wallet-go/internal/wallet"
+)
+
+// output prints results as a table for people or as JSON for scripts
+type output struct {
+	w      io.Writer
+	format string
+}
+
+func newOutput(w io.Writer, format string) *output {
+	return &output{w: w, format: format}
+}
+
+// wallet prints a single result, as an object in JSON. operation and
+// deadLetter do the same; the plural forms print lists.
+func (o *output) wallet(w *wallet.Wallet) error {
+	if o.format == "json" {
+		return o.json(w)
+	}
+	return o.wallets([]*wallet.Wallet{w})
+}
+
+func (o *output) wallets(wallets []*wallet.Wallet) error {
+	if o.format == "json" {
+		return o.json(wallets)
+	}
+
+	rows := make([][]string, len(wallets))
+	for i, w := range wallets {
+		rows[i] = []string{
+			w.WalletID.String(),
+			w.CustomerID,
+			strconv.FormatInt(w.CurrentAmountInCents, 10),
+			strconv.FormatBool(w.Active),
+			strconv.FormatBool(w.Blocked),
+			w.TimeZone,
+			formatTime(w.UpdatedAt),
+		}
+	}
+	return o.table([]string{"WALLET", "CUSTOMER", "BALANCE (CENTS)", "ACTIVE", "BLOCKED", "TIME ZONE", "UPDATED"}, rows)
+}
+
+func (o *output) operation(op *operation.Operation) error {
+	if o.format == "json" {
+		return o.json(op)
+	}
+	return o.operations([]operation.Operation{*op})
+}
+
+func (o *output) operations(operations []operation.Operation) error {
+	if o.format == "json" {
+		return o.json(operations)
+	}
+
+	rows := make([][]string, len(operations))
+	for i, op := range operations {
+		rows[i] = []string{
+			op.OperationID.String(),
+			op.WalletID.String(),
+			string(op.Type),
+			string(op.Status),
+			strconv.FormatInt(op.AmountInCents, 10),
+			op.Reason,
+			formatTime(op.CreatedAt),
+		}
+	}
+	return o.table([]string{"OPERATION", "WALLET", "TYPE", "STATUS", "AMOUNT (CENTS)", "REASON", "CREATED"}, rows)
+}
+
+func (o *output) reconciliation(result *wallet.Reconciliation) error {
+	if o.format == "json" {
+		return o.json(result)
+	}
+
+	rows := make([][]string, len(result.Wallets))
+	for i, item := range result.Wallets {
+		rows[i] = []string{
+			item.WalletID.String(),
+			strconv.FormatInt(item.BalanceInCents, 10),
+			strconv.FormatInt(item.LedgerInCents, 10),
+			strconv.FormatInt(item.DifferenceInCents, 10),
+			strconv.FormatInt(item.OperationCount, 10),
+			strconv.FormatBool(item.Balanced),
+		}
+	}
+	if err := o.table([]string{"WALLET", "BALANCE (CENTS)", "OPERATIONS SUM", "DIFFERENCE", "OPERATIONS", "BALANCED"}, rows); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(o.w, "\n%d wallets checked, %d mismatched\n", result.WalletCount, result.MismatchCount)
+	return err
+}
+
+func (o *output) deadLetter(d *deadletter.DeadLetter) error {
+	if o.format == "json" {
+		return o.json(d)
+	}
+	return o.deadLetters([]*deadletter.DeadLetter{d})
+}
+
+func (o *output) deadLetters(deadLetters []*deadletter.DeadLetter) error {
+	if o.format == "json" {
+		return o.json(deadLetters)
+	}
+
+	rows := make([][]string, len(deadLetters))
+	for i, d := range deadLetters {
+		rows[i] = []string{
+			d.DeadLetterID.String(),
+			d.Topic,
+			fmt.Sprintf("%d/%d", d.Partition, d.Offset),
+			string(d.Status),
+			d.Error,
+			formatTime(d.CreatedAt),
+		}
+	}
+	return o.table([]string{"DEAD LETTER", "TOPIC", "PARTITION/OFFSET", "STATUS", "ERROR", "CREATED"}, rows)
+}
+
+func (o *output) migrations(statuses []migration.Status) error {
+	if o.format == "json" {
+		return o.json(statuses)
+	}
+
+	rows := make([][]string, len(statuses))
+	for i, status := range statuses {
+		appliedAt := ""
+		if status.AppliedAt != nil {
+			appliedAt = formatTime(*status.AppliedAt)
+		}
+		rows[i] = []string{
+			strconv.Itoa(status.Version),
+			status.Description,
+			strconv.FormatBool(status.Applied),
+			appliedAt,
+		}
+	}
+	return o.table([]string{"VERSION", "DESCRIPTION", "APPLIED", "APPLIED AT"}, rows)
+}
+
+func (o *output) json(value interface{}) error {
+	encoder := json.NewEncoder(o.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func (o *output) table(headers []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
@@ -0,0 +1,92 @@
+// Command walletkeys manages the field encryption key file.
+//
+//	walletkeys generate -file keys.json   create a key file
+//	walletkeys rotate -file keys.json     add a wrapping key and make it current
+//	walletkeys rewrap                     encrypt legacy customer IDs and rewrap
+//	                                      data keys wrapped with retired keys
+//
+// rewrap reads ENCRYPTION_KEY_FILE and the MongoDB settings from the
+// environment, like the API. Rotate the file, roll it out to every instance,
+// then run rewrap; old keys can be removed from the file afterwards.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/database"
+	"wallet-go/internal/shared/encryption"
+	"wallet-go/internal/wallet"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	file := flags.String("file", os.Getenv("ENCRYPTION_KEY_FILE"), "key file path")
+	flags.Parse(args)
+
+	switch command {
+	case "generate":
+		if err := encryption.GenerateKeyFile(*file); err != nil {
+			log.Fatal("Failed to generate key file:", err)
+		}
+		fmt.Printf("Key file written to %s\n", *file)
+
+	case "rotate":
+		keyID, err := encryption.RotateKeyFile(*file)
+		if err != nil {
+			log.Fatal("Failed to rotate key file:", err)
+		}
+		fmt.Printf("Key %s added to %s and made current\n", keyID, *file)
+
+	case "rewrap":
+		rewrap(*file)
+
+	default:
+		usage()
+	}
+}
+
+func rewrap(file string) {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Invalid configuration:\n", err)
+	}
+
+	cipher, err := encryption.LoadFieldCipher(file)
+	if err != nil {
+		log.Fatal("Failed to load encryption keys:", err)
+	}
+	if cipher == nil {
+		log.Fatal("No key file given, use -file or ENCRYPTION_KEY_FILE")
+	}
+
+	mongoClient, err := database.NewMongoClient(cfg.MongoDB)
+	if err != nil {
+		log.Fatal("Failed to connect to MongoDB:", err)
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	result, err := wallet.NewStore(mongoClient, cipher).RewrapCustomerIDs(context.Background())
+	if err != nil {
+		log.Fatal("Failed to rewrap customer IDs:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(result)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: walletkeys generate|rotate|rewrap [-file keys.json]")
+	os.Exit(2)
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"wallet-go/internal/app"
+)
+
+// The worker consumes the deposit, withdraw and transfer topics and runs the
+// webhook deliveries, chain checkpoints and approval expiry. Its probes and
+// metrics are served on WORKER_PORT.
+func main() {
+	if err := app.Run(app.ModeWorker); err != nil {
+		slog.Error("Worker stopped with an error", "error", err)
+		os.Exit(1)
+	}
+}
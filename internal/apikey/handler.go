@@ -0,0 +1,178 @@
+package apikey
+
+import (
+	"net/http"
+
+	"wallet-go/internal/shared/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Create godoc
+// @Summary Create API key
+// @Description Create a service credential. The key is only returned in this response.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body APIKeyRequest true "API key request"
+// @Success 201 {object} APIKeyResponse
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/api-keys [post]
+func (h *Handler) Create(c *gin.Context) {
+	var request APIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	key, plaintext, err := h.service.Create(c.Request.Context(), request)
+	if err != nil {
+		h.handleError(c, err, "Failed to create API key")
+		return
+	}
+
+	response := h.mapToResponse(key)
+	response.Key = plaintext
+	c.JSON(http.StatusCreated, response)
+}
+
+// List godoc
+// @Summary List API keys
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} APIKeyResponse
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/api-keys [get]
+func (h *Handler) List(c *gin.Context) {
+	keys, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to list API keys")
+		return
+	}
+
+	responses := make([]APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = *h.mapToResponse(key)
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetByID godoc
+// @Summary Get API key
+// @Tags Admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} APIKeyResponse
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /admin/api-keys/{id} [get]
+func (h *Handler) GetByID(c *gin.Context) {
+	keyID, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.service.GetByID(c.Request.Context(), keyID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.mapToResponse(key))
+}
+
+// Rotate godoc
+// @Summary Rotate API key
+// @Description Issue a new secret for the key; the previous one stops working immediately
+// @Tags Admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} APIKeyResponse
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /admin/api-keys/{id}/rotate [post]
+func (h *Handler) Rotate(c *gin.Context) {
+	keyID, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	key, plaintext, err := h.service.Rotate(c.Request.Context(), keyID)
+	if err != nil {
+		h.handleError(c, err, "Failed to rotate API key")
+		return
+	}
+
+	response := h.mapToResponse(key)
+	response.Key = plaintext
+	c.JSON(http.StatusOK, response)
+}
+
+// Revoke godoc
+// @Summary Revoke API key
+// @Tags Admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} APIKeyResponse
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /admin/api-keys/{id}/revoke [post]
+func (h *Handler) Revoke(c *gin.Context) {
+	keyID, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.service.Revoke(c.Request.Context(), keyID)
+	if err != nil {
+		h.handleError(c, err, "Failed to revoke API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.mapToResponse(key))
+}
+
+func (h *Handler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid API key ID"))
+		return uuid.Nil, false
+	}
+	return keyID, true
+}
+
+func (h *Handler) handleError(c *gin.Context, err error, message string) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.Code, appErr)
+		return
+	}
+	c.JSON(http.StatusInternalServerError, errors.InternalServerError(message))
+}
+
+func (h *Handler) mapToResponse(key *APIKey) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:         key.KeyID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		Active:     key.Active,
+		CreatedAt:  key.CreatedAt,
+		UpdatedAt:  key.UpdatedAt,
+		RotatedAt:  key.RotatedAt,
+		RevokedAt:  key.RevokedAt,
+		LastUsedAt: key.LastUsedAt,
+	}
+}
@@ -0,0 +1,90 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"wallet-go/internal/shared/database"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Store struct {
+	collection *mongo.Collection
+}
+
+func NewStore(db *database.MongoClient) *Store {
+	return &Store{
+		collection: db.GetCollection("api_key"),
+	}
+}
+
+func (s *Store) Create(ctx context.Context, key *APIKey) error {
+	key.CreatedAt = time.Now()
+	key.UpdatedAt = key.CreatedAt
+
+	_, err := s.collection.InsertOne(ctx, key)
+	return err
+}
+
+func (s *Store) FindByID(ctx context.Context, keyID uuid.UUID) (*APIKey, error) {
+	return s.findOne(ctx, bson.M{"keyId": keyID})
+}
+
+func (s *Store) FindByHash(ctx context.Context, hash string) (*APIKey, error) {
+	return s.findOne(ctx, bson.M{"hash": hash})
+}
+
+func (s *Store) FindAll(ctx context.Context) ([]*APIKey, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var keys []*APIKey
+	for cursor.Next(ctx) {
+		var key APIKey
+		if err := cursor.Decode(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+
+	return keys, cursor.Err()
+}
+
+func (s *Store) Update(ctx context.Context, key *APIKey) error {
+	key.UpdatedAt = time.Now()
+
+	filter := bson.M{"keyId": key.KeyID}
+	update := bson.M{"$set": key}
+
+	_, err := s.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (s *Store) TouchLastUsed(ctx context.Context, keyID uuid.UUID, usedAt time.Time) error {
+	filter := bson.M{"keyId": keyID}
+	update := bson.M{"$set": bson.M{"lastUsedAt": usedAt}}
+
+	_, err := s.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (s *Store) findOne(ctx context.Context, filter bson.M) (*APIKey, error) {
+	var key APIKey
+
+	err := s.collection.FindOne(ctx, filter).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
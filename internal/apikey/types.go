@@ -0,0 +1,40 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	KeyID      uuid.UUID  `bson:"keyId" json:"keyId"`
+	Name       string     `bson:"name" json:"name"`
+	Prefix     string     `bson:"prefix" json:"prefix"`
+	Hash       string     `bson:"hash" json:"-"`
+	Scopes     []string   `bson:"scopes" json:"scopes"`
+	Active     bool       `bson:"active" json:"active"`
+	CreatedAt  time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time  `bson:"updatedAt" json:"updatedAt"`
+	RotatedAt  *time.Time `bson:"rotatedAt,omitempty" json:"rotatedAt,omitempty"`
+	RevokedAt  *time.Time `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	LastUsedAt *time.Time `bson:"lastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+}
+
+type APIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+}
+
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Active     bool       `json:"active"`
+	Key        string     `json:"key,omitempty"` // only returned on creation and rotation
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	RotatedAt  *time.Time `json:"rotatedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
@@ -0,0 +1,311 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"wallet-go/internal/approval"
+	"wallet-go/internal/bootstrap"
+	"wallet-go/internal/integrity"
+	"wallet-go/internal/router"
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/kafka"
+	"wallet-go/internal/shared/logger"
+	"wallet-go/internal/shared/tracing"
+	"wallet-go/internal/wallet"
+	"wallet-go/internal/webhook"
+)
+
+// Mode selects what a process runs
+type Mode string
+
+const (
+	// ModeAPI serves the HTTP API and publishes the transactions to Kafka
+	ModeAPI Mode = "api"
+	// ModeWorker consumes the Kafka topics and runs the background jobs
+	ModeWorker Mode = "worker"
+	// ModeAll runs the API and the worker in one process
+	ModeAll Mode = "all"
+)
+
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(value); mode {
+	case ModeAPI, ModeWorker, ModeAll:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid mode %q, expected api, worker or all", value)
+	}
+}
+
+func (m Mode) serves() bool {
+	return m == ModeAPI || m == ModeAll
+}
+
+func (m Mode) consumes() bool {
+	return m == ModeWorker || m == ModeAll
+}
+
+// Run starts the process and blocks until SIGINT or SIGTERM. SIGHUP reloads
+// the config file.
+func Run(mode Mode) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	// The in-process bus does not cross processes, so the messages published
+	// by the API only reach a worker running beside it
+	if cfg.Kafka.Backend == "memory" && mode != ModeAll {
+		return fmt.Errorf("KAFKA_BACKEND=memory requires -mode=all, got %s", mode)
+	}
+
+	if err := logger.Setup(cfg.Log); err != nil {
+		return fmt.Errorf("setting up logging: %w", err)
+	}
+
+	// Safe settings (log level, rate limits, approval threshold) follow the
+	// config file while the process runs
+	reloader := config.NewReloader(cfg)
+	reloader.OnReload(func(next *config.Config) {
+		if err := logger.SetLevel(next.Log.Level); err != nil {
+			slog.Error("Invalid log level in reloaded config", "error", err)
+		}
+	})
+
+	// Tracing first, so the Mongo and Kafka clients pick up the provider
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("setting up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Error flushing traces", "error", err)
+		}
+	}()
+
+	container, err := bootstrap.New(reloader)
+	if err != nil {
+		return fmt.Errorf("initializing dependencies: %w", err)
+	}
+
+	p := &process{mode: mode, container: container, serveErrs: make(chan error, 1)}
+	if err := migrate(container); err != nil {
+		return errors.Join(err, p.shutdown())
+	}
+	if err := p.start(); err != nil {
+		return errors.Join(err, p.shutdown())
+	}
+
+	// SIGHUP reloads the config file; SIGINT and SIGTERM stop the process
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go func() {
+		for range reload {
+			reloader.Reload()
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case sig := <-quit:
+		slog.Info("Shutting down", "mode", mode, "signal", sig.String())
+		err = nil
+	case err = <-p.serveErrs:
+		slog.Error("HTTP server failed, shutting down", "mode", mode, "error", err)
+	}
+
+	if shutdownErr := p.shutdown(); shutdownErr != nil {
+		err = errors.Join(err, shutdownErr)
+	}
+	if err == nil {
+		slog.Info("Process exited", "mode", mode)
+	}
+	return err
+}
+
+// migrate applies the pending schema migrations, or only warns about them
+// when MONGODB_MIGRATE_ON_STARTUP is off
+func migrate(c *bootstrap.Container) error {
+	ctx := context.Background()
+
+	if !c.Config.MongoDB.MigrateOnStartup {
+		pending, err := c.Migrator.Pending(ctx)
+		if err != nil {
+			return fmt.Errorf("checking schema migrations: %w", err)
+		}
+		if pending > 0 {
+			slog.Warn("Schema migrations pending, run walletctl migrate up", "pending", pending)
+		}
+		return nil
+	}
+
+	applied, err := c.Migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("applying schema migrations: %w", err)
+	}
+	slog.Info("Schema migrations up to date", "applied", len(applied))
+	return nil
+}
+
+// process holds what a running mode has started, so shutdown stops it in
+// reverse order
+type process struct {
+	mode      Mode
+	container *bootstrap.Container
+
+	server    *http.Server
+	serveErrs chan error
+	consumer  *kafka.Consumer
+
+	stopJobs context.CancelFunc
+	jobs     sync.WaitGroup
+}
+
+func (p *process) start() error {
+	cfg := p.container.Config
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	p.stopJobs = stopJobs
+
+	// Criar tópicos automaticamente
+	topics := []string{cfg.Kafka.Topics.Deposit, cfg.Kafka.Topics.Withdraw, cfg.Kafka.Topics.Transfer}
+	if err := p.container.Broker.CreateTopics(topics, cfg.Kafka.Partitions); err != nil {
+		slog.Warn("Could not create Kafka topics", "error", err)
+	} else {
+		slog.Info("Kafka topics created/verified")
+	}
+
+	if p.mode.consumes() {
+		if err := p.startWorker(jobsCtx); err != nil {
+			return err
+		}
+	}
+
+	p.runJob(jobsCtx, p.container.Reloader.Start)
+
+	// The API serves the probes too; a worker alone serves only the probes
+	// and the metrics on its own port
+	var handler http.Handler
+	port := cfg.Server.Port
+	if p.mode.serves() {
+		r, err := router.Setup(p.container)
+		if err != nil {
+			return fmt.Errorf("setting up router: %w", err)
+		}
+		handler = r
+	} else {
+		handler = router.SetupProbes(p.container)
+		port = cfg.Worker.Port
+	}
+
+	p.server = &http.Server{
+		Addr:    ":" + port,
+		Handler: handler,
+	}
+	go func() {
+		if err := p.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			p.serveErrs <- err
+		}
+	}()
+
+	slog.Info("Server started", "mode", p.mode, "port", port)
+	return nil
+}
+
+// startWorker starts the Kafka consumers and the background jobs fed by them
+func (p *process) startWorker(ctx context.Context) error {
+	c := p.container
+	cfg := c.Config
+
+	consumer, err := kafka.NewConsumer(c.Broker, cfg.Kafka, cfg.Worker.Concurrency)
+	if err != nil {
+		return fmt.Errorf("creating Kafka consumer: %w", err)
+	}
+	consumer.SetWalletService(wallet.NewServiceAdapter(c.WalletService))
+	consumer.SetDeadLetterQueue(c.DeadLetterService)
+
+	// /health/ready stays down until the consumers join their group
+	c.HealthService.SetConsumer(consumer)
+	consumer.Start(context.Background())
+	p.consumer = consumer
+
+	slog.Info("Kafka consumers started", "concurrency", cfg.Worker.Concurrency)
+
+	if cfg.Webhook.WorkerEnabled {
+		p.runJob(ctx, webhook.NewWorker(c.WebhookStore, cfg.Webhook).Start)
+	}
+
+	if c.CheckpointSigner != nil {
+		p.runJob(ctx, integrity.NewCheckpointer(c.IntegrityService, cfg.Integrity.CheckpointInterval).Start)
+	} else {
+		slog.Warn("INTEGRITY_SIGNING_KEY_FILE not set, chain checkpoints are disabled")
+	}
+
+	// Debits under risk review are parked too, and the threshold may be
+	// raised by a reload, so the expirer always runs
+	p.runJob(ctx, approval.NewExpirer(c.ApprovalService, cfg.Approval.ExpiryInterval).Start)
+
+	return nil
+}
+
+func (p *process) runJob(ctx context.Context, job func(context.Context)) {
+	p.jobs.Add(1)
+	go func() {
+		defer p.jobs.Done()
+		job(ctx)
+	}()
+}
+
+// shutdown stops taking requests first, so no new message is produced, then
+// drains the consumers and the background jobs, and finally flushes the
+// producer and disconnects from MongoDB. SERVER_SHUTDOWN_TIMEOUT bounds all
+// of it.
+func (p *process) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.container.Config.Server.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if p.server != nil {
+		if err := p.server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("http server: %w", err))
+		}
+	}
+
+	if p.consumer != nil {
+		if err := p.consumer.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if p.stopJobs != nil {
+		p.stopJobs()
+	}
+	stopped := make(chan struct{})
+	go func() {
+		p.jobs.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("background jobs did not stop: %w", ctx.Err()))
+	}
+
+	if err := p.container.Close(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
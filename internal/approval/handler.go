@@ -0,0 +1,161 @@
+package approval
+
+import (
+	"net/http"
+
+	"wallet-go/internal/shared/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// List godoc
+// @Summary List approvals
+// @Description List debits parked for a second approver, newest first
+// @Tags Admin
+// @Produce json
+// @Param status query string false "PENDING, APPROVED, REJECTED, EXPIRED or FAILED"
+// @Param walletId query string false "Wallet ID"
+// @Param limit query int false "Maximum approvals (default 100, max 1000)"
+// @Success 200 {array} Approval
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/approvals [get]
+func (h *Handler) List(c *gin.Context) {
+	var request ApprovalFilterRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	approvals, err := h.service.List(c.Request.Context(), request)
+	if err != nil {
+		h.handleError(c, err, "Failed to list approvals")
+		return
+	}
+
+	c.JSON(http.StatusOK, approvals)
+}
+
+// GetByID godoc
+// @Summary Get approval
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Approval ID"
+// @Success 200 {object} Approval
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /admin/approvals/{id} [get]
+func (h *Handler) GetByID(c *gin.Context) {
+	approvalID, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	approval, err := h.service.GetByID(c.Request.Context(), approvalID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get approval")
+		return
+	}
+
+	c.JSON(http.StatusOK, approval)
+}
+
+// Approve godoc
+// @Summary Approve a parked debit
+// @Description Execute the parked withdrawal or transfer. The requester cannot approve their own request. An approval whose execution is refused ends as FAILED.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Approval ID"
+// @Param request body DecisionRequest true "Decision"
+// @Success 200 {object} Approval
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 403 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Failure 409 {object} object{error=string,message=string}
+// @Router /admin/approvals/{id}/approve [post]
+func (h *Handler) Approve(c *gin.Context) {
+	approvalID, request, ok := h.parseDecision(c)
+	if !ok {
+		return
+	}
+
+	approval, err := h.service.Approve(c.Request.Context(), approvalID, request)
+	if err != nil {
+		h.handleError(c, err, "Failed to approve")
+		return
+	}
+
+	c.JSON(http.StatusOK, approval)
+}
+
+// Reject godoc
+// @Summary Reject a parked debit
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Approval ID"
+// @Param request body DecisionRequest true "Decision"
+// @Success 200 {object} Approval
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Failure 409 {object} object{error=string,message=string}
+// @Router /admin/approvals/{id}/reject [post]
+func (h *Handler) Reject(c *gin.Context) {
+	approvalID, request, ok := h.parseDecision(c)
+	if !ok {
+		return
+	}
+
+	approval, err := h.service.Reject(c.Request.Context(), approvalID, request)
+	if err != nil {
+		h.handleError(c, err, "Failed to reject")
+		return
+	}
+
+	c.JSON(http.StatusOK, approval)
+}
+
+func (h *Handler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	approvalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid approval ID"))
+		return uuid.Nil, false
+	}
+	return approvalID, true
+}
+
+func (h *Handler) parseDecision(c *gin.Context) (uuid.UUID, DecisionRequest, bool) {
+	var request DecisionRequest
+
+	approvalID, ok := h.parseID(c)
+	if !ok {
+		return uuid.Nil, request, false
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("A reason is required"))
+		return uuid.Nil, request, false
+	}
+
+	return approvalID, request, true
+}
+
+func (h *Handler) handleError(c *gin.Context, err error, message string) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.Code, appErr)
+		return
+	}
+	c.JSON(http.StatusInternalServerError, errors.InternalServerError(message))
+}
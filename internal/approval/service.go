@@ -0,0 +1,252 @@
+package approval
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/errors"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+	expireBatchSize  = 100
+)
+
+// Executor runs the outcome of a decision on the wallets. It is implemented
+// by wallet.Service, which registers itself with SetExecutor.
+type Executor interface {
+	// ExecuteApproval performs the approved debit with the usual validations
+	ExecuteApproval(ctx context.Context, approval *Approval) error
+	// CancelApproval records that a rejected or expired debit did not happen
+	CancelApproval(ctx context.Context, approval *Approval) error
+}
+
+type Service struct {
+	store     *Store
+	executor  Executor
+	config    config.ApprovalConfig
+	threshold atomic.Int64
+}
+
+func NewService(store *Store, cfg config.ApprovalConfig) *Service {
+	s := &Service{
+		store:  store,
+		config: cfg,
+	}
+	s.threshold.Store(cfg.ThresholdInCents)
+	return s
+}
+
+// SetThreshold changes the approval threshold, used when the configuration is
+// reloaded. Debits already parked stay parked.
+func (s *Service) SetThreshold(thresholdInCents int64) {
+	s.threshold.Store(thresholdInCents)
+}
+
+func (s *Service) SetExecutor(executor Executor) {
+	s.executor = executor
+}
+
+// RequiresApproval reports whether a debit of the amount must be parked. A
+// zero threshold disables approvals.
+func (s *Service) RequiresApproval(amountInCents int64) bool {
+	threshold := s.threshold.Load()
+	return threshold > 0 && amountInCents > threshold
+}
+
+// Park stores a pending approval requested by the actor of ctx
+func (s *Service) Park(ctx context.Context, approval *Approval) error {
+	now := time.Now()
+
+	approval.ApprovalID = uuid.New()
+	approval.Status = StatusPending
+	approval.ExpiresAt = now.Add(s.config.TTL)
+	approval.CreatedAt = now
+	approval.UpdatedAt = now
+	if approval.RequestedBy == nil {
+		approval.RequestedBy = auth.ActorFromContext(ctx)
+	}
+
+	return s.store.Create(ctx, approval)
+}
+
+func (s *Service) GetByID(ctx context.Context, approvalID uuid.UUID) (*Approval, error) {
+	approval, err := s.store.FindByID(ctx, approvalID)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to get approval")
+	}
+
+	if approval == nil {
+		return nil, errors.ApprovalNotFound()
+	}
+
+	return approval, nil
+}
+
+func (s *Service) List(ctx context.Context, request ApprovalFilterRequest) ([]*Approval, error) {
+	filter := ApprovalFilter{
+		Status: request.Status,
+		Limit:  request.Limit,
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+
+	if request.WalletID != "" {
+		walletID, err := uuid.Parse(request.WalletID)
+		if err != nil {
+			return nil, errors.BadRequest("Invalid wallet ID")
+		}
+		filter.WalletID = &walletID
+	}
+
+	approvals, err := s.store.Find(ctx, filter)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to list approvals")
+	}
+
+	return approvals, nil
+}
+
+// Approve executes the parked debit. The approver must be a different person
+// than the requester; the resulting operations keep the requester as actor.
+func (s *Service) Approve(ctx context.Context, approvalID uuid.UUID, request DecisionRequest) (*Approval, error) {
+	approval, err := s.getPending(ctx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+
+	approver := auth.ActorFromContext(ctx)
+	if sameSubject(approver, approval.RequestedBy) {
+		return nil, errors.ApprovalSelfDecision()
+	}
+
+	approved, err := s.store.Transition(ctx, approvalID, StatusApproved, bson.M{
+		"decidedBy":      approver,
+		"decisionReason": request.Reason,
+	})
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to approve")
+	}
+	if approved == nil {
+		return nil, errors.ApprovalNotPending()
+	}
+
+	if err := s.executor.ExecuteApproval(auth.WithActor(ctx, approved.RequestedBy), approved); err != nil {
+		message := err.Error()
+		if appErr, ok := err.(*errors.AppError); ok {
+			message = appErr.Message
+		}
+
+		if err := s.store.RecordFailure(ctx, approvalID, message); err != nil {
+			slog.ErrorContext(ctx, "Failed to record failure of approval", "approval_id", approvalID, "error", err)
+		}
+		approved.Status = StatusFailed
+		approved.Error = message
+	}
+
+	return approved, nil
+}
+
+// Reject discards the parked debit
+func (s *Service) Reject(ctx context.Context, approvalID uuid.UUID, request DecisionRequest) (*Approval, error) {
+	if _, err := s.getPending(ctx, approvalID); err != nil {
+		return nil, err
+	}
+
+	rejected, err := s.store.Transition(ctx, approvalID, StatusRejected, bson.M{
+		"decidedBy":      auth.ActorFromContext(ctx),
+		"decisionReason": request.Reason,
+	})
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to reject")
+	}
+	if rejected == nil {
+		return nil, errors.ApprovalNotPending()
+	}
+
+	if err := s.executor.CancelApproval(ctx, rejected); err != nil {
+		slog.ErrorContext(ctx, "Failed to record rejection of approval", "approval_id", approvalID, "error", err)
+	}
+
+	return rejected, nil
+}
+
+// ExpireDue expires every pending approval past its deadline and returns how
+// many were expired
+func (s *Service) ExpireDue(ctx context.Context) (int, error) {
+	expired := 0
+	for {
+		approvals, err := s.store.FindExpired(ctx, time.Now(), expireBatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		for _, approval := range approvals {
+			if ok, err := s.expire(ctx, approval); err != nil {
+				return expired, err
+			} else if ok {
+				expired++
+			}
+		}
+
+		if len(approvals) < expireBatchSize {
+			return expired, nil
+		}
+	}
+}
+
+// getPending loads an approval that can still be decided. Approvals past
+// their deadline are expired on the spot.
+func (s *Service) getPending(ctx context.Context, approvalID uuid.UUID) (*Approval, error) {
+	approval, err := s.GetByID(ctx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if approval.IsPending() && !time.Now().Before(approval.ExpiresAt) {
+		if _, err := s.expire(ctx, approval); err != nil {
+			return nil, errors.InternalServerError("Failed to expire approval")
+		}
+		return nil, errors.ApprovalNotPending()
+	}
+
+	if !approval.IsPending() {
+		return nil, errors.ApprovalNotPending()
+	}
+
+	return approval, nil
+}
+
+func (s *Service) expire(ctx context.Context, approval *Approval) (bool, error) {
+	expired, err := s.store.Transition(ctx, approval.ApprovalID, StatusExpired, bson.M{
+		"decisionReason": "Approval expired",
+	})
+	if err != nil || expired == nil {
+		return false, err
+	}
+
+	if err := s.executor.CancelApproval(ctx, expired); err != nil {
+		slog.ErrorContext(ctx, "Failed to record expiry of approval", "approval_id", approval.ApprovalID, "error", err)
+	}
+
+	return true, nil
+}
+
+// sameSubject reports whether both actors are the same identified person.
+// Without authentication there are no subjects and the check does not apply.
+func sameSubject(a, b *auth.Actor) bool {
+	return a != nil && b != nil && a.Subject != "" && a.Subject == b.Subject
+}
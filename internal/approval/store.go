@@ -0,0 +1,135 @@
+package approval
+
+import (
+	"context"
+	"time"
+
+	"wallet-go/internal/shared/database"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Store struct {
+	collection *mongo.Collection
+}
+
+func NewStore(db *database.MongoClient) *Store {
+	return &Store{
+		collection: db.GetCollection("operation_approval"),
+	}
+}
+
+func (s *Store) Create(ctx context.Context, approval *Approval) error {
+	_, err := s.collection.InsertOne(ctx, approval)
+	return err
+}
+
+func (s *Store) FindByID(ctx context.Context, approvalID uuid.UUID) (*Approval, error) {
+	var approval Approval
+
+	err := s.collection.FindOne(ctx, bson.M{"approvalId": approvalID}).Decode(&approval)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &approval, nil
+}
+
+func (s *Store) Find(ctx context.Context, filter ApprovalFilter) ([]*Approval, error) {
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.WalletID != nil {
+		query["walletId"] = *filter.WalletID
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetLimit(filter.Limit)
+
+	cursor, err := s.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	approvals := []*Approval{}
+	if err := cursor.All(ctx, &approvals); err != nil {
+		return nil, err
+	}
+
+	return approvals, nil
+}
+
+// Transition moves a pending approval to the given status. It returns nil
+// when the approval is no longer pending, so of two concurrent decisions only
+// one wins.
+func (s *Store) Transition(ctx context.Context, approvalID uuid.UUID, status Status, set bson.M) (*Approval, error) {
+	now := time.Now()
+	fields := bson.M{
+		"status":    status,
+		"decidedAt": now,
+		"updatedAt": now,
+	}
+	for key, value := range set {
+		fields[key] = value
+	}
+
+	filter := bson.M{"approvalId": approvalID, "status": StatusPending}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var approval Approval
+	err := s.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": fields}, opts).Decode(&approval)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &approval, nil
+}
+
+// RecordFailure stores why an approved request could not be executed
+func (s *Store) RecordFailure(ctx context.Context, approvalID uuid.UUID, message string) error {
+	filter := bson.M{"approvalId": approvalID, "status": StatusApproved}
+	update := bson.M{"$set": bson.M{
+		"status":    StatusFailed,
+		"error":     message,
+		"updatedAt": time.Now(),
+	}}
+
+	_, err := s.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// FindExpired returns pending approvals whose deadline has passed
+func (s *Store) FindExpired(ctx context.Context, now time.Time, limit int64) ([]*Approval, error) {
+	filter := bson.M{
+		"status":    StatusPending,
+		"expiresAt": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "expiresAt", Value: 1}}).
+		SetLimit(limit)
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	approvals := []*Approval{}
+	if err := cursor.All(ctx, &approvals); err != nil {
+		return nil, err
+	}
+
+	return approvals, nil
+}
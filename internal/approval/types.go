@@ -0,0 +1,67 @@
+package approval
+
+import (
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/auth"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusPending  Status = "PENDING"
+	StatusApproved Status = "APPROVED"
+	StatusRejected Status = "REJECTED"
+	StatusExpired  Status = "EXPIRED"
+	// StatusFailed is an approved request whose execution was refused, e.g.
+	// for insufficient funds at approval time
+	StatusFailed Status = "FAILED"
+)
+
+// Approval is a debit parked until a second person approves or rejects it
+type Approval struct {
+	ApprovalID          uuid.UUID          `bson:"approvalId" json:"approvalId"`
+	Type                enum.OperationType `bson:"type" json:"type"`
+	WalletID            uuid.UUID          `bson:"walletId" json:"walletId"`
+	WalletDestinationID *uuid.UUID         `bson:"walletDestinationId,omitempty" json:"walletDestinationId,omitempty"`
+	AmountInCents       int64              `bson:"amountInCents" json:"amountInCents"`
+	Status              Status             `bson:"status" json:"status"`
+	// OperationID is the PENDING_APPROVAL operation recorded when parking
+	OperationID uuid.UUID `bson:"operationId" json:"operationId"`
+	// Risk is set when the risk rules sent the debit to review
+	Risk           *operation.RiskAssessment `bson:"risk,omitempty" json:"risk,omitempty"`
+	RequestedBy    *auth.Actor               `bson:"requestedBy,omitempty" json:"requestedBy,omitempty"`
+	DecidedBy      *auth.Actor               `bson:"decidedBy,omitempty" json:"decidedBy,omitempty"`
+	DecisionReason string                    `bson:"decisionReason,omitempty" json:"decisionReason,omitempty"`
+	// Error is the reason an approved request could not be executed
+	Error     string     `bson:"error,omitempty" json:"error,omitempty"`
+	ExpiresAt time.Time  `bson:"expiresAt" json:"expiresAt"`
+	DecidedAt *time.Time `bson:"decidedAt,omitempty" json:"decidedAt,omitempty"`
+	CreatedAt time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time  `bson:"updatedAt" json:"updatedAt"`
+}
+
+type DecisionRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type ApprovalFilterRequest struct {
+	Status   Status `form:"status"`
+	WalletID string `form:"walletId"`
+	Limit    int64  `form:"limit"`
+}
+
+type ApprovalFilter struct {
+	Status   Status
+	WalletID *uuid.UUID
+	Limit    int64
+}
+
+// IsPending reports whether the approval still waits for a decision
+func (a *Approval) IsPending() bool {
+	return a.Status == StatusPending
+}
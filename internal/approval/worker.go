@@ -0,0 +1,43 @@
+package approval
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Expirer expires pending approvals past their deadline on a fixed interval
+type Expirer struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewExpirer(service *Service, interval time.Duration) *Expirer {
+	return &Expirer{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (e *Expirer) Start(ctx context.Context) {
+	slog.Info("Starting approval expirer", "interval", e.interval.String())
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Approval expirer stopped")
+			return
+		case <-ticker.C:
+			count, err := e.service.ExpireDue(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "Error expiring approvals", "error", err)
+			}
+			if count > 0 {
+				slog.InfoContext(ctx, "Expired pending approvals", "count", count)
+			}
+		}
+	}
+}
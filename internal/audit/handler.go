@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"net/http"
+
+	"wallet-go/internal/shared/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// List godoc
+// @Summary Query audit log
+// @Description List administrative changes, newest first
+// @Tags Admin
+// @Produce json
+// @Param resourceType query string false "Resource type, e.g. wallet"
+// @Param resourceId query string false "Resource ID"
+// @Param action query string false "Action, e.g. wallet.block"
+// @Param actor query string false "Actor subject"
+// @Param from query string false "From (RFC 3339, inclusive)"
+// @Param to query string false "To (RFC 3339, exclusive)"
+// @Param limit query int false "Maximum entries (default 100, max 1000)"
+// @Success 200 {array} Entry
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/audit [get]
+func (h *Handler) List(c *gin.Context) {
+	var request EntryFilterRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	entries, err := h.service.List(c.Request.Context(), request)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, appErr)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errors.InternalServerError("Failed to list audit entries"))
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/errors"
+	"wallet-go/internal/shared/requestinfo"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+type Service struct {
+	store *Store
+}
+
+func NewService(store *Store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+// Record stamps the entry with the actor and request metadata of ctx and
+// appends it to the log
+func (s *Service) Record(ctx context.Context, entry *Entry) error {
+	entry.EntryID = uuid.New()
+	entry.CreatedAt = time.Now()
+
+	if entry.Actor == nil {
+		entry.Actor = auth.ActorFromContext(ctx)
+	}
+
+	info := requestinfo.FromContext(ctx)
+	entry.RequestID = info.RequestID
+	entry.IP = info.IP
+	entry.UserAgent = info.UserAgent
+
+	if entry.Changes == nil {
+		entry.Changes = []Change{}
+	}
+
+	return s.store.Create(ctx, entry)
+}
+
+func (s *Service) List(ctx context.Context, request EntryFilterRequest) ([]*Entry, error) {
+	filter := EntryFilter{
+		ResourceType: request.ResourceType,
+		ResourceID:   request.ResourceID,
+		Action:       request.Action,
+		Actor:        request.Actor,
+		Limit:        request.Limit,
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+
+	if request.From != "" {
+		from, err := time.Parse(time.RFC3339, request.From)
+		if err != nil {
+			return nil, errors.BadRequest("Invalid from date, use RFC 3339")
+		}
+		filter.From = &from
+	}
+
+	if request.To != "" {
+		to, err := time.Parse(time.RFC3339, request.To)
+		if err != nil {
+			return nil, errors.BadRequest("Invalid to date, use RFC 3339")
+		}
+		filter.To = &to
+	}
+
+	entries, err := s.store.Find(ctx, filter)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to list audit entries")
+	}
+
+	return entries, nil
+}
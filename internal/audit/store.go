@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"context"
+
+	"wallet-go/internal/shared/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Store only inserts and reads; entries are never updated or deleted
+type Store struct {
+	collection *mongo.Collection
+}
+
+func NewStore(db *database.MongoClient) *Store {
+	return &Store{
+		collection: db.GetCollection("audit_log"),
+	}
+}
+
+func (s *Store) Create(ctx context.Context, entry *Entry) error {
+	_, err := s.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (s *Store) Find(ctx context.Context, filter EntryFilter) ([]*Entry, error) {
+	query := bson.M{}
+	if filter.ResourceType != "" {
+		query["resourceType"] = filter.ResourceType
+	}
+	if filter.ResourceID != "" {
+		query["resourceId"] = filter.ResourceID
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.Actor != "" {
+		query["actor.subject"] = filter.Actor
+	}
+
+	createdAt := bson.M{}
+	if filter.From != nil {
+		createdAt["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		createdAt["$lt"] = *filter.To
+	}
+	if len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetLimit(filter.Limit)
+
+	cursor, err := s.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []*Entry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"time"
+
+	"wallet-go/internal/shared/auth"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded for wallets
+const (
+	ActionWalletBlock      = "wallet.block"
+	ActionWalletUnblock    = "wallet.unblock"
+	ActionWalletActivate   = "wallet.activate"
+	ActionWalletDeactivate = "wallet.deactivate"
+	ActionWalletUpdate     = "wallet.update"
+	ActionWalletAdjust     = "wallet.adjust"
+)
+
+const ResourceWallet = "wallet"
+
+// Entry is an append-only record of an administrative change
+type Entry struct {
+	EntryID      uuid.UUID   `bson:"entryId" json:"entryId"`
+	Action       string      `bson:"action" json:"action"`
+	ResourceType string      `bson:"resourceType" json:"resourceType"`
+	ResourceID   string      `bson:"resourceId" json:"resourceId"`
+	Actor        *auth.Actor `bson:"actor,omitempty" json:"actor,omitempty"`
+	Reason       string      `bson:"reason,omitempty" json:"reason,omitempty"`
+	Changes      []Change    `bson:"changes" json:"changes"`
+	RequestID    string      `bson:"requestId,omitempty" json:"requestId,omitempty"`
+	IP           string      `bson:"ip,omitempty" json:"ip,omitempty"`
+	UserAgent    string      `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	CreatedAt    time.Time   `bson:"createdAt" json:"createdAt"`
+}
+
+// Change is the before/after value of one field
+type Change struct {
+	Field  string      `bson:"field" json:"field"`
+	Before interface{} `bson:"before" json:"before"`
+	After  interface{} `bson:"after" json:"after"`
+}
+
+type EntryFilterRequest struct {
+	ResourceType string `form:"resourceType"`
+	ResourceID   string `form:"resourceId"`
+	Action       string `form:"action"`
+	Actor        string `form:"actor"`
+	From         string `form:"from"`
+	To           string `form:"to"`
+	Limit        int64  `form:"limit"`
+}
+
+// EntryFilter is the parsed form of EntryFilterRequest
+type EntryFilter struct {
+	ResourceType string
+	ResourceID   string
+	Action       string
+	Actor        string
+	From         *time.Time
+	To           *time.Time
+	Limit        int64
+}
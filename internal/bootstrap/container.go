@@ -0,0 +1,191 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"wallet-go/internal/apikey"
+	"wallet-go/internal/approval"
+	"wallet-go/internal/audit"
+	"wallet-go/internal/deadletter"
+	"wallet-go/internal/events"
+	"wallet-go/internal/health"
+	"wallet-go/internal/integrity"
+	"wallet-go/internal/migration"
+	"wallet-go/internal/operation"
+	"wallet-go/internal/report"
+	"wallet-go/internal/risk"
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/database"
+	"wallet-go/internal/shared/encryption"
+	"wallet-go/internal/shared/kafka"
+	"wallet-go/internal/shared/utils"
+	"wallet-go/internal/wallet"
+	"wallet-go/internal/webhook"
+)
+
+// Container holds the dependencies shared by the API and the worker. They are
+// built once per process, so both sides of the combined mode use the same
+// wallet service and lock manager.
+type Container struct {
+	Config   *config.Config
+	Reloader *config.Reloader
+
+	Mongo *database.MongoClient
+	// Broker is Kafka or the in-process bus, shared by the producer and the
+	// consumers of the process
+	Broker   kafka.Broker
+	Producer *kafka.Producer
+	// Migrator creates the indexes and validators the stores rely on
+	Migrator *migration.Migrator
+
+	WebhookStore *webhook.Store
+
+	WalletService    *wallet.Service
+	OperationService *operation.Service
+	ReportService    *report.Service
+	EventService     *events.Service
+	WebhookService   *webhook.Service
+	APIKeyService    *apikey.Service
+	IntegrityService *integrity.Service
+	AuditService     *audit.Service
+	ApprovalService  *approval.Service
+	RiskService      *risk.Service
+	HealthService    *health.Service
+	// DeadLetterService keeps the Kafka messages the worker failed to process
+	DeadLetterService *deadletter.Service
+
+	// CheckpointSigner is nil when chain checkpoints are disabled
+	CheckpointSigner *integrity.Signer
+}
+
+// New connects to MongoDB and wires the stores and services. The reloadable
+// settings follow reloader.
+func New(reloader *config.Reloader) (*Container, error) {
+	cfg := reloader.Current()
+
+	mongoClient, err := database.NewMongoClient(cfg.MongoDB)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := wire(cfg, reloader, mongoClient)
+	if err != nil {
+		mongoClient.Disconnect(context.Background())
+		return nil, err
+	}
+	return c, nil
+}
+
+func wire(cfg *config.Config, reloader *config.Reloader, mongoClient *database.MongoClient) (*Container, error) {
+	fieldCipher, err := encryption.LoadFieldCipher(cfg.Encryption.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if fieldCipher == nil {
+		slog.Warn("ENCRYPTION_KEY_FILE not set, customer IDs are stored in plaintext")
+	}
+
+	checkpointSigner, err := integrity.LoadSigner(cfg.Integrity.SigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stores
+	walletStore := wallet.NewStore(mongoClient, fieldCipher)
+	operationStore := operation.NewStore(mongoClient)
+	integrityStore := integrity.NewStore(mongoClient)
+	webhookStore := webhook.NewStore(mongoClient)
+
+	// Services
+	walletService := wallet.NewService(walletStore, operationStore, wallet.NewValidator(), utils.NewWalletLockManager())
+	walletService.SetTransactor(mongoClient)
+	auditService := audit.NewService(audit.NewStore(mongoClient))
+	walletService.SetAuditLogger(auditService)
+
+	// Large debits are parked for a second approver
+	approvalService := approval.NewService(approval.NewStore(mongoClient), cfg.Approval)
+	approvalService.SetExecutor(walletService)
+	walletService.SetApprovalQueue(approvalService)
+	reloader.OnReload(func(next *config.Config) {
+		approvalService.SetThreshold(next.Approval.ThresholdInCents)
+	})
+
+	// Withdrawals and transfers go through the risk rules
+	riskService := risk.NewService(risk.NewStore(mongoClient), operationStore)
+	walletService.SetRiskEvaluator(riskService)
+
+	defaultLocation := loadLocation(cfg.Wallet.DefaultTimeZone)
+	operationService := operation.NewService(operationStore, defaultLocation)
+	operationService.SetTimeZoneProvider(walletService)
+
+	// Webhooks are fed by the operations written by the wallet service
+	webhookService := webhook.NewService(webhookStore)
+	walletService.AddOperationPublisher(webhookService)
+
+	healthService, err := health.NewService(mongoClient, cfg.Kafka.Brokers, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	broker, err := kafka.NewBroker(cfg.Kafka)
+	if err != nil {
+		return nil, err
+	}
+	producer, err := kafka.NewProducer(broker)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replays go back to the topics through the same producer as the API
+	deadLetterService := deadletter.NewService(deadletter.NewStore(mongoClient))
+	deadLetterService.SetPublisher(producer)
+
+	return &Container{
+		Config:   cfg,
+		Reloader: reloader,
+
+		Mongo:    mongoClient,
+		Broker:   broker,
+		Producer: producer,
+		Migrator: migration.NewMigrator(mongoClient),
+
+		WebhookStore: webhookStore,
+
+		WalletService:    walletService,
+		OperationService: operationService,
+		ReportService:    report.NewService(report.NewStore(mongoClient), defaultLocation),
+		EventService:     events.NewService(events.NewStore(mongoClient)),
+		WebhookService:   webhookService,
+		APIKeyService:    apikey.NewService(apikey.NewStore(mongoClient)),
+		IntegrityService: integrity.NewService(integrityStore, operationService, checkpointSigner),
+		AuditService:     auditService,
+		ApprovalService:  approvalService,
+		RiskService:      riskService,
+		HealthService:    healthService,
+
+		DeadLetterService: deadLetterService,
+
+		CheckpointSigner: checkpointSigner,
+	}, nil
+}
+
+// Close flushes the Kafka producer and disconnects from MongoDB. Call it after
+// the HTTP server and the consumers have stopped.
+func (c *Container) Close(ctx context.Context) error {
+	return errors.Join(
+		c.Producer.Close(),
+		c.Mongo.Disconnect(ctx),
+	)
+}
+
+func loadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		slog.Warn("Invalid default time zone, using UTC", "time_zone", name, "error", err)
+		return time.UTC
+	}
+	return location
+}
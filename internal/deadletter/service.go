@@ -0,0 +1,186 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/errors"
+	"wallet-go/internal/shared/kafka"
+	"wallet-go/internal/shared/requestinfo"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// Publisher sends a replayed message back to its topic. It is implemented by
+// kafka.Producer.
+type Publisher interface {
+	SendRaw(ctx context.Context, topic string, key string, value []byte) error
+}
+
+type Service struct {
+	store     *Store
+	publisher Publisher
+}
+
+func NewService(store *Store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+func (s *Service) SetPublisher(publisher Publisher) {
+	s.publisher = publisher
+}
+
+// Add implements kafka.DeadLetterQueue
+func (s *Service) Add(ctx context.Context, message kafka.FailedMessage) error {
+	now := time.Now()
+	deadLetter := &DeadLetter{
+		DeadLetterID: uuid.New(),
+		Topic:        message.Topic,
+		Partition:    message.Partition,
+		Offset:       message.Offset,
+		Key:          string(message.Key),
+		Value:        string(message.Value),
+		Headers:      message.Headers,
+		Status:       StatusPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if message.Err != nil {
+		deadLetter.Error = message.Err.Error()
+	}
+
+	if err := s.store.Create(ctx, deadLetter); err != nil {
+		return err
+	}
+
+	slog.WarnContext(ctx, "Kafka message added to the dead letter queue",
+		"dead_letter_id", deadLetter.DeadLetterID, "topic", message.Topic, "offset", message.Offset)
+	return nil
+}
+
+func (s *Service) GetByID(ctx context.Context, deadLetterID uuid.UUID) (*DeadLetter, error) {
+	deadLetter, err := s.store.FindByID(ctx, deadLetterID)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to get dead letter")
+	}
+
+	if deadLetter == nil {
+		return nil, errors.DeadLetterNotFound()
+	}
+
+	return deadLetter, nil
+}
+
+func (s *Service) List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetter, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+
+	deadLetters, err := s.store.Find(ctx, filter)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to list dead letters")
+	}
+
+	return deadLetters, nil
+}
+
+// Replay publishes the message again to its topic, under its original
+// request ID, so the worker processes it once more. The worker skips messages
+// whose operation ID is already recorded, so a message that failed after its
+// change was applied is not applied twice; messages without an operation ID
+// cannot be replayed. If publishing fails the dead letter stays pending.
+func (s *Service) Replay(ctx context.Context, deadLetterID uuid.UUID) (*DeadLetter, error) {
+	deadLetter, err := s.getPending(ctx, deadLetterID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasOperationID(deadLetter.Value) {
+		return nil, errors.DeadLetterNotReplayable()
+	}
+
+	claimed, err := s.store.Transition(ctx, deadLetterID, StatusReplayed, bson.M{
+		"resolvedBy": auth.ActorFromContext(ctx),
+	})
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to replay dead letter")
+	}
+	if claimed == nil {
+		return nil, errors.DeadLetterNotPending()
+	}
+
+	publishCtx := ctx
+	if requestID := claimed.Headers[requestinfo.HeaderRequestID]; requestID != "" {
+		publishCtx = requestinfo.WithInfo(ctx, &requestinfo.Info{RequestID: requestID})
+	}
+
+	if err := s.publisher.SendRaw(publishCtx, claimed.Topic, claimed.Key, []byte(claimed.Value)); err != nil {
+		if releaseErr := s.store.Release(ctx, deadLetterID, err.Error()); releaseErr != nil {
+			slog.ErrorContext(ctx, "Failed to release dead letter", "dead_letter_id", deadLetterID, "error", releaseErr)
+		}
+		return nil, errors.InternalServerError("Failed to publish dead letter: " + err.Error())
+	}
+
+	return claimed, nil
+}
+
+// Discard marks the message as not to be replayed
+func (s *Service) Discard(ctx context.Context, deadLetterID uuid.UUID, reason string) (*DeadLetter, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, errors.BadRequest("A reason is required to discard a dead letter")
+	}
+
+	if _, err := s.getPending(ctx, deadLetterID); err != nil {
+		return nil, err
+	}
+
+	discarded, err := s.store.Transition(ctx, deadLetterID, StatusDiscarded, bson.M{
+		"resolvedBy": auth.ActorFromContext(ctx),
+		"reason":     reason,
+	})
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to discard dead letter")
+	}
+	if discarded == nil {
+		return nil, errors.DeadLetterNotPending()
+	}
+
+	return discarded, nil
+}
+
+// hasOperationID reports whether a wallet message carries the operation ID the
+// worker deduplicates on
+func hasOperationID(value string) bool {
+	var message struct {
+		OperationID uuid.UUID `json:"operationId"`
+	}
+	if err := json.Unmarshal([]byte(value), &message); err != nil {
+		return false
+	}
+	return message.OperationID != uuid.Nil
+}
+
+func (s *Service) getPending(ctx context.Context, deadLetterID uuid.UUID) (*DeadLetter, error) {
+	deadLetter, err := s.GetByID(ctx, deadLetterID)
+	if err != nil {
+		return nil, err
+	}
+	if deadLetter.Status != StatusPending {
+		return nil, errors.DeadLetterNotPending()
+	}
+	return deadLetter, nil
+}
@@ -0,0 +1,115 @@
+package deadletter
+
+import (
+	"context"
+	"time"
+
+	"wallet-go/internal/shared/database"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Store struct {
+	collection *mongo.Collection
+}
+
+func NewStore(db *database.MongoClient) *Store {
+	return &Store{
+		collection: db.GetCollection("dead_letter"),
+	}
+}
+
+func (s *Store) Create(ctx context.Context, deadLetter *DeadLetter) error {
+	_, err := s.collection.InsertOne(ctx, deadLetter)
+	return err
+}
+
+func (s *Store) FindByID(ctx context.Context, deadLetterID uuid.UUID) (*DeadLetter, error) {
+	var deadLetter DeadLetter
+
+	err := s.collection.FindOne(ctx, bson.M{"deadLetterId": deadLetterID}).Decode(&deadLetter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &deadLetter, nil
+}
+
+// Find returns the newest dead letters first
+func (s *Store) Find(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetter, error) {
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Topic != "" {
+		query["topic"] = filter.Topic
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetLimit(filter.Limit)
+
+	cursor, err := s.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deadLetters := []*DeadLetter{}
+	if err := cursor.All(ctx, &deadLetters); err != nil {
+		return nil, err
+	}
+
+	return deadLetters, nil
+}
+
+// Transition moves a pending dead letter to the given status. It returns nil
+// when the dead letter is no longer pending, so a message is replayed once
+// even if two operators replay it at the same time.
+func (s *Store) Transition(ctx context.Context, deadLetterID uuid.UUID, status Status, set bson.M) (*DeadLetter, error) {
+	now := time.Now()
+	fields := bson.M{
+		"status":     status,
+		"resolvedAt": now,
+		"updatedAt":  now,
+	}
+	for key, value := range set {
+		fields[key] = value
+	}
+
+	filter := bson.M{"deadLetterId": deadLetterID, "status": StatusPending}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var deadLetter DeadLetter
+	err := s.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": fields}, opts).Decode(&deadLetter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &deadLetter, nil
+}
+
+// Release returns a claimed dead letter to pending after its replay failed
+func (s *Store) Release(ctx context.Context, deadLetterID uuid.UUID, replayError string) error {
+	filter := bson.M{"deadLetterId": deadLetterID, "status": StatusReplayed}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      StatusPending,
+			"replayError": replayError,
+			"updatedAt":   time.Now(),
+		},
+		"$unset": bson.M{"resolvedBy": "", "resolvedAt": "", "reason": ""},
+	}
+
+	_, err := s.collection.UpdateOne(ctx, filter, update)
+	return err
+}
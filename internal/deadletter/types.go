@@ -0,0 +1,44 @@
+package deadletter
+
+import (
+	"time"
+
+	"wallet-go/internal/shared/auth"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "PENDING"
+	StatusReplayed  Status = "REPLAYED"
+	StatusDiscarded Status = "DISCARDED"
+)
+
+// DeadLetter is a Kafka message the worker could not process. It stays
+// pending until it is replayed to its topic or discarded.
+type DeadLetter struct {
+	DeadLetterID uuid.UUID         `bson:"deadLetterId" json:"deadLetterId"`
+	Topic        string            `bson:"topic" json:"topic"`
+	Partition    int               `bson:"partition" json:"partition"`
+	Offset       int64             `bson:"offset" json:"offset"`
+	Key          string            `bson:"key,omitempty" json:"key,omitempty"`
+	Value        string            `bson:"value" json:"value"`
+	Headers      map[string]string `bson:"headers,omitempty" json:"headers,omitempty"`
+	Error        string            `bson:"error" json:"error"`
+	Status       Status            `bson:"status" json:"status"`
+	// ReplayError is the last failure to publish the message again
+	ReplayError string      `bson:"replayError,omitempty" json:"replayError,omitempty"`
+	ResolvedBy  *auth.Actor `bson:"resolvedBy,omitempty" json:"resolvedBy,omitempty"`
+	Reason      string      `bson:"reason,omitempty" json:"reason,omitempty"`
+	ResolvedAt  *time.Time  `bson:"resolvedAt,omitempty" json:"resolvedAt,omitempty"`
+	CreatedAt   time.Time   `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time   `bson:"updatedAt" json:"updatedAt"`
+}
+
+type DeadLetterFilter struct {
+	Status Status
+	Topic  string
+	Limit  int64
+}
@@ -0,0 +1,291 @@
+// Package e2e runs the wallet flows end to end in process: HTTP handlers, the
+// producer, the in-memory message bus, the consumers and the wallet service
+// on in-memory stores.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/kafka"
+	"wallet-go/internal/shared/utils"
+	"wallet-go/internal/wallet"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// harness is one process in mode all with KAFKA_BACKEND=memory
+type harness struct {
+	t          *testing.T
+	engine     *gin.Engine
+	producer   *kafka.Producer
+	wallets    *wallet.Service
+	operations *operation.Service
+}
+
+func newHarness(t *testing.T) *harness {
+	cfg := config.KafkaConfig{
+		Backend:    "memory",
+		GroupID:    "wallet-group",
+		Partitions: 3,
+		Topics: config.KafkaTopics{
+			Deposit:  "wallet.deposit",
+			Withdraw: "wallet.withdraw",
+			Transfer: "wallet.transfer",
+		},
+	}
+
+	operationStore := operation.NewMemoryStore()
+	walletService := wallet.NewService(wallet.NewMemoryStore(operationStore), operationStore, wallet.NewValidator(), utils.NewWalletLockManager())
+	operationService := operation.NewService(operationStore, time.UTC)
+
+	broker, err := kafka.NewBroker(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	producer, err := kafka.NewProducer(broker)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two readers per topic, so messages of different wallets run in parallel
+	consumer, err := kafka.NewConsumer(broker, cfg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consumer.SetWalletService(wallet.NewServiceAdapter(walletService))
+	consumer.Start(context.Background())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := consumer.Shutdown(ctx); err != nil {
+			t.Errorf("consumer shutdown: %v", err)
+		}
+		producer.Close()
+	})
+
+	handler := wallet.NewHandler(walletService, operationService, producer, cfg.Topics.Deposit, cfg.Topics.Withdraw, cfg.Topics.Transfer)
+	engine := gin.New()
+	engine.POST("/wallet", handler.Create)
+	engine.GET("/wallet/:id", handler.GetByID)
+	engine.POST("/wallet/:id/deposit", handler.Deposit)
+	engine.POST("/wallet/:id/withdraw", handler.Withdraw)
+	engine.POST("/wallet/:id/transfer", handler.Transfer)
+
+	return &harness{t: t, engine: engine, producer: producer, wallets: walletService, operations: operationService}
+}
+
+func TestWalletFlow(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+
+	source := h.createWallet("customer-a")
+	destination := h.createWallet("customer-b")
+
+	// Deposits are accepted at once and applied by the consumers
+	var deposited int64
+	for amount := int64(1); amount <= 20; amount++ {
+		h.post("/wallet/"+source.String()+"/deposit", map[string]interface{}{"amountInCents": amount}, http.StatusAccepted)
+		deposited += amount
+	}
+	h.eventually("deposits applied", func() bool { return h.balance(source) == deposited })
+
+	// Messages of a wallet share a partition, so they are applied in the order
+	// they were sent
+	var amounts []int64
+	for _, op := range h.walletOperations(source) {
+		if op.Type == enum.OperationTypeDeposit {
+			amounts = append(amounts, op.AmountInCents)
+		}
+	}
+	for i, amount := range amounts {
+		if amount != int64(i+1) {
+			t.Fatalf("deposit %d applied with %d cents, want %d: %v", i, amount, i+1, amounts)
+		}
+	}
+
+	// A rejected withdraw is recorded as an error operation and changes nothing
+	h.post("/wallet/"+source.String()+"/withdraw", map[string]interface{}{"amountInCents": deposited + 1}, http.StatusAccepted)
+	h.eventually("rejected withdraw recorded", func() bool {
+		for _, op := range h.walletOperations(source) {
+			if op.Type == enum.OperationTypeWithdraw && op.Status == enum.OperationStatusError {
+				if op.Rejection != enum.RejectionReasonInsufficientFunds {
+					t.Fatalf("rejected withdraw classified as %q, want %q", op.Rejection, enum.RejectionReasonInsufficientFunds)
+				}
+				return true
+			}
+		}
+		return false
+	})
+	if balance := h.balance(source); balance != deposited {
+		t.Fatalf("balance after rejected withdraw = %d, want %d", balance, deposited)
+	}
+
+	h.post("/wallet/"+source.String()+"/transfer", map[string]interface{}{
+		"amountInCents":       110,
+		"walletDestinationId": destination,
+	}, http.StatusAccepted)
+	h.eventually("transfer applied", func() bool { return h.balance(destination) == 110 })
+	if balance := h.balance(source); balance != deposited-110 {
+		t.Fatalf("source balance after transfer = %d, want %d", balance, deposited-110)
+	}
+
+	// Balances match the operations, and the operation chains are intact
+	reconciliation, err := h.wallets.Reconcile(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reconciliation.Balanced || reconciliation.WalletCount != 2 {
+		t.Fatalf("reconciliation = %+v, want 2 balanced wallets", reconciliation)
+	}
+	for _, walletID := range []uuid.UUID{source, destination} {
+		verification, err := h.operations.VerifyChain(ctx, walletID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !verification.Valid {
+			t.Fatalf("chain of %s is broken: %+v", walletID, verification.Breaks)
+		}
+	}
+}
+
+func TestConcurrentWallets(t *testing.T) {
+	h := newHarness(t)
+
+	// Many wallets and deposits interleaved across partitions and readers
+	var walletIDs []uuid.UUID
+	for i := 0; i < 10; i++ {
+		walletIDs = append(walletIDs, h.createWallet("customer-"+uuid.NewString()))
+	}
+	for round := 0; round < 10; round++ {
+		for _, walletID := range walletIDs {
+			h.post("/wallet/"+walletID.String()+"/deposit", map[string]interface{}{"amountInCents": 100}, http.StatusAccepted)
+		}
+	}
+
+	for _, walletID := range walletIDs {
+		walletID := walletID
+		h.eventually("deposits of "+walletID.String(), func() bool { return h.balance(walletID) == 1000 })
+	}
+
+	reconciliation, err := h.wallets.Reconcile(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reconciliation.Balanced {
+		t.Fatalf("reconciliation found %d mismatched wallets", reconciliation.MismatchCount)
+	}
+}
+
+func TestRedeliveredMessageAppliesOnce(t *testing.T) {
+	h := newHarness(t)
+	walletID := h.createWallet("customer-a")
+
+	// The same message twice, as after a redelivery or a dead letter replay
+	message := kafka.WalletKafkaTransactionMessage{OperationID: uuid.New(), WalletID: walletID, AmountInCents: 100}
+	for i := 0; i < 2; i++ {
+		if err := h.producer.SendMessage(context.Background(), "wallet.deposit", walletID.String(), message); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A later message of the wallet is consumed after both copies
+	h.post("/wallet/"+walletID.String()+"/deposit", map[string]interface{}{"amountInCents": 1}, http.StatusAccepted)
+	h.eventually("marker deposit applied", func() bool { return h.balance(walletID)%100 == 1 })
+
+	if balance := h.balance(walletID); balance != 101 {
+		t.Fatalf("balance = %d, want the redelivered deposit applied once", balance)
+	}
+}
+
+func (h *harness) createWallet(customerID string) uuid.UUID {
+	h.t.Helper()
+
+	var response wallet.WalletResponse
+	h.decode(h.post("/wallet", map[string]interface{}{"customerId": customerID}, http.StatusCreated), &response)
+	return response.Id
+}
+
+func (h *harness) balance(walletID uuid.UUID) int64 {
+	h.t.Helper()
+
+	var response wallet.WalletResponse
+	h.decode(h.do(http.MethodGet, "/wallet/"+walletID.String(), nil, http.StatusOK), &response)
+	return response.CurrentAmountInCents
+}
+
+func (h *harness) walletOperations(walletID uuid.UUID) []operation.Operation {
+	h.t.Helper()
+
+	operations, err := h.operations.GetByWalletID(context.Background(), walletID)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return operations
+}
+
+func (h *harness) post(path string, body interface{}, status int) []byte {
+	h.t.Helper()
+	return h.do(http.MethodPost, path, body, status)
+}
+
+func (h *harness) do(method, path string, body interface{}, status int) []byte {
+	h.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			h.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request := httptest.NewRequest(method, path, reader)
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	h.engine.ServeHTTP(recorder, request)
+
+	if recorder.Code != status {
+		h.t.Fatalf("%s %s = %d %s, want %d", method, path, recorder.Code, recorder.Body.String(), status)
+	}
+	return recorder.Body.Bytes()
+}
+
+func (h *harness) decode(data []byte, value interface{}) {
+	h.t.Helper()
+	if err := json.Unmarshal(data, value); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// eventually waits for the consumers to apply the messages
+func (h *harness) eventually(what string, condition func() bool) {
+	h.t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("timed out waiting: %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"wallet-go/internal/shared/errors"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Stream godoc
+// @Summary Stream wallet events
+// @Description Server-Sent Events stream of new operations and balance changes. Reconnect with the Last-Event-ID header to resume.
+// @Tags Wallet
+// @Produce text/event-stream
+// @Param id path string true "Wallet ID"
+// @Param Last-Event-ID header string false "Resume after this event ID"
+// @Success 200 {string} string
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /wallet/{id}/events [get]
+func (h *Handler) Stream(c *gin.Context) {
+	idParam := c.Param("id")
+	walletID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid wallet ID"))
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	ctx := c.Request.Context()
+	events, errs, err := h.service.Subscribe(ctx, walletID, lastEventID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, appErr)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errors.InternalServerError("Failed to subscribe to wallet events"))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				select {
+				case err := <-errs:
+					slog.WarnContext(c.Request.Context(), "Wallet event stream ended", "wallet_id", walletID, "error", err)
+				default:
+				}
+				return false
+			}
+			c.Render(-1, sse.Event{
+				Id:    event.ID,
+				Event: string(event.Type),
+				Data:  event.Data,
+			})
+			return true
+
+		case <-heartbeat.C:
+			// Comment lines keep proxies from closing idle connections
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return false
+			}
+			return true
+
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
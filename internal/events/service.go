@@ -0,0 +1,133 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/shared/errors"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type Service struct {
+	store *Store
+}
+
+func NewService(store *Store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+// Subscribe streams operation and balance events for a wallet until ctx is
+// cancelled. The events channel is closed when the stream ends; a non-nil
+// value on the error channel means it ended because of a failure.
+func (s *Service) Subscribe(ctx context.Context, walletID uuid.UUID, lastEventID string) (<-chan Event, <-chan error, error) {
+	if lastEventID != "" && !IsValidResumeToken(lastEventID) {
+		return nil, nil, errors.BadRequest("Invalid Last-Event-ID")
+	}
+
+	exists, err := s.store.WalletExists(ctx, walletID)
+	if err != nil {
+		return nil, nil, errors.InternalServerError("Failed to get wallet")
+	}
+	if !exists {
+		return nil, nil, errors.WalletNotFound()
+	}
+
+	stream, err := s.store.Watch(ctx, walletID, lastEventID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to open change stream", "wallet_id", walletID, "error", err)
+		return nil, nil, errors.InternalServerError("Failed to subscribe to wallet events")
+	}
+
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer stream.Close(context.Background())
+
+		for stream.Next(ctx) {
+			event, ok := s.decode(stream)
+			if !ok {
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			errs <- err
+		}
+	}()
+
+	return events, errs, nil
+}
+
+func (s *Service) decode(stream *mongo.ChangeStream) (Event, bool) {
+	var change changeEvent
+	if err := stream.Decode(&change); err != nil {
+		slog.Error("Failed to decode change event", "error", err)
+		return Event{}, false
+	}
+
+	fullDocument, ok := stream.Current.Lookup("fullDocument").DocumentOK()
+	if !ok {
+		return Event{}, false
+	}
+
+	eventID := stream.ResumeToken().Lookup("_data").StringValue()
+
+	switch change.Namespace.Collection {
+	case operationCollection:
+		var op operation.Operation
+		if err := bson.Unmarshal(fullDocument, &op); err != nil {
+			slog.Error("Failed to decode operation event", "error", err)
+			return Event{}, false
+		}
+		return Event{
+			ID:   eventID,
+			Type: EventTypeOperation,
+			Data: operation.OperationResponse{
+				ID:                     op.OperationID,
+				WalletID:               op.WalletID,
+				Type:                   op.Type,
+				Status:                 op.Status,
+				AmountInCents:          op.AmountInCents,
+				WalletTransactionID:    op.WalletTransactionID,
+				OperationTransactionID: op.OperationTransactionID,
+				Reason:                 op.Reason,
+				CreatedAt:              op.CreatedAt,
+				UpdatedAt:              op.UpdatedAt,
+			},
+		}, true
+
+	case walletCollection:
+		var wallet walletDocument
+		if err := bson.Unmarshal(fullDocument, &wallet); err != nil {
+			slog.Error("Failed to decode wallet event", "error", err)
+			return Event{}, false
+		}
+		return Event{
+			ID:   eventID,
+			Type: EventTypeBalance,
+			Data: BalanceEvent{
+				WalletID:             wallet.WalletID,
+				CurrentAmountInCents: wallet.CurrentAmountInCents,
+				Active:               wallet.Active,
+				Blocked:              wallet.Blocked,
+				UpdatedAt:            wallet.UpdatedAt,
+			},
+		}, true
+	}
+
+	return Event{}, false
+}
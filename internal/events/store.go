@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"encoding/hex"
+
+	"wallet-go/internal/shared/database"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	walletCollection    = "wallet"
+	operationCollection = "operation"
+)
+
+type Store struct {
+	database *mongo.Database
+}
+
+func NewStore(db *database.MongoClient) *Store {
+	return &Store{
+		database: db.Database,
+	}
+}
+
+func (s *Store) WalletExists(ctx context.Context, walletID uuid.UUID) (bool, error) {
+	count, err := s.database.Collection(walletCollection).CountDocuments(ctx, bson.M{"walletId": walletID}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Watch opens a database-level change stream limited to the wallet document and
+// its operations, so a single resume token covers both collections. Change
+// streams require the replica set configured in docker-compose.
+func (s *Store) Watch(ctx context.Context, walletID uuid.UUID, resumeToken string) (*mongo.ChangeStream, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"ns.coll":               bson.M{"$in": []string{walletCollection, operationCollection}},
+			"operationType":         bson.M{"$in": []string{"insert", "update", "replace"}},
+			"fullDocument.walletId": walletID,
+		}}},
+	}
+
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if resumeToken != "" {
+		opts.SetResumeAfter(bson.M{"_data": resumeToken})
+	}
+
+	return s.database.Watch(ctx, pipeline, opts)
+}
+
+// IsValidResumeToken reports whether the token looks like a change stream
+// resume token (a hex-encoded _data string)
+func IsValidResumeToken(token string) bool {
+	_, err := hex.DecodeString(token)
+	return err == nil
+}
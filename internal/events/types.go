@@ -0,0 +1,47 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventTypeOperation EventType = "operation"
+	EventTypeBalance   EventType = "balance"
+)
+
+// Event is pushed to subscribers. ID carries the change stream resume token so
+// clients can reconnect with Last-Event-ID without missing changes.
+type Event struct {
+	ID   string
+	Type EventType
+	Data interface{}
+}
+
+type BalanceEvent struct {
+	WalletID             uuid.UUID `json:"walletId"`
+	CurrentAmountInCents int64     `json:"currentAmountInCents"`
+	Active               bool      `json:"active"`
+	Blocked              bool      `json:"blocked"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+}
+
+// changeEvent is the subset of a MongoDB change stream document we rely on
+type changeEvent struct {
+	Namespace struct {
+		Collection string `bson:"coll"`
+	} `bson:"ns"`
+	OperationType string `bson:"operationType"`
+}
+
+// walletDocument holds the balance fields of a wallet full document
+type walletDocument struct {
+	WalletID             uuid.UUID `bson:"walletId"`
+	CurrentAmountInCents int64     `bson:"currentAmountInCents"`
+	Active               bool      `bson:"active"`
+	Blocked              bool      `bson:"blocked"`
+	UpdatedAt            time.Time `bson:"updatedAt"`
+}
@@ -0,0 +1,301 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// cachedCheck runs a check at most once per interval. Concurrent probes wait
+// for the running check instead of starting another one.
+type cachedCheck struct {
+	mu       sync.Mutex
+	interval time.Duration
+	timeout  time.Duration
+	run      func(ctx context.Context) *ComponentHealth
+	last     *ComponentHealth
+}
+
+func newCachedCheck(interval, timeout time.Duration, run func(ctx context.Context) *ComponentHealth) *cachedCheck {
+	return &cachedCheck{
+		interval: interval,
+		timeout:  timeout,
+		run:      run,
+	}
+}
+
+func (c *cachedCheck) get() *ComponentHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && time.Since(c.last.CheckedAt) < c.interval {
+		return c.last
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	result := c.run(ctx)
+	result.CheckedAt = time.Now()
+	c.last = result
+	return result
+}
+
+func up(details map[string]interface{}) *ComponentHealth {
+	return &ComponentHealth{Status: HealthStatusUp, Details: details}
+}
+
+func down(err error, details map[string]interface{}) *ComponentHealth {
+	return &ComponentHealth{Status: HealthStatusDown, Error: err.Error(), Details: details}
+}
+
+type helloResult struct {
+	SetName           string `bson:"setName"`
+	Primary           string `bson:"primary"`
+	IsWritablePrimary bool   `bson:"isWritablePrimary"`
+}
+
+// checkMongo runs hello against the primary. Server selection fails when the
+// replica set has no primary, so writes could not be served either.
+func (s *Service) checkMongo(ctx context.Context) *ComponentHealth {
+	var hello helloResult
+	// Explicit, since MONGODB_READ_PREFERENCE may point reads at secondaries
+	opts := options.RunCmd().SetReadPreference(readpref.Primary())
+	err := s.mongoClient.Client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}, opts).Decode(&hello)
+	if err != nil {
+		return down(err, nil)
+	}
+
+	if hello.SetName == "" {
+		return up(map[string]interface{}{"topology": "standalone"})
+	}
+
+	details := map[string]interface{}{
+		"replicaSet": hello.SetName,
+		"primary":    hello.Primary,
+	}
+	if !hello.IsWritablePrimary {
+		return down(fmt.Errorf("replica set %s has no writable primary", hello.SetName), details)
+	}
+	return up(details)
+}
+
+// checkBrokers is up while at least one broker accepts connections
+func (s *Service) checkBrokers(ctx context.Context) *ComponentHealth {
+	// The in-process bus is always reachable
+	if s.config.Kafka.Backend == "memory" {
+		return up(map[string]interface{}{"backend": "memory"})
+	}
+
+	dialer := &kafka.Dialer{Timeout: s.config.Health.CheckTimeout}
+
+	reachable := 0
+	var lastErr error
+	for _, broker := range s.kafkaBrokers {
+		conn, err := dialer.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		conn.Close()
+		reachable++
+	}
+
+	details := map[string]interface{}{
+		"reachable": reachable,
+		"brokers":   len(s.kafkaBrokers),
+	}
+	if reachable == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no Kafka brokers configured")
+		}
+		return down(lastErr, details)
+	}
+	return up(details)
+}
+
+// checkConsumers asks the brokers for the members and committed offsets of
+// the consumer group. Every topic must be assigned to a member and its lag
+// must stay under the threshold.
+func (s *Service) checkConsumers(ctx context.Context) *ComponentHealth {
+	if !s.consumer.Assigned() {
+		return down(fmt.Errorf("waiting for consumer group assignment"), nil)
+	}
+	if s.config.Kafka.Backend == "memory" {
+		return up(map[string]interface{}{"backend": "memory"})
+	}
+
+	client := &kafka.Client{
+		Addr:    kafka.TCP(s.kafkaBrokers...),
+		Timeout: s.config.Health.CheckTimeout,
+	}
+	groupID := s.config.Kafka.GroupID
+
+	groups, err := client.DescribeGroups(ctx, &kafka.DescribeGroupsRequest{GroupIDs: []string{groupID}})
+	if err != nil {
+		return down(err, nil)
+	}
+	if len(groups.Groups) == 0 {
+		return down(fmt.Errorf("consumer group %s not found", groupID), nil)
+	}
+	group := groups.Groups[0]
+	if group.Error != nil {
+		return down(group.Error, nil)
+	}
+
+	assigned := map[string]bool{}
+	for _, member := range group.Members {
+		for _, topic := range member.MemberAssignments.Topics {
+			if len(topic.Partitions) > 0 {
+				assigned[topic.Topic] = true
+			}
+		}
+	}
+
+	lags, err := s.consumerLag(ctx, client, groupID)
+	if err != nil {
+		return down(err, nil)
+	}
+
+	topics := map[string]interface{}{}
+	var problems []string
+	for _, topic := range s.topics {
+		health := TopicHealth{
+			Assigned:  assigned[topic],
+			Lag:       lags[topic],
+			Threshold: s.lagThreshold(topic),
+		}
+		topics[topic] = health
+
+		if !health.Assigned {
+			problems = append(problems, topic+" has no assigned consumer")
+		} else if health.Lag > health.Threshold {
+			problems = append(problems, fmt.Sprintf("%s lag %d exceeds %d", topic, health.Lag, health.Threshold))
+		}
+	}
+
+	details := map[string]interface{}{
+		"group":  groupID,
+		"state":  group.GroupState,
+		"topics": topics,
+	}
+	if len(problems) > 0 {
+		return down(fmt.Errorf("%s", strings.Join(problems, "; ")), details)
+	}
+	return up(details)
+}
+
+// consumerLag sums, per topic, the messages between the committed offset of
+// the group and the end of each partition. Partitions without a commit count
+// from their first offset, where the readers start.
+func (s *Service) consumerLag(ctx context.Context, client *kafka.Client, groupID string) (map[string]int64, error) {
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: s.topics})
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := map[string][]int{}
+	ends := map[string][]kafka.OffsetRequest{}
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			return nil, fmt.Errorf("topic %s: %w", topic.Name, topic.Error)
+		}
+		for _, partition := range topic.Partitions {
+			partitions[topic.Name] = append(partitions[topic.Name], partition.ID)
+			ends[topic.Name] = append(ends[topic.Name], kafka.LastOffsetOf(partition.ID))
+		}
+	}
+
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupID, Topics: partitions})
+	if err != nil {
+		return nil, err
+	}
+	if committed.Error != nil {
+		return nil, committed.Error
+	}
+
+	latest, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: ends})
+	if err != nil {
+		return nil, err
+	}
+
+	commits := map[string]map[int]int64{}
+	for topic, offsets := range committed.Topics {
+		commits[topic] = map[int]int64{}
+		for _, offset := range offsets {
+			if offset.Error != nil {
+				return nil, fmt.Errorf("topic %s partition %d: %w", topic, offset.Partition, offset.Error)
+			}
+			commits[topic][offset.Partition] = offset.CommittedOffset
+		}
+	}
+
+	lags := map[string]int64{}
+	for topic, offsets := range latest.Topics {
+		for _, offset := range offsets {
+			if offset.Error != nil {
+				return nil, fmt.Errorf("topic %s partition %d: %w", topic, offset.Partition, offset.Error)
+			}
+			start, ok := commits[topic][offset.Partition]
+			if !ok || start < 0 {
+				if start, err = s.firstOffset(ctx, topic, offset.Partition); err != nil {
+					return nil, err
+				}
+			}
+			if lag := offset.LastOffset - start; lag > 0 {
+				lags[topic] += lag
+			}
+		}
+	}
+
+	return lags, nil
+}
+
+// firstOffset asks the partition leader where its log starts
+func (s *Service) firstOffset(ctx context.Context, topic string, partition int) (int64, error) {
+	dialer := &kafka.Dialer{Timeout: s.config.Health.CheckTimeout}
+
+	var lastErr error
+	for _, broker := range s.kafkaBrokers {
+		conn, err := dialer.DialLeader(ctx, "tcp", broker, topic, partition)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		defer conn.Close()
+		return conn.ReadFirstOffset()
+	}
+	return 0, lastErr
+}
+
+func (s *Service) lagThreshold(topic string) int64 {
+	if threshold, ok := s.lagThresholds[topic]; ok {
+		return threshold
+	}
+	return s.config.Health.MaxConsumerLag
+}
+
+// parseLagThresholds reads "topic=messages" entries
+func parseLagThresholds(entries []string) (map[string]int64, error) {
+	thresholds := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		topic, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid lag threshold %q, expected topic=messages", entry)
+		}
+		threshold, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || threshold < 0 {
+			return nil, fmt.Errorf("invalid lag threshold %q, expected topic=messages", entry)
+		}
+		thresholds[strings.TrimSpace(topic)] = threshold
+	}
+	return thresholds, nil
+}
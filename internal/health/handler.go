@@ -37,3 +37,33 @@ func (h *Handler) HealthDetails(c *gin.Context) {
 
 	c.JSON(statusCode, health)
 }
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Up while the process serves requests; dependencies are not checked
+// @Tags Health
+// @Produce json
+// @Success 200 {object} Health
+// @Router /health/live [get]
+func (h *Handler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Liveness())
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Up when MongoDB has a writable primary, Kafka is reachable and the consumers are assigned and within their lag thresholds
+// @Tags Health
+// @Produce json
+// @Success 200 {object} Health
+// @Failure 503 {object} Health
+// @Router /health/ready [get]
+func (h *Handler) Ready(c *gin.Context) {
+	health := h.service.Readiness()
+
+	statusCode := http.StatusOK
+	if health.Status == HealthStatusDown {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	c.JSON(statusCode, health)
+}
@@ -1,26 +1,80 @@
 package health
 
 import (
-	"context"
 	"time"
 
 	"wallet-go/internal/shared/config"
 	"wallet-go/internal/shared/database"
-	"github.com/segmentio/kafka-go"
 )
 
+// Service answers the liveness and readiness probes. Dependency checks are
+// cached, so probes from several orchestrators do not multiply the load on
+// MongoDB and Kafka.
 type Service struct {
-	mongoClient  *database.MongoClient
-	kafkaBrokers []string
-	config       *config.Config
+	mongoClient   *database.MongoClient
+	kafkaBrokers  []string
+	config        *config.Config
+	topics        []string
+	lagThresholds map[string]int64
+	consumer      ConsumerStatus
+	started       time.Time
+
+	mongoCheck     *cachedCheck
+	brokerCheck    *cachedCheck
+	consumersCheck *cachedCheck
 }
 
-func NewService(mongoClient *database.MongoClient, kafkaBrokers []string, config *config.Config) *Service {
-	return &Service{
+func NewService(mongoClient *database.MongoClient, kafkaBrokers []string, config *config.Config) (*Service, error) {
+	lagThresholds, err := parseLagThresholds(config.Health.LagThresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Service{
 		mongoClient:  mongoClient,
 		kafkaBrokers: kafkaBrokers,
 		config:       config,
+		topics: []string{
+			config.Kafka.Topics.Deposit,
+			config.Kafka.Topics.Withdraw,
+			config.Kafka.Topics.Transfer,
+		},
+		lagThresholds: lagThresholds,
+		started:       time.Now(),
 	}
+
+	interval, timeout := config.Health.CheckInterval, config.Health.CheckTimeout
+	s.mongoCheck = newCachedCheck(interval, timeout, s.checkMongo)
+	s.brokerCheck = newCachedCheck(interval, timeout, s.checkBrokers)
+	s.consumersCheck = newCachedCheck(config.Health.ConsumerCheckInterval, timeout, s.checkConsumers)
+
+	return s, nil
+}
+
+// SetConsumer adds the consumer group checks to readiness. Until the consumer
+// has joined the group the service reports not ready, so traffic waits for
+// the consumers at startup.
+func (s *Service) SetConsumer(consumer ConsumerStatus) {
+	s.consumer = consumer
+}
+
+// Liveness only tells whether the process is serving requests. It does not
+// look at dependencies: restarting the process would not fix them.
+func (s *Service) Liveness() *Health {
+	health := &Health{Status: HealthStatusUp}
+	if s.config.Health.ShowDetails {
+		health.Details = map[string]interface{}{
+			"uptime": time.Since(s.started).Round(time.Second).String(),
+		}
+	}
+	return health
+}
+
+// Readiness tells whether the service can take traffic: MongoDB has a
+// writable primary, a broker is reachable and, when this process consumes,
+// every topic is assigned and under its lag threshold.
+func (s *Service) Readiness() *Health {
+	return s.GetHealth()
 }
 
 func (s *Service) GetHealth() *Health {
@@ -37,17 +91,21 @@ func (s *Service) GetHealth() *Health {
 }
 
 func (s *Service) GetHealthWithDetails() *Health {
-	details := make(map[string]interface{})
-
-	mongoHealth := s.checkMongoHealth()
-	kafkaHealth := s.checkKafkaHealth()
-
-	details["mongodb"] = mongoHealth
-	details["kafka"] = kafkaHealth
+	components := map[string]*ComponentHealth{
+		"mongodb": s.mongoCheck.get(),
+		"kafka":   s.brokerCheck.get(),
+	}
+	if s.consumer != nil {
+		components["consumers"] = s.consumersCheck.get()
+	}
 
+	details := make(map[string]interface{}, len(components))
 	overallStatus := HealthStatusUp
-	if mongoHealth.Status == HealthStatusDown || kafkaHealth.Status == HealthStatusDown {
-		overallStatus = HealthStatusDown
+	for name, component := range components {
+		details[name] = component
+		if component.Status == HealthStatusDown {
+			overallStatus = HealthStatusDown
+		}
 	}
 
 	return &Health{
@@ -55,35 +113,3 @@ func (s *Service) GetHealthWithDetails() *Health {
 		Details: details,
 	}
 }
-
-func (s *Service) checkMongoHealth() *ComponentHealth {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	err := s.mongoClient.Client.Ping(ctx, nil)
-	if err != nil {
-		return &ComponentHealth{
-			Status: HealthStatusDown,
-			Error:  err.Error(),
-		}
-	}
-
-	return &ComponentHealth{
-		Status: HealthStatusUp,
-	}
-}
-
-func (s *Service) checkKafkaHealth() *ComponentHealth {
-	conn, err := kafka.DialContext(context.Background(), "tcp", s.kafkaBrokers[0])
-	if err != nil {
-		return &ComponentHealth{
-			Status: HealthStatusDown,
-			Error:  err.Error(),
-		}
-	}
-	defer conn.Close()
-
-	return &ComponentHealth{
-		Status: HealthStatusUp,
-	}
-}
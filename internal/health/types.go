@@ -1,5 +1,7 @@
 package health
 
+import "time"
+
 type HealthStatus string
 
 const (
@@ -13,6 +15,21 @@ type Health struct {
 }
 
 type ComponentHealth struct {
-	Status HealthStatus `json:"status"`
-	Error  string       `json:"error,omitempty"`
+	Status    HealthStatus           `json:"status"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CheckedAt time.Time              `json:"checkedAt"`
+}
+
+// TopicHealth is the consumer group view of a topic
+type TopicHealth struct {
+	Assigned  bool  `json:"assigned"`
+	Lag       int64 `json:"lag"`
+	Threshold int64 `json:"threshold"`
+}
+
+// ConsumerStatus is implemented by the Kafka consumer of this process
+type ConsumerStatus interface {
+	// Assigned reports whether every reader has joined the consumer group
+	Assigned() bool
 }
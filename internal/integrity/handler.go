@@ -0,0 +1,135 @@
+package integrity
+
+import (
+	"net/http"
+
+	"wallet-go/internal/shared/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// VerifyWallet godoc
+// @Summary Verify wallet operation chain
+// @Description Walk the hash chain of a wallet's operations and report every break
+// @Tags Integrity
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Success 200 {object} operation.ChainVerification
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/integrity/wallets/{id}/verify [get]
+func (h *Handler) VerifyWallet(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid wallet ID"))
+		return
+	}
+
+	result, err := h.service.VerifyWallet(c.Request.Context(), walletID)
+	if err != nil {
+		h.handleError(c, err, "Failed to verify operation chain")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// VerifyAll godoc
+// @Summary Verify all operation chains
+// @Description Walk the hash chain of every wallet and return the broken ones
+// @Tags Integrity
+// @Produce json
+// @Success 200 {object} ChainsVerification
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/integrity/verify [get]
+func (h *Handler) VerifyAll(c *gin.Context) {
+	result, err := h.service.VerifyAll(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to verify operation chains")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateCheckpoint godoc
+// @Summary Create checkpoint
+// @Description Sign the current head of every wallet chain
+// @Tags Integrity
+// @Produce json
+// @Success 201 {object} Checkpoint
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/integrity/checkpoints [post]
+func (h *Handler) CreateCheckpoint(c *gin.Context) {
+	checkpoint, err := h.service.CreateCheckpoint(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to create checkpoint")
+		return
+	}
+
+	c.JSON(http.StatusCreated, checkpoint)
+}
+
+// ListCheckpoints godoc
+// @Summary List checkpoints
+// @Description List the latest checkpoints without their heads
+// @Tags Integrity
+// @Produce json
+// @Success 200 {array} Checkpoint
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/integrity/checkpoints [get]
+func (h *Handler) ListCheckpoints(c *gin.Context) {
+	checkpoints, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to list checkpoints")
+		return
+	}
+
+	c.JSON(http.StatusOK, checkpoints)
+}
+
+// VerifyCheckpoint godoc
+// @Summary Verify checkpoint
+// @Description Check the signature and links of a checkpoint and that the anchored operations are unchanged
+// @Tags Integrity
+// @Produce json
+// @Param id path string true "Checkpoint ID"
+// @Success 200 {object} CheckpointVerification
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /admin/integrity/checkpoints/{id}/verify [get]
+func (h *Handler) VerifyCheckpoint(c *gin.Context) {
+	checkpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid checkpoint ID"))
+		return
+	}
+
+	result, err := h.service.VerifyCheckpoint(c.Request.Context(), checkpointID)
+	if err != nil {
+		h.handleError(c, err, "Failed to verify checkpoint")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) handleError(c *gin.Context, err error, message string) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.Code, appErr)
+		return
+	}
+	c.JSON(http.StatusInternalServerError, errors.InternalServerError(message))
+}
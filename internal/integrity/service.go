@@ -0,0 +1,225 @@
+package integrity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/shared/errors"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GenesisCheckpointHash is the PrevHash of the first checkpoint
+const GenesisCheckpointHash = operation.GenesisHash
+
+const checkpointListLimit = 100
+
+type Service struct {
+	store            *Store
+	operationService *operation.Service
+	signer           *Signer
+}
+
+// NewService accepts a nil signer; checkpoints then cannot be created or
+// have their signatures checked
+func NewService(store *Store, operationService *operation.Service, signer *Signer) *Service {
+	return &Service{
+		store:            store,
+		operationService: operationService,
+		signer:           signer,
+	}
+}
+
+func (s *Service) SigningEnabled() bool {
+	return s.signer != nil
+}
+
+// VerifyWallet walks the chain of one wallet
+func (s *Service) VerifyWallet(ctx context.Context, walletID uuid.UUID) (*operation.ChainVerification, error) {
+	return s.operationService.VerifyChain(ctx, walletID)
+}
+
+// VerifyAll walks the chain of every wallet that has chained operations
+func (s *Service) VerifyAll(ctx context.Context) (*ChainsVerification, error) {
+	heads, err := s.operationService.ChainHeads(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ChainsVerification{
+		Valid:   true,
+		Wallets: len(heads),
+		Broken:  []*operation.ChainVerification{},
+	}
+
+	for _, head := range heads {
+		verification, err := s.operationService.VerifyChain(ctx, head.WalletID)
+		if err != nil {
+			return nil, err
+		}
+		if !verification.Valid {
+			result.Valid = false
+			result.Broken = append(result.Broken, verification)
+		}
+	}
+
+	return result, nil
+}
+
+// CreateCheckpoint signs the current chain heads, linked to the latest
+// checkpoint
+func (s *Service) CreateCheckpoint(ctx context.Context) (*Checkpoint, error) {
+	if s.signer == nil {
+		return nil, errors.BadRequest("Checkpoint signing key is not configured")
+	}
+
+	heads, err := s.operationService.ChainHeads(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.store.FindLatest(ctx)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to get latest checkpoint")
+	}
+
+	checkpoint := &Checkpoint{
+		CheckpointID: uuid.New(),
+		Sequence:     1,
+		Heads:        make([]CheckpointHead, len(heads)),
+		PrevHash:     GenesisCheckpointHash,
+		KeyID:        s.signer.KeyID(),
+		CreatedAt:    time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if latest != nil {
+		checkpoint.Sequence = latest.Sequence + 1
+		checkpoint.PrevHash = latest.Hash
+	}
+
+	for i, head := range heads {
+		checkpoint.Heads[i] = CheckpointHead{
+			WalletID: head.WalletID,
+			Sequence: head.Sequence,
+			Hash:     head.Hash,
+		}
+	}
+
+	checkpoint.Root = headsRoot(checkpoint.Heads)
+	checkpoint.Hash = checkpointHash(checkpoint)
+	checkpoint.Signature = s.signer.Sign(checkpoint.Hash)
+
+	if err := s.store.Create(ctx, checkpoint); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.BadRequest("Another checkpoint was created concurrently, try again")
+		}
+		return nil, errors.InternalServerError("Failed to create checkpoint")
+	}
+
+	slog.InfoContext(ctx, "Created chain checkpoint", "sequence", checkpoint.Sequence, "wallet_chains", len(checkpoint.Heads))
+	return checkpoint, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]*Checkpoint, error) {
+	checkpoints, err := s.store.FindRecent(ctx, checkpointListLimit)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to list checkpoints")
+	}
+	return checkpoints, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, checkpointID uuid.UUID) (*Checkpoint, error) {
+	checkpoint, err := s.store.FindByID(ctx, checkpointID)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to get checkpoint")
+	}
+	if checkpoint == nil {
+		return nil, errors.CheckpointNotFound()
+	}
+	return checkpoint, nil
+}
+
+// VerifyCheckpoint checks the signature and links of a checkpoint and that
+// every anchored operation still has the anchored hash
+func (s *Service) VerifyCheckpoint(ctx context.Context, checkpointID uuid.UUID) (*CheckpointVerification, error) {
+	checkpoint, err := s.GetByID(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckpointVerification{
+		CheckpointID: checkpoint.CheckpointID,
+		HashValid:    checkpointHash(checkpoint) == checkpoint.Hash,
+		RootValid:    headsRoot(checkpoint.Heads) == checkpoint.Root,
+		Mismatches:   []HeadMismatch{},
+	}
+
+	if s.signer != nil && s.signer.KeyID() == checkpoint.KeyID {
+		result.SignatureValid = s.signer.Verify(checkpoint.Hash, checkpoint.Signature)
+	}
+
+	expectedPrev := GenesisCheckpointHash
+	if checkpoint.Sequence > 1 {
+		previous, err := s.store.FindBySequence(ctx, checkpoint.Sequence-1)
+		if err != nil {
+			return nil, errors.InternalServerError("Failed to get previous checkpoint")
+		}
+		expectedPrev = ""
+		if previous != nil {
+			expectedPrev = previous.Hash
+		}
+	}
+	result.PrevLinkValid = expectedPrev != "" && expectedPrev == checkpoint.PrevHash
+
+	for _, head := range checkpoint.Heads {
+		link, err := s.operationService.GetChainLink(ctx, head.WalletID, head.Sequence)
+		if err != nil {
+			return nil, err
+		}
+
+		actual := ""
+		if link != nil {
+			actual = operation.ComputeHash(link)
+		}
+		if actual != head.Hash {
+			result.Mismatches = append(result.Mismatches, HeadMismatch{
+				WalletID: head.WalletID,
+				Sequence: head.Sequence,
+				Expected: head.Hash,
+				Actual:   actual,
+			})
+		}
+	}
+
+	result.Valid = result.SignatureValid && result.HashValid && result.RootValid &&
+		result.PrevLinkValid && len(result.Mismatches) == 0
+
+	return result, nil
+}
+
+func headsRoot(heads []CheckpointHead) string {
+	data, _ := json.Marshal(heads)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func checkpointHash(checkpoint *Checkpoint) string {
+	data, _ := json.Marshal(struct {
+		Sequence  int64  `json:"sequence"`
+		CreatedAt string `json:"createdAt"`
+		Root      string `json:"root"`
+		PrevHash  string `json:"prevHash"`
+	}{
+		Sequence:  checkpoint.Sequence,
+		CreatedAt: checkpoint.CreatedAt.UTC().Format(time.RFC3339Nano),
+		Root:      checkpoint.Root,
+		PrevHash:  checkpoint.PrevHash,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,137 @@
+package integrity
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/operation/enum"
+
+	"github.com/google/uuid"
+)
+
+// tamperedStore returns the chain of a wallet as an attacker with write
+// access to the collection left it
+type tamperedStore struct {
+	operation.Repository
+	tamper func(op *operation.Operation)
+}
+
+func (s tamperedStore) WalkChain(ctx context.Context, walletID uuid.UUID, fn func(*operation.Operation)) error {
+	return s.Repository.WalkChain(ctx, walletID, func(op *operation.Operation) {
+		edited := *op
+		s.tamper(&edited)
+		fn(&edited)
+	})
+}
+
+func TestVerifyReportsTamperedLinks(t *testing.T) {
+	ctx := context.Background()
+	store := operation.NewMemoryStore()
+
+	tampered, intact := uuid.New(), uuid.New()
+	for _, walletID := range []uuid.UUID{tampered, intact} {
+		for i := 1; i <= 5; i++ {
+			op := &operation.Operation{
+				OperationID:   uuid.New(),
+				WalletID:      walletID,
+				Type:          enum.OperationTypeDeposit,
+				Status:        enum.OperationStatusSuccess,
+				AmountInCents: int64(i * 100),
+				Reason:        "Deposit success!",
+			}
+			if err := store.Create(ctx, op); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// The amount of sequence 2 is raised, and sequence 4 is relinked to
+	// another predecessor. The stored hashes are left as they were.
+	service := NewService(nil, operation.NewService(tamperedStore{store, func(op *operation.Operation) {
+		if op.WalletID != tampered {
+			return
+		}
+		switch op.Sequence {
+		case 2:
+			op.AmountInCents = 1_000_000
+		case 4:
+			op.PrevHash = operation.GenesisHash
+		}
+	}}, time.UTC), nil)
+
+	verification, err := service.VerifyWallet(ctx, tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		kind     string
+		sequence int64
+	}{
+		{operation.ChainBreakHash, 2},
+		{operation.ChainBreakPrevHash, 4},
+		{operation.ChainBreakHash, 4},
+	}
+	if verification.Valid || verification.Length != 5 || len(verification.Breaks) != len(want) {
+		t.Fatalf("verification = %+v, want %d breaks over 5 links", verification, len(want))
+	}
+	for i, expected := range want {
+		got := verification.Breaks[i]
+		if got.Kind != expected.kind || got.Sequence != expected.sequence {
+			t.Fatalf("break %d = %s at %d, want %s at %d", i, got.Kind, got.Sequence, expected.kind, expected.sequence)
+		}
+	}
+
+	// Only the tampered wallet is reported
+	all, err := service.VerifyAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.Valid || all.Wallets != 2 || len(all.Broken) != 1 || all.Broken[0].WalletID != tampered {
+		t.Fatalf("VerifyAll = %+v, want only %s broken", all, tampered)
+	}
+}
+
+func TestCheckpointHashAndSignature(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewSigner(key)
+
+	checkpoint := &Checkpoint{
+		Sequence:  2,
+		Heads:     []CheckpointHead{{WalletID: uuid.New(), Sequence: 5, Hash: "head"}},
+		PrevHash:  "previous",
+		CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	checkpoint.Root = headsRoot(checkpoint.Heads)
+	checkpoint.Hash = checkpointHash(checkpoint)
+	checkpoint.Signature = signer.Sign(checkpoint.Hash)
+
+	if !signer.Verify(checkpoint.Hash, checkpoint.Signature) {
+		t.Fatal("signature of the checkpoint does not verify")
+	}
+
+	// Moving a head changes the root, and relinking changes the hash
+	moved := append([]CheckpointHead(nil), checkpoint.Heads...)
+	moved[0].Sequence = 4
+	if headsRoot(moved) == checkpoint.Root {
+		t.Fatal("root does not cover the anchored heads")
+	}
+	relinked := *checkpoint
+	relinked.PrevHash = GenesisCheckpointHash
+	if checkpointHash(&relinked) == checkpoint.Hash {
+		t.Fatal("hash does not cover the previous checkpoint")
+	}
+
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	other := NewSigner(otherKey)
+	if other.Verify(checkpoint.Hash, checkpoint.Signature) || other.KeyID() == signer.KeyID() {
+		t.Fatal("another key verified the signature or shares the key ID")
+	}
+}
@@ -0,0 +1,74 @@
+package integrity
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// Signer signs checkpoints with an Ed25519 key
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// LoadSigner reads a PKCS#8 PEM Ed25519 private key, as produced by
+// `openssl genpkey -algorithm ed25519`. An empty path returns nil.
+func LoadSigner(path string) (*Signer, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("checkpoint signing key %s is not PEM encoded", path)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse checkpoint signing key: %w", err)
+	}
+
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("checkpoint signing key %s is not an Ed25519 key", path)
+	}
+
+	return NewSigner(key), nil
+}
+
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	sum := sha256.Sum256(key.Public().(ed25519.PublicKey))
+	return &Signer{
+		key:   key,
+		keyID: hex.EncodeToString(sum[:8]),
+	}
+}
+
+// KeyID identifies the public key, so checkpoints signed with a retired key
+// are reported as such instead of as forged
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+func (s *Signer) Sign(message string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, []byte(message)))
+}
+
+func (s *Signer) Verify(message, signature string) bool {
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(s.key.Public().(ed25519.PublicKey), []byte(message), raw)
+}
@@ -0,0 +1,80 @@
+package integrity
+
+import (
+	"context"
+
+	"wallet-go/internal/shared/database"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Store struct {
+	collection *mongo.Collection
+}
+
+func NewStore(db *database.MongoClient) *Store {
+	return &Store{
+		collection: db.GetCollection("operation_checkpoint"),
+	}
+}
+
+func (s *Store) Create(ctx context.Context, checkpoint *Checkpoint) error {
+	_, err := s.collection.InsertOne(ctx, checkpoint)
+	return err
+}
+
+func (s *Store) FindByID(ctx context.Context, checkpointID uuid.UUID) (*Checkpoint, error) {
+	return s.findOne(ctx, bson.M{"checkpointId": checkpointID}, nil)
+}
+
+func (s *Store) FindBySequence(ctx context.Context, sequence int64) (*Checkpoint, error) {
+	return s.findOne(ctx, bson.M{"sequence": sequence}, nil)
+}
+
+func (s *Store) FindLatest(ctx context.Context) (*Checkpoint, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "sequence", Value: -1}})
+	return s.findOne(ctx, bson.M{}, opts)
+}
+
+// FindRecent returns the latest checkpoints without their heads
+func (s *Store) FindRecent(ctx context.Context, limit int64) ([]*Checkpoint, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "sequence", Value: -1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"heads": 0})
+
+	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	checkpoints := []*Checkpoint{}
+	if err := cursor.All(ctx, &checkpoints); err != nil {
+		return nil, err
+	}
+
+	return checkpoints, nil
+}
+
+func (s *Store) findOne(ctx context.Context, filter bson.M, opts *options.FindOneOptions) (*Checkpoint, error) {
+	var checkpoint Checkpoint
+
+	findOpts := []*options.FindOneOptions{}
+	if opts != nil {
+		findOpts = append(findOpts, opts)
+	}
+
+	err := s.collection.FindOne(ctx, filter, findOpts...).Decode(&checkpoint)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &checkpoint, nil
+}
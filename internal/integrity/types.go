@@ -0,0 +1,60 @@
+package integrity
+
+import (
+	"time"
+
+	"wallet-go/internal/operation"
+
+	"github.com/google/uuid"
+)
+
+// Checkpoint anchors the heads of every wallet chain at a point in time. Each
+// checkpoint links to the previous one and is signed, so rewriting operations
+// also requires forging the checkpoints taken after them.
+type Checkpoint struct {
+	CheckpointID uuid.UUID        `bson:"checkpointId" json:"checkpointId"`
+	Sequence     int64            `bson:"sequence" json:"sequence"`
+	Heads        []CheckpointHead `bson:"heads" json:"heads"`
+	// Root is the SHA-256 of the canonical list of heads
+	Root     string `bson:"root" json:"root"`
+	PrevHash string `bson:"prevHash" json:"prevHash"`
+	// Hash covers sequence, createdAt, root and prevHash; Signature is the
+	// base64 Ed25519 signature of Hash
+	Hash      string    `bson:"hash" json:"hash"`
+	KeyID     string    `bson:"keyId" json:"keyId"`
+	Signature string    `bson:"signature" json:"signature"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+type CheckpointHead struct {
+	WalletID uuid.UUID `bson:"walletId" json:"walletId"`
+	Sequence int64     `bson:"sequence" json:"sequence"`
+	Hash     string    `bson:"hash" json:"hash"`
+}
+
+// CheckpointVerification reports whether a checkpoint is authentic and whether
+// the operations it anchors are still the ones it saw
+type CheckpointVerification struct {
+	CheckpointID   uuid.UUID      `json:"checkpointId"`
+	Valid          bool           `json:"valid"`
+	SignatureValid bool           `json:"signatureValid"`
+	HashValid      bool           `json:"hashValid"`
+	RootValid      bool           `json:"rootValid"`
+	PrevLinkValid  bool           `json:"prevLinkValid"`
+	Mismatches     []HeadMismatch `json:"mismatches"`
+}
+
+// HeadMismatch is an anchored head whose operation changed or disappeared
+type HeadMismatch struct {
+	WalletID uuid.UUID `json:"walletId"`
+	Sequence int64     `json:"sequence"`
+	Expected string    `json:"expected"`
+	Actual   string    `json:"actual"`
+}
+
+// ChainsVerification is the result of verifying every wallet chain
+type ChainsVerification struct {
+	Valid   bool                           `json:"valid"`
+	Wallets int                            `json:"wallets"`
+	Broken  []*operation.ChainVerification `json:"broken"`
+}
@@ -0,0 +1,39 @@
+package integrity
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Checkpointer anchors the chain heads on a fixed interval
+type Checkpointer struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewCheckpointer(service *Service, interval time.Duration) *Checkpointer {
+	return &Checkpointer{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (c *Checkpointer) Start(ctx context.Context) {
+	slog.Info("Starting chain checkpointer", "interval", c.interval.String())
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Chain checkpointer stopped")
+			return
+		case <-ticker.C:
+			if _, err := c.service.CreateCheckpoint(ctx); err != nil {
+				slog.ErrorContext(ctx, "Error creating chain checkpoint", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package migration
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrations is the schema history. Append new versions; never change or
+// reorder one that has been released, since databases record it as applied.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "unique wallet, customer and operation IDs",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db.Collection("wallet"),
+				index("walletId_unique", bson.D{{Key: "walletId", Value: 1}}).unique(),
+				// Encrypted customer IDs are unique by blind index; legacy
+				// plaintext ones by value
+				index("customerIdIndex_unique", bson.D{{Key: "customerIdIndex", Value: 1}}).
+					unique().partial(bson.M{"customerIdIndex": bson.M{"$type": "string"}}),
+				index("customerId_unique", bson.D{{Key: "customerId", Value: 1}}).
+					unique().partial(bson.M{"customerId": bson.M{"$type": "string"}}),
+			); err != nil {
+				return err
+			}
+
+			return createIndexes(ctx, db.Collection("operation"),
+				index("operationId_unique", bson.D{{Key: "operationId", Value: 1}}).unique(),
+				// Keeps each wallet hash chain linear
+				index("walletId_sequence_unique", bson.D{{Key: "walletId", Value: 1}, {Key: "sequence", Value: 1}}).
+					unique().partial(bson.M{"sequence": bson.M{"$gt": 0}}),
+			)
+		},
+	},
+	{
+		Version:     2,
+		Description: "date-range indexes for statements and reports",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Statements, daily summaries and risk windows read one wallet by
+			// date; treasury reports read every wallet by date
+			return createIndexes(ctx, db.Collection("operation"),
+				index("walletId_createdAt", bson.D{{Key: "walletId", Value: 1}, {Key: "createdAt", Value: 1}}),
+				index("createdAt", bson.D{{Key: "createdAt", Value: 1}}),
+			)
+		},
+	},
+	{
+		Version:     3,
+		Description: "indexes of checkpoints, audit log, approvals, webhooks, API keys, risk rules and dead letters",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			collections := map[string][]indexSpec{
+				// A unique sequence stops two instances extending the same checkpoint
+				"operation_checkpoint": {
+					index("sequence_unique", bson.D{{Key: "sequence", Value: 1}}).unique(),
+					index("checkpointId_unique", bson.D{{Key: "checkpointId", Value: 1}}).unique(),
+				},
+				"audit_log": {
+					index("resource_createdAt", bson.D{
+						{Key: "resourceType", Value: 1}, {Key: "resourceId", Value: 1}, {Key: "createdAt", Value: -1},
+					}),
+					index("actor_createdAt", bson.D{{Key: "actor.subject", Value: 1}, {Key: "createdAt", Value: -1}}),
+					index("createdAt", bson.D{{Key: "createdAt", Value: -1}}),
+				},
+				"operation_approval": {
+					index("approvalId_unique", bson.D{{Key: "approvalId", Value: 1}}).unique(),
+					index("status_expiresAt", bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}}),
+					index("walletId_createdAt", bson.D{{Key: "walletId", Value: 1}, {Key: "createdAt", Value: -1}}),
+				},
+				"webhook_subscription": {
+					index("subscriptionId_unique", bson.D{{Key: "subscriptionId", Value: 1}}).unique(),
+				},
+				"webhook_delivery": {
+					index("deliveryId_unique", bson.D{{Key: "deliveryId", Value: 1}}).unique(),
+					index("subscriptionId_createdAt", bson.D{{Key: "subscriptionId", Value: 1}, {Key: "createdAt", Value: -1}}),
+					index("status_nextAttemptAt", bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}}),
+				},
+				"api_key": {
+					index("keyId_unique", bson.D{{Key: "keyId", Value: 1}}).unique(),
+					index("hash_unique", bson.D{{Key: "hash", Value: 1}}).unique(),
+				},
+				"risk_rule": {
+					index("ruleId_unique", bson.D{{Key: "ruleId", Value: 1}}).unique(),
+				},
+				"dead_letter": {
+					index("deadLetterId_unique", bson.D{{Key: "deadLetterId", Value: 1}}).unique(),
+					index("status_createdAt", bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}),
+				},
+			}
+
+			for name, indexes := range collections {
+				if err := createIndexes(ctx, db.Collection(name), indexes...); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     4,
+		Description: "expire rate limit buckets and request nonces",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Both stores write the expiry date itself, so documents expire as
+			// soon as expireAt passes
+			for _, name := range []string{"rate_limit", "request_nonce"} {
+				if err := createIndexes(ctx, db.Collection(name),
+					index("expireAt_ttl", bson.D{{Key: "expireAt", Value: 1}}).expireAfter(0),
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     5,
+		Description: "JSON schema validators for wallets and operations",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := setValidator(ctx, db, "wallet", walletSchema); err != nil {
+				return err
+			}
+			return setValidator(ctx, db, "operation", operationSchema)
+		},
+	},
+}
+
+// indexSpec wraps mongo.IndexModel so the migrations read as a list
+type indexSpec struct {
+	model mongo.IndexModel
+}
+
+// index names every index explicitly, so a later migration can drop it
+func index(name string, keys bson.D) indexSpec {
+	return indexSpec{model: mongo.IndexModel{Keys: keys, Options: options.Index().SetName(name)}}
+}
+
+func (i indexSpec) unique() indexSpec {
+	i.model.Options.SetUnique(true)
+	return i
+}
+
+func (i indexSpec) partial(filter bson.M) indexSpec {
+	i.model.Options.SetPartialFilterExpression(filter)
+	return i
+}
+
+func (i indexSpec) expireAfter(seconds int32) indexSpec {
+	i.model.Options.SetExpireAfterSeconds(seconds)
+	return i
+}
+
+// createIndexes is a no-op for indexes that already exist with the same keys
+// and options
+func createIndexes(ctx context.Context, collection *mongo.Collection, indexes ...indexSpec) error {
+	models := make([]mongo.IndexModel, len(indexes))
+	for i, index := range indexes {
+		models[i] = index.model
+	}
+	_, err := collection.Indexes().CreateMany(ctx, models)
+	return err
+}
+
+// setValidator creates the collection when missing and replaces its
+// validator. Level moderate leaves existing invalid documents alone until
+// they are fixed; new and valid documents must match.
+func setValidator(ctx context.Context, db *mongo.Database, name string, schema bson.M) error {
+	err := db.CreateCollection(ctx, name)
+	var commandErr mongo.CommandError
+	if err != nil && !(errors.As(err, &commandErr) && commandErr.Name == "NamespaceExists") {
+		return err
+	}
+
+	return db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: name},
+		{Key: "validator", Value: bson.M{"$jsonSchema": schema}},
+		{Key: "validationLevel", Value: "moderate"},
+		{Key: "validationAction", Value: "error"},
+	}).Err()
+}
+
+var (
+	uuidField    = bson.M{"bsonType": "binData"}
+	integerField = bson.M{"bsonType": bson.A{"long", "int"}}
+	dateField    = bson.M{"bsonType": "date"}
+)
+
+var walletSchema = bson.M{
+	"bsonType": "object",
+	"required": bson.A{"walletId", "currentAmountInCents", "active", "blocked", "createdAt", "updatedAt"},
+	"properties": bson.M{
+		"walletId":             uuidField,
+		"customerId":           bson.M{"bsonType": "string"},
+		"customerIdIndex":      bson.M{"bsonType": "string"},
+		"currentAmountInCents": integerField,
+		"active":               bson.M{"bsonType": "bool"},
+		"blocked":              bson.M{"bsonType": "bool"},
+		"timeZone":             bson.M{"bsonType": "string"},
+		"createdAt":            dateField,
+		"updatedAt":            dateField,
+	},
+}
+
+// operationSchema lists the operation types and statuses; a new one needs a
+// migration that replaces the validator
+var operationSchema = bson.M{
+	"bsonType": "object",
+	"required": bson.A{"operationId", "walletId", "type", "status", "amountInCents", "createdAt"},
+	"properties": bson.M{
+		"operationId": uuidField,
+		"walletId":    uuidField,
+		"type": bson.M{"enum": bson.A{
+			"CREATED", "DEPOSIT", "WITHDRAW", "TRANSFER", "RECEIVE_TRANSFER", "ADJUSTMENT",
+		}},
+		"status":        bson.M{"enum": bson.A{"SUCCESS", "ERROR", "PENDING_APPROVAL"}},
+		"amountInCents": integerField,
+		"sequence":      integerField,
+		"createdAt":     dateField,
+	},
+}
@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"wallet-go/internal/shared/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrator applies the pending migrations in version order and records each
+// one in the schema_migration collection
+type Migrator struct {
+	db         *mongo.Database
+	records    *mongo.Collection
+	migrations []Migration
+}
+
+func NewMigrator(db *database.MongoClient) *Migrator {
+	return &Migrator{
+		db:         db.Database,
+		records:    db.GetCollection("schema_migration"),
+		migrations: migrations,
+	}
+}
+
+// Up applies every pending migration and returns the ones it applied. It
+// stops at the first failure; the versions before it stay recorded.
+func (m *Migrator) Up(ctx context.Context) ([]Record, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, migration := range m.sorted() {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		start := time.Now()
+		if err := migration.Up(ctx, m.db); err != nil {
+			return records, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+
+		record := Record{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now(),
+			DurationMs:  time.Since(start).Milliseconds(),
+		}
+		// Another instance may have recorded it meanwhile; the migration is
+		// idempotent, so its record is as good as ours
+		if _, err := m.records.InsertOne(ctx, record); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return records, fmt.Errorf("recording migration %d: %w", migration.Version, err)
+		}
+
+		slog.Info("Migration applied",
+			"version", record.Version,
+			"description", record.Description,
+			"duration_ms", record.DurationMs,
+		)
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// Status lists the known migrations, applied or not, in version order
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, migration := range m.sorted() {
+		status := Status{Version: migration.Version, Description: migration.Description}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Pending returns how many known migrations have not been applied
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]Record, error) {
+	cursor, err := m.records.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	applied := make(map[int]Record)
+	for cursor.Next(ctx) {
+		var record Record
+		if err := cursor.Decode(&record); err != nil {
+			return nil, err
+		}
+		applied[record.Version] = record
+	}
+
+	return applied, cursor.Err()
+}
+
+func (m *Migrator) sorted() []Migration {
+	sorted := append([]Migration(nil), m.migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migration is one versioned change of the database schema. Up must be
+// idempotent: instances starting together may both run a pending migration
+// before either records it.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// Record is the document stored in schema_migration for each applied version
+type Record struct {
+	Version     int       `bson:"_id" json:"version"`
+	Description string    `bson:"description" json:"description"`
+	AppliedAt   time.Time `bson:"appliedAt" json:"appliedAt"`
+	DurationMs  int64     `bson:"durationMs" json:"durationMs"`
+}
+
+// Status reports whether a known migration has been applied
+type Status struct {
+	Version     int        `json:"version"`
+	Description string     `json:"description"`
+	Applied     bool       `json:"applied"`
+	AppliedAt   *time.Time `json:"appliedAt,omitempty"`
+}
@@ -0,0 +1,148 @@
+package operation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/auth"
+
+	"github.com/google/uuid"
+)
+
+// GenesisHash is the PrevHash of the first operation of every wallet
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// chainContent is the canonical form hashed for each operation. Field order is
+// fixed by the struct, so the JSON encoding is deterministic. CreatedAt is
+// truncated to milliseconds in UTC because that is what MongoDB stores. Risk
+// is omitted when empty so operations written before the risk rules keep their
+// hashes.
+type chainContent struct {
+	OperationID            uuid.UUID            `json:"operationId"`
+	WalletID               uuid.UUID            `json:"walletId"`
+	Sequence               int64                `json:"sequence"`
+	Type                   enum.OperationType   `json:"type"`
+	Status                 enum.OperationStatus `json:"status"`
+	AmountInCents          int64                `json:"amountInCents"`
+	WalletTransactionID    *uuid.UUID           `json:"walletTransactionId"`
+	OperationTransactionID *uuid.UUID           `json:"operationTransactionId"`
+	Reason                 string               `json:"reason"`
+	Actor                  *auth.Actor          `json:"actor"`
+	Risk                   *RiskAssessment      `json:"risk,omitempty"`
+	CreatedAt              string               `json:"createdAt"`
+	PrevHash               string               `json:"prevHash"`
+}
+
+// ComputeHash returns the SHA-256 of the operation content and its PrevHash
+func ComputeHash(op *Operation) string {
+	content := chainContent{
+		OperationID:            op.OperationID,
+		WalletID:               op.WalletID,
+		Sequence:               op.Sequence,
+		Type:                   op.Type,
+		Status:                 op.Status,
+		AmountInCents:          op.AmountInCents,
+		WalletTransactionID:    op.WalletTransactionID,
+		OperationTransactionID: op.OperationTransactionID,
+		Reason:                 op.Reason,
+		Actor:                  op.Actor,
+		Risk:                   op.Risk,
+		CreatedAt:              op.CreatedAt.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano),
+		PrevHash:               op.PrevHash,
+	}
+
+	// Marshal cannot fail for this struct
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ChainHead is the last link of a wallet chain
+type ChainHead struct {
+	WalletID uuid.UUID `bson:"_id" json:"walletId"`
+	Sequence int64     `bson:"sequence" json:"sequence"`
+	Hash     string    `bson:"hash" json:"hash"`
+}
+
+// ChainBreak kinds
+const (
+	ChainBreakHash     = "HASH_MISMATCH"
+	ChainBreakPrevHash = "PREV_HASH_MISMATCH"
+	ChainBreakSequence = "SEQUENCE_GAP"
+)
+
+type ChainBreak struct {
+	Kind        string    `json:"kind"`
+	Sequence    int64     `json:"sequence"`
+	OperationID uuid.UUID `json:"operationId"`
+	Expected    string    `json:"expected"`
+	Actual      string    `json:"actual"`
+}
+
+// ChainVerification is the result of walking the chain of one wallet
+type ChainVerification struct {
+	WalletID uuid.UUID `json:"walletId"`
+	Valid    bool      `json:"valid"`
+	// Length is the number of chained operations; Unchained counts operations
+	// written before the chain existed, which cannot be verified
+	Length    int64        `json:"length"`
+	Unchained int64        `json:"unchained"`
+	HeadHash  string       `json:"headHash"`
+	Breaks    []ChainBreak `json:"breaks"`
+}
+
+// chainVerifier checks operations one by one in sequence order
+type chainVerifier struct {
+	result       *ChainVerification
+	expectedSeq  int64
+	expectedPrev string
+}
+
+func newChainVerifier(walletID uuid.UUID) *chainVerifier {
+	return &chainVerifier{
+		result: &ChainVerification{
+			WalletID: walletID,
+			Valid:    true,
+			HeadHash: GenesisHash,
+			Breaks:   []ChainBreak{},
+		},
+		expectedSeq:  1,
+		expectedPrev: GenesisHash,
+	}
+}
+
+func (v *chainVerifier) add(op *Operation) {
+	if op.Sequence != v.expectedSeq {
+		v.addBreak(ChainBreakSequence, op, strconv.FormatInt(v.expectedSeq, 10), strconv.FormatInt(op.Sequence, 10))
+	}
+
+	if op.PrevHash != v.expectedPrev {
+		v.addBreak(ChainBreakPrevHash, op, v.expectedPrev, op.PrevHash)
+	}
+
+	if hash := ComputeHash(op); hash != op.Hash {
+		v.addBreak(ChainBreakHash, op, hash, op.Hash)
+	}
+
+	// Continue from what is stored, so one edited record is reported once
+	// instead of breaking every later link
+	v.expectedSeq = op.Sequence + 1
+	v.expectedPrev = op.Hash
+	v.result.Length++
+	v.result.HeadHash = op.Hash
+}
+
+func (v *chainVerifier) addBreak(kind string, op *Operation, expected, actual string) {
+	v.result.Valid = false
+	v.result.Breaks = append(v.result.Breaks, ChainBreak{
+		Kind:        kind,
+		Sequence:    op.Sequence,
+		OperationID: op.OperationID,
+		Expected:    expected,
+		Actual:      actual,
+	})
+}
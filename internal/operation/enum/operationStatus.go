@@ -5,4 +5,7 @@ type OperationStatus string
 const (
 	OperationStatusSuccess OperationStatus = "SUCCESS"
 	OperationStatusError   OperationStatus = "ERROR"
+	// OperationStatusPendingApproval records a debit parked for a second
+	// approver; the outcome is recorded as a new operation
+	OperationStatusPendingApproval OperationStatus = "PENDING_APPROVAL"
 )
@@ -8,4 +8,7 @@ const (
 	OperationTypeWithdraw        OperationType = "WITHDRAW"
 	OperationTypeTransfer        OperationType = "TRANSFER"
 	OperationTypeReceiveTransfer OperationType = "RECEIVE_TRANSFER"
+	// OperationTypeAdjustment is a manual credit (positive) or debit
+	// (negative) made by support, with the reason of the correction
+	OperationTypeAdjustment OperationType = "ADJUSTMENT"
 )
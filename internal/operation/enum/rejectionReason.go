@@ -0,0 +1,17 @@
+package enum
+
+// RejectionReason classifies why an ERROR operation was rejected; it is the
+// reason label of the rejection metrics
+type RejectionReason string
+
+const (
+	RejectionReasonInactive          RejectionReason = "inactive"
+	RejectionReasonBlocked           RejectionReason = "blocked"
+	RejectionReasonInsufficientFunds RejectionReason = "insufficient_funds"
+	RejectionReasonSameWallet        RejectionReason = "same_wallet"
+	RejectionReasonRiskDenied        RejectionReason = "risk_denied"
+	RejectionReasonApprovalRejected  RejectionReason = "approval_rejected"
+	RejectionReasonApprovalExpired   RejectionReason = "approval_expired"
+	// RejectionReasonOther covers error operations recorded without a reason
+	RejectionReasonOther RejectionReason = "other"
+)
@@ -73,6 +73,9 @@ func (h *Handler) List(c *gin.Context) {
 // @Tags Wallet
 // @Accept json
 // @Produce json
+// @Param walletId query string true "Wallet ID"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Param tz query string false "IANA time zone, defaults to the wallet setting"
 // @Success 200 {object} object{date=string,total_deposits=int,total_withdraws=int,total_transfers=int}
 // @Failure 500 {object} object{error=string,message=string}
 // @Router /wallet/daily-summary [get]
@@ -95,7 +98,17 @@ func (h *Handler) GetDailySummary(c *gin.Context) {
 		return
 	}
 
-	date, err := time.Parse("2006-01-02", dateParam)
+	location, err := h.service.ResolveLocation(c.Request.Context(), walletID, c.Query("tz"))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, appErr)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errors.InternalServerError("Failed to resolve time zone"))
+		return
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", dateParam, location)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid date format. Use YYYY-MM-DD"))
 		return
@@ -120,6 +133,9 @@ func (h *Handler) GetDailySummary(c *gin.Context) {
 // @Tags Wallet
 // @Accept json
 // @Produce json
+// @Param walletId query string true "Wallet ID"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Param tz query string false "IANA time zone, defaults to the wallet setting"
 // @Success 200 {object} object{date=string,total_deposits=int,total_withdraws=int,total_transfers=int}
 // @Failure 500 {object} object{error=string,message=string}
 // @Router /wallet/daily-summary-details [get]
@@ -142,7 +158,17 @@ func (h *Handler) GetDailySummaryDetails(c *gin.Context) {
 		return
 	}
 
-	date, err := time.Parse("2006-01-02", dateParam)
+	location, err := h.service.ResolveLocation(c.Request.Context(), walletID, c.Query("tz"))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, appErr)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errors.InternalServerError("Failed to resolve time zone"))
+		return
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", dateParam, location)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid date format. Use YYYY-MM-DD"))
 		return
@@ -161,6 +187,59 @@ func (h *Handler) GetDailySummaryDetails(c *gin.Context) {
 	c.JSON(http.StatusOK, summary)
 }
 
+// GetReport godoc
+// @Summary Get operations report
+// @Description Get operations aggregated in daily, weekly or monthly buckets, broken down by type and status
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param walletId query []string true "Wallet IDs" collectionFormat(multi)
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD)"
+// @Param granularity query string false "day, week or month" default(day)
+// @Param tz query string false "IANA time zone"
+// @Success 200 {object} OperationReportResponse
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /wallet/report [get]
+func (h *Handler) GetReport(c *gin.Context) {
+	walletIDParams := c.QueryArray("walletId")
+	if len(walletIDParams) == 0 {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("walletId is required"))
+		return
+	}
+
+	walletIDs := make([]uuid.UUID, len(walletIDParams))
+	for i, walletIDParam := range walletIDParams {
+		walletID, err := uuid.Parse(walletIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid wallet ID"))
+			return
+		}
+		walletIDs[i] = walletID
+	}
+
+	request := ReportRequest{
+		WalletIDs:   walletIDs,
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+		Granularity: ReportGranularity(c.DefaultQuery("granularity", string(ReportGranularityDay))),
+		TZ:          c.Query("tz"),
+	}
+
+	report, err := h.service.GetReport(c.Request.Context(), request)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, appErr)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errors.InternalServerError("Failed to get operations report"))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 func (h *Handler) mapToResponse(operation *Operation) *OperationResponse {
 	return &OperationResponse{
 		ID:                     operation.OperationID,
@@ -171,7 +250,14 @@ func (h *Handler) mapToResponse(operation *Operation) *OperationResponse {
 		WalletTransactionID:    operation.WalletTransactionID,
 		OperationTransactionID: operation.OperationTransactionID,
 		Reason:                 operation.Reason,
+		Actor:                  operation.Actor,
 		CreatedAt:              operation.CreatedAt,
 		UpdatedAt:              operation.UpdatedAt,
+		Sequence:               operation.Sequence,
+		Hash:                   operation.Hash,
+		PrevHash:               operation.PrevHash,
+		Risk:                   operation.Risk,
+		Rejection:              operation.Rejection,
+		RequestID:              operation.RequestID,
 	}
 }
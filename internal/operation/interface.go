@@ -0,0 +1,14 @@
+package operation
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// WalletTimeZoneProvider returns the IANA time zone configured for a wallet.
+// It is implemented by the wallet service and set after construction, since
+// the wallet package already depends on this one.
+type WalletTimeZoneProvider interface {
+	WalletTimeZone(ctx context.Context, walletID uuid.UUID) (string, error)
+}
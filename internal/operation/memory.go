@@ -0,0 +1,320 @@
+package operation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"wallet-go/internal/operation/enum"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore keeps operations in process with the semantics of Store, for
+// tests and local runs. Times are kept at millisecond precision in UTC, as
+// MongoDB returns them.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	operations []*Operation
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, operation *Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.operations {
+		if existing.OperationID == operation.OperationID {
+			return ErrOperationExists
+		}
+	}
+
+	operation.CreatedAt = time.Now()
+	operation.Sequence = 1
+	operation.PrevHash = GenesisHash
+	if head := s.chainHead(operation.WalletID); head != nil {
+		operation.Sequence = head.Sequence + 1
+		operation.PrevHash = head.Hash
+	}
+	operation.Hash = ComputeHash(operation)
+
+	stored := cloneOperation(operation)
+	stored.CreatedAt = storedTime(stored.CreatedAt)
+	if stored.UpdatedAt != nil {
+		updatedAt := storedTime(*stored.UpdatedAt)
+		stored.UpdatedAt = &updatedAt
+	}
+	s.operations = append(s.operations, stored)
+	return nil
+}
+
+func (s *MemoryStore) FindByID(ctx context.Context, operationID uuid.UUID) (*Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, operation := range s.operations {
+		if operation.OperationID == operationID {
+			return cloneOperation(operation), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) FindByWalletID(ctx context.Context, walletID uuid.UUID) ([]*Operation, error) {
+	return s.find(func(op *Operation) bool { return op.WalletID == walletID }, nil), nil
+}
+
+func (s *MemoryStore) FindByWalletIDAndDateRange(ctx context.Context, walletID uuid.UUID, from, to time.Time) ([]*Operation, error) {
+	return s.find(func(op *Operation) bool {
+		return op.WalletID == walletID && !op.CreatedAt.Before(from) && !op.CreatedAt.After(to)
+	}, byCreatedAt), nil
+}
+
+func (s *MemoryStore) FindByWalletIDAndDate(ctx context.Context, walletID uuid.UUID, date time.Time) ([]*Operation, error) {
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+
+	return s.find(func(op *Operation) bool {
+		return op.WalletID == walletID && !op.CreatedAt.Before(startOfDay) && op.CreatedAt.Before(endOfDay)
+	}, byCreatedAt), nil
+}
+
+func (s *MemoryStore) FindChainHead(ctx context.Context, walletID uuid.UUID) (*Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if head := s.chainHead(walletID); head != nil {
+		return cloneOperation(head), nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) FindBySequence(ctx context.Context, walletID uuid.UUID, sequence int64) (*Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, operation := range s.operations {
+		if operation.WalletID == walletID && operation.Sequence == sequence {
+			return cloneOperation(operation), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) WalkChain(ctx context.Context, walletID uuid.UUID, fn func(*Operation)) error {
+	chain := s.find(func(op *Operation) bool { return op.WalletID == walletID && op.Sequence > 0 }, func(a, b *Operation) bool {
+		return a.Sequence < b.Sequence
+	})
+	for _, operation := range chain {
+		fn(operation)
+	}
+	return nil
+}
+
+func (s *MemoryStore) CountUnchained(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	unchained := s.find(func(op *Operation) bool { return op.WalletID == walletID && op.Sequence <= 0 }, nil)
+	return int64(len(unchained)), nil
+}
+
+func (s *MemoryStore) ChainHeads(ctx context.Context) ([]ChainHead, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	latest := make(map[uuid.UUID]*Operation)
+	for _, operation := range s.operations {
+		if operation.Sequence <= 0 {
+			continue
+		}
+		if head, ok := latest[operation.WalletID]; !ok || operation.Sequence > head.Sequence {
+			latest[operation.WalletID] = operation
+		}
+	}
+
+	heads := []ChainHead{}
+	for walletID, head := range latest {
+		heads = append(heads, ChainHead{WalletID: walletID, Sequence: head.Sequence, Hash: head.Hash})
+	}
+	sort.Slice(heads, func(i, j int) bool { return bytes.Compare(heads[i].WalletID[:], heads[j].WalletID[:]) < 0 })
+
+	return heads, nil
+}
+
+func (s *MemoryStore) LedgerBalances(ctx context.Context, walletIDs []uuid.UUID) ([]LedgerBalance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	totals := make(map[uuid.UUID]*LedgerBalance)
+	for _, operation := range s.operations {
+		if operation.Status != enum.OperationStatusSuccess {
+			continue
+		}
+		if len(walletIDs) > 0 && !containsID(walletIDs, operation.WalletID) {
+			continue
+		}
+
+		total, ok := totals[operation.WalletID]
+		if !ok {
+			total = &LedgerBalance{WalletID: operation.WalletID}
+			totals[operation.WalletID] = total
+		}
+		total.AmountInCents += operation.AmountInCents
+		total.OperationCount++
+	}
+
+	balances := []LedgerBalance{}
+	for _, total := range totals {
+		balances = append(balances, *total)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return bytes.Compare(balances[i].WalletID[:], balances[j].WalletID[:]) < 0
+	})
+
+	return balances, nil
+}
+
+func (s *MemoryStore) CountActivity(ctx context.Context, filter ActivityFilter) (int64, error) {
+	return int64(len(s.find(filter.matches, nil))), nil
+}
+
+func (s *MemoryStore) FindLatestActivity(ctx context.Context, filter ActivityFilter) (*Operation, error) {
+	var latest *Operation
+	for _, operation := range s.find(filter.matches, nil) {
+		if latest == nil || operation.CreatedAt.After(latest.CreatedAt) {
+			latest = operation
+		}
+	}
+	return latest, nil
+}
+
+// matches is activityQuery for MemoryStore
+func (filter ActivityFilter) matches(op *Operation) bool {
+	if op.WalletID != filter.WalletID || op.Status != enum.OperationStatusSuccess {
+		return false
+	}
+	if len(filter.Types) > 0 {
+		found := false
+		for _, operationType := range filter.Types {
+			found = found || op.Type == operationType
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.CounterpartyID != nil && (op.WalletTransactionID == nil || *op.WalletTransactionID != *filter.CounterpartyID) {
+		return false
+	}
+	return filter.Since == nil || !op.CreatedAt.Before(*filter.Since)
+}
+
+func (s *MemoryStore) AggregateReport(ctx context.Context, walletIDs []uuid.UUID, from, to time.Time, granularity ReportGranularity, location *time.Location) ([]reportRow, error) {
+	operations := s.find(func(op *Operation) bool {
+		return containsID(walletIDs, op.WalletID) && !op.CreatedAt.Before(from) && op.CreatedAt.Before(to)
+	}, nil)
+
+	groups := make(map[string]*reportRow)
+	var rows []reportRow
+	for _, operation := range operations {
+		bucket := truncateBucket(operation.CreatedAt, granularity, location)
+		key := fmt.Sprintf("%d|%s|%s", bucket.Unix(), operation.Type, operation.Status)
+
+		row, ok := groups[key]
+		if !ok {
+			row = &reportRow{}
+			row.Key.Bucket = bucket
+			row.Key.Type = operation.Type
+			row.Key.Status = operation.Status
+			groups[key] = row
+		}
+		row.Count++
+		row.AmountInCents += operation.AmountInCents
+	}
+
+	for _, row := range groups {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i].Key, rows[j].Key
+		if !a.Bucket.Equal(b.Bucket) {
+			return a.Bucket.Before(b.Bucket)
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Status < b.Status
+	})
+
+	return rows, nil
+}
+
+// truncateBucket is $dateTrunc: the start of the day, the week starting on
+// Monday or the month of t in location, returned in UTC
+func truncateBucket(t time.Time, granularity ReportGranularity, location *time.Location) time.Time {
+	local := t.In(location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+
+	switch granularity {
+	case ReportGranularityWeek:
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	case ReportGranularityMonth:
+		start = time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, location)
+	}
+	return start.UTC()
+}
+
+// chainHead is FindChainHead without locking; the caller holds mu
+func (s *MemoryStore) chainHead(walletID uuid.UUID) *Operation {
+	var head *Operation
+	for _, operation := range s.operations {
+		if operation.WalletID == walletID && operation.Sequence > 0 && (head == nil || operation.Sequence > head.Sequence) {
+			head = operation
+		}
+	}
+	return head
+}
+
+// find returns copies of the matching operations in insertion order, or
+// sorted by less when given
+func (s *MemoryStore) find(match func(*Operation) bool, less func(a, b *Operation) bool) []*Operation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var operations []*Operation
+	for _, operation := range s.operations {
+		if match(operation) {
+			operations = append(operations, cloneOperation(operation))
+		}
+	}
+	if less != nil {
+		sort.SliceStable(operations, func(i, j int) bool { return less(operations[i], operations[j]) })
+	}
+	return operations
+}
+
+func byCreatedAt(a, b *Operation) bool {
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+func cloneOperation(operation *Operation) *Operation {
+	clone := *operation
+	return &clone
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// storedTime is t as MongoDB returns it
+func storedTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Millisecond)
+}
@@ -0,0 +1,45 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrOperationExists is returned by Create when the operation ID is already
+// recorded
+var ErrOperationExists = errors.New("operation already recorded")
+
+// Repository stores operations; implemented by Store on MongoDB and by
+// MemoryStore for tests. Finders return nil, nil when nothing matches.
+type Repository interface {
+	// Create appends the operation to the hash chain of its wallet, setting
+	// CreatedAt, Sequence, PrevHash and Hash. A duplicate OperationID fails
+	// with ErrOperationExists.
+	Create(ctx context.Context, operation *Operation) error
+	FindByID(ctx context.Context, operationID uuid.UUID) (*Operation, error)
+	// FindByWalletID returns the operations of a wallet in insertion order
+	FindByWalletID(ctx context.Context, walletID uuid.UUID) ([]*Operation, error)
+	// FindByWalletIDAndDateRange includes both ends; results are sorted by
+	// CreatedAt
+	FindByWalletIDAndDateRange(ctx context.Context, walletID uuid.UUID, from, to time.Time) ([]*Operation, error)
+	// FindByWalletIDAndDate returns the operations of the calendar day of date,
+	// in the location of date, sorted by CreatedAt
+	FindByWalletIDAndDate(ctx context.Context, walletID uuid.UUID, date time.Time) ([]*Operation, error)
+
+	FindChainHead(ctx context.Context, walletID uuid.UUID) (*Operation, error)
+	FindBySequence(ctx context.Context, walletID uuid.UUID, sequence int64) (*Operation, error)
+	WalkChain(ctx context.Context, walletID uuid.UUID, fn func(*Operation)) error
+	CountUnchained(ctx context.Context, walletID uuid.UUID) (int64, error)
+	// ChainHeads is sorted by wallet ID
+	ChainHeads(ctx context.Context) ([]ChainHead, error)
+
+	LedgerBalances(ctx context.Context, walletIDs []uuid.UUID) ([]LedgerBalance, error)
+	CountActivity(ctx context.Context, filter ActivityFilter) (int64, error)
+	FindLatestActivity(ctx context.Context, filter ActivityFilter) (*Operation, error)
+	// AggregateReport returns one row per bucket, type and status, sorted by
+	// bucket, type and status. The range excludes to.
+	AggregateReport(ctx context.Context, walletIDs []uuid.UUID, from, to time.Time, granularity ReportGranularity, location *time.Location) ([]reportRow, error)
+}
@@ -0,0 +1,314 @@
+package operation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/database/mongotest"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		return NewMemoryStore()
+	})
+}
+
+func TestStore(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		return NewStore(mongotest.Connect(t))
+	})
+}
+
+// testRepository is the contract every Repository implementation must meet.
+// Each case gets an empty repository.
+func testRepository(t *testing.T, newRepository func(t *testing.T) Repository) {
+	ctx := context.Background()
+
+	t.Run("Create chains the operations of each wallet", func(t *testing.T) {
+		repository := newRepository(t)
+		walletID, otherID := uuid.New(), uuid.New()
+
+		first := create(t, repository, walletID, enum.OperationTypeCreated, enum.OperationStatusSuccess, 0)
+		second := create(t, repository, walletID, enum.OperationTypeDeposit, enum.OperationStatusSuccess, 500)
+		third := create(t, repository, walletID, enum.OperationTypeWithdraw, enum.OperationStatusError, -900)
+		create(t, repository, otherID, enum.OperationTypeCreated, enum.OperationStatusSuccess, 0)
+
+		if first.Sequence != 1 || first.PrevHash != GenesisHash {
+			t.Fatalf("first link = %d/%s, want 1/genesis", first.Sequence, first.PrevHash)
+		}
+		if second.Sequence != 2 || second.PrevHash != first.Hash || third.PrevHash != second.Hash {
+			t.Fatal("operations are not linked to the previous hash")
+		}
+
+		head, err := repository.FindChainHead(ctx, walletID)
+		if err != nil || head == nil || head.OperationID != third.OperationID {
+			t.Fatalf("FindChainHead = %v, %v, want the third operation", head, err)
+		}
+		found, err := repository.FindBySequence(ctx, walletID, 2)
+		if err != nil || found == nil || found.OperationID != second.OperationID {
+			t.Fatalf("FindBySequence(2) = %v, %v, want the second operation", found, err)
+		}
+		if found.Hash != ComputeHash(found) {
+			t.Error("stored operation does not match its hash")
+		}
+
+		var walked []int64
+		if err := repository.WalkChain(ctx, walletID, func(op *Operation) { walked = append(walked, op.Sequence) }); err != nil {
+			t.Fatal(err)
+		}
+		if len(walked) != 3 || walked[0] != 1 || walked[2] != 3 {
+			t.Errorf("WalkChain visited %v, want [1 2 3]", walked)
+		}
+
+		unchained, err := repository.CountUnchained(ctx, walletID)
+		if err != nil || unchained != 0 {
+			t.Errorf("CountUnchained = %d, %v, want 0", unchained, err)
+		}
+
+		heads, err := repository.ChainHeads(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(heads) != 2 {
+			t.Fatalf("ChainHeads returned %d heads, want 2", len(heads))
+		}
+		for _, head := range heads {
+			if head.WalletID == walletID && (head.Sequence != 3 || head.Hash != third.Hash) {
+				t.Errorf("head of wallet = %d/%s, want 3/%s", head.Sequence, head.Hash, third.Hash)
+			}
+		}
+		if heads[0].WalletID.String() > heads[1].WalletID.String() {
+			t.Error("ChainHeads is not sorted by wallet ID")
+		}
+	})
+
+	t.Run("Create rejects a duplicate operation ID", func(t *testing.T) {
+		repository := newRepository(t)
+		op := create(t, repository, uuid.New(), enum.OperationTypeCreated, enum.OperationStatusSuccess, 0)
+
+		duplicate := &Operation{OperationID: op.OperationID, WalletID: op.WalletID, Type: enum.OperationTypeDeposit, Status: enum.OperationStatusSuccess}
+		if err := repository.Create(ctx, duplicate); err != ErrOperationExists {
+			t.Fatalf("Create of a duplicate operation ID = %v, want ErrOperationExists", err)
+		}
+	})
+
+	t.Run("FindByID returns nil when missing", func(t *testing.T) {
+		repository := newRepository(t)
+
+		found, err := repository.FindByID(ctx, uuid.New())
+		if err != nil || found != nil {
+			t.Fatalf("FindByID = %v, %v, want nil, nil", found, err)
+		}
+		head, err := repository.FindChainHead(ctx, uuid.New())
+		if err != nil || head != nil {
+			t.Fatalf("FindChainHead = %v, %v, want nil, nil", head, err)
+		}
+	})
+
+	t.Run("FindByWalletID returns the wallet operations in order", func(t *testing.T) {
+		repository := newRepository(t)
+		walletID := uuid.New()
+
+		first := create(t, repository, walletID, enum.OperationTypeCreated, enum.OperationStatusSuccess, 0)
+		second := create(t, repository, walletID, enum.OperationTypeDeposit, enum.OperationStatusSuccess, 100)
+		create(t, repository, uuid.New(), enum.OperationTypeCreated, enum.OperationStatusSuccess, 0)
+
+		operations, err := repository.FindByWalletID(ctx, walletID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(operations) != 2 || operations[0].OperationID != first.OperationID || operations[1].OperationID != second.OperationID {
+			t.Fatalf("FindByWalletID returned %d operations, want the two of the wallet in order", len(operations))
+		}
+
+		none, err := repository.FindByWalletID(ctx, uuid.New())
+		if err != nil || len(none) != 0 {
+			t.Fatalf("FindByWalletID of an unknown wallet = %v, %v, want none", none, err)
+		}
+	})
+
+	t.Run("date queries select by CreatedAt", func(t *testing.T) {
+		repository := newRepository(t)
+		walletID := uuid.New()
+
+		var operations []*Operation
+		for i := 0; i < 3; i++ {
+			operations = append(operations, create(t, repository, walletID, enum.OperationTypeDeposit, enum.OperationStatusSuccess, 100))
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		// Both ends are included
+		inRange, err := repository.FindByWalletIDAndDateRange(ctx, walletID, operations[0].CreatedAt, operations[1].CreatedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(inRange) != 2 || inRange[0].OperationID != operations[0].OperationID {
+			t.Fatalf("FindByWalletIDAndDateRange returned %d operations, want the first two", len(inRange))
+		}
+
+		day := operations[0].CreatedAt
+		var sameDay int
+		for _, op := range operations {
+			if op.CreatedAt.YearDay() == day.YearDay() {
+				sameDay++
+			}
+		}
+		onDay, err := repository.FindByWalletIDAndDate(ctx, walletID, day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(onDay) != sameDay {
+			t.Fatalf("FindByWalletIDAndDate returned %d operations, want %d", len(onDay), sameDay)
+		}
+
+		nextDay, err := repository.FindByWalletIDAndDate(ctx, walletID, day.AddDate(0, 0, 2))
+		if err != nil || len(nextDay) != 0 {
+			t.Fatalf("FindByWalletIDAndDate two days later = %d, %v, want none", len(nextDay), err)
+		}
+	})
+
+	t.Run("LedgerBalances sums successful operations", func(t *testing.T) {
+		repository := newRepository(t)
+		walletID, otherID := uuid.New(), uuid.New()
+
+		create(t, repository, walletID, enum.OperationTypeDeposit, enum.OperationStatusSuccess, 1000)
+		create(t, repository, walletID, enum.OperationTypeWithdraw, enum.OperationStatusSuccess, -300)
+		create(t, repository, walletID, enum.OperationTypeWithdraw, enum.OperationStatusError, -5000)
+		create(t, repository, otherID, enum.OperationTypeDeposit, enum.OperationStatusSuccess, 50)
+
+		all, err := repository.LedgerBalances(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 2 {
+			t.Fatalf("LedgerBalances returned %d wallets, want 2", len(all))
+		}
+
+		one, err := repository.LedgerBalances(ctx, []uuid.UUID{walletID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(one) != 1 || one[0].WalletID != walletID || one[0].AmountInCents != 700 || one[0].OperationCount != 2 {
+			t.Fatalf("LedgerBalances(wallet) = %+v, want 700 over 2 operations", one)
+		}
+	})
+
+	t.Run("activity filters successful operations", func(t *testing.T) {
+		repository := newRepository(t)
+		walletID, counterpartyID := uuid.New(), uuid.New()
+
+		create(t, repository, walletID, enum.OperationTypeWithdraw, enum.OperationStatusSuccess, -100)
+		create(t, repository, walletID, enum.OperationTypeWithdraw, enum.OperationStatusError, -100)
+		time.Sleep(5 * time.Millisecond)
+		transfer := &Operation{
+			OperationID:         uuid.New(),
+			WalletID:            walletID,
+			Type:                enum.OperationTypeTransfer,
+			Status:              enum.OperationStatusSuccess,
+			AmountInCents:       -200,
+			WalletTransactionID: &counterpartyID,
+		}
+		if err := repository.Create(ctx, transfer); err != nil {
+			t.Fatal(err)
+		}
+
+		count := func(filter ActivityFilter) int64 {
+			t.Helper()
+			n, err := repository.CountActivity(ctx, filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return n
+		}
+
+		if n := count(ActivityFilter{WalletID: walletID}); n != 2 {
+			t.Errorf("CountActivity = %d, want 2 successful operations", n)
+		}
+		if n := count(ActivityFilter{WalletID: walletID, Types: []enum.OperationType{enum.OperationTypeWithdraw}}); n != 1 {
+			t.Errorf("CountActivity(withdraw) = %d, want 1", n)
+		}
+		if n := count(ActivityFilter{WalletID: walletID, CounterpartyID: &counterpartyID}); n != 1 {
+			t.Errorf("CountActivity(counterparty) = %d, want 1", n)
+		}
+		future := time.Now().Add(time.Hour)
+		if n := count(ActivityFilter{WalletID: walletID, Since: &future}); n != 0 {
+			t.Errorf("CountActivity(since an hour from now) = %d, want 0", n)
+		}
+
+		latest, err := repository.FindLatestActivity(ctx, ActivityFilter{WalletID: walletID})
+		if err != nil || latest == nil || latest.OperationID != transfer.OperationID {
+			t.Fatalf("FindLatestActivity = %v, %v, want the transfer", latest, err)
+		}
+		none, err := repository.FindLatestActivity(ctx, ActivityFilter{WalletID: uuid.New()})
+		if err != nil || none != nil {
+			t.Fatalf("FindLatestActivity of an unknown wallet = %v, %v, want nil", none, err)
+		}
+	})
+
+	t.Run("AggregateReport groups by bucket, type and status", func(t *testing.T) {
+		repository := newRepository(t)
+		walletID := uuid.New()
+		location, err := time.LoadLocation("America/Sao_Paulo")
+		if err != nil {
+			t.Skip("time zone database not available")
+		}
+
+		create(t, repository, walletID, enum.OperationTypeDeposit, enum.OperationStatusSuccess, 100)
+		create(t, repository, walletID, enum.OperationTypeDeposit, enum.OperationStatusSuccess, 200)
+		last := create(t, repository, walletID, enum.OperationTypeWithdraw, enum.OperationStatusError, -50)
+		create(t, repository, uuid.New(), enum.OperationTypeDeposit, enum.OperationStatusSuccess, 999)
+
+		from, to := time.Now().AddDate(0, 0, -40), time.Now().AddDate(0, 0, 1)
+		for _, granularity := range []ReportGranularity{ReportGranularityDay, ReportGranularityWeek, ReportGranularityMonth} {
+			rows, err := repository.AggregateReport(ctx, []uuid.UUID{walletID}, from, to, granularity, location)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The operations were written within milliseconds, so they share a
+			// bucket unless they straddle its boundary
+			bucket := truncateBucket(last.CreatedAt, granularity, location)
+			var count, amount int64
+			for _, row := range rows {
+				if !row.Key.Bucket.Equal(bucket) {
+					continue
+				}
+				count += row.Count
+				amount += row.AmountInCents
+				if row.Key.Type == enum.OperationTypeDeposit && row.Key.Status == enum.OperationStatusSuccess && row.Count != 2 {
+					t.Errorf("%s: %d successful deposits, want 2", granularity, row.Count)
+				}
+			}
+			if len(rows) != 2 || count != 3 || amount != 250 {
+				t.Errorf("%s: %d rows, %d operations, %d cents; want 2 rows, 3 operations, 250 cents", granularity, len(rows), count, amount)
+			}
+		}
+	})
+}
+
+// create writes an operation and returns it as stored
+func create(t *testing.T, repository Repository, walletID uuid.UUID, operationType enum.OperationType, status enum.OperationStatus, amountInCents int64) *Operation {
+	t.Helper()
+
+	op := &Operation{
+		OperationID:   uuid.New(),
+		WalletID:      walletID,
+		Type:          operationType,
+		Status:        status,
+		AmountInCents: amountInCents,
+		Reason:        "contract test",
+	}
+	if err := repository.Create(context.Background(), op); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	stored, err := repository.FindByID(context.Background(), op.OperationID)
+	if err != nil || stored == nil {
+		t.Fatalf("FindByID after Create = %v, %v", stored, err)
+	}
+	return stored
+}
@@ -11,15 +11,52 @@ import (
 )
 
 type Service struct {
-	store *Store
+	store            Repository
+	defaultLocation  *time.Location
+	timeZoneProvider WalletTimeZoneProvider
 }
 
-func NewService(store *Store) *Service {
+func NewService(store Repository, defaultLocation *time.Location) *Service {
+	if defaultLocation == nil {
+		defaultLocation = time.UTC
+	}
+
 	return &Service{
-		store: store,
+		store:           store,
+		defaultLocation: defaultLocation,
 	}
 }
 
+func (s *Service) SetTimeZoneProvider(provider WalletTimeZoneProvider) {
+	s.timeZoneProvider = provider
+}
+
+// ResolveLocation picks the location used for day boundaries: the explicit tz
+// parameter first, then the wallet setting, then the configured default.
+func (s *Service) ResolveLocation(ctx context.Context, walletID uuid.UUID, tz string) (*time.Location, error) {
+	if tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, errors.BadRequest("Invalid time zone. Use an IANA name such as America/Sao_Paulo")
+		}
+		return location, nil
+	}
+
+	if s.timeZoneProvider != nil && walletID != uuid.Nil {
+		walletTZ, err := s.timeZoneProvider.WalletTimeZone(ctx, walletID)
+		if err != nil {
+			return nil, errors.InternalServerError("Failed to get wallet time zone")
+		}
+		if walletTZ != "" {
+			if location, err := time.LoadLocation(walletTZ); err == nil {
+				return location, nil
+			}
+		}
+	}
+
+	return s.defaultLocation, nil
+}
+
 func (s *Service) GetByWalletID(ctx context.Context, walletID uuid.UUID) ([]Operation, error) {
 	operationPointers, err := s.store.FindByWalletID(ctx, walletID)
 	if err != nil {
@@ -49,18 +86,23 @@ func (s *Service) GetByID(ctx context.Context, operationID uuid.UUID) (*Operatio
 }
 
 func (s *Service) List(ctx context.Context, request OperationFilterRequest) ([]*Operation, error) {
-	from, err := time.Parse("2006-01-02", request.From)
+	location, err := s.ResolveLocation(ctx, request.WalletID, request.TZ)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", request.From, location)
 	if err != nil {
 		return nil, errors.BadRequest("Invalid from date format")
 	}
 
-	to, err := time.Parse("2006-01-02", request.To)
+	to, err := time.ParseInLocation("2006-01-02", request.To, location)
 	if err != nil {
 		return nil, errors.BadRequest("Invalid to date format")
 	}
 
 	// Add one day to include the full "to" date
-	to = to.Add(24 * time.Hour)
+	to = to.AddDate(0, 0, 1)
 
 	operations, err := s.store.FindByWalletIDAndDateRange(ctx, request.WalletID, from, to)
 	if err != nil {
@@ -106,7 +148,9 @@ func (s *Service) validateDailySummaryParams(walletID uuid.UUID, date time.Time)
 		return errors.BadRequest("Date cannot be null")
 	}
 
-	today := time.Now().Truncate(24 * time.Hour)
+	// "today" is evaluated in the same location the date was parsed in
+	year, month, day := time.Now().In(date.Location()).Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, date.Location())
 	if date.After(today) {
 		return errors.BadRequest("Summary request cannot be for future dates")
 	}
@@ -143,6 +187,7 @@ func (s *Service) buildDailySummaryData(ctx context.Context, walletID uuid.UUID,
 		enum.OperationTypeWithdraw:        true,
 		enum.OperationTypeTransfer:        true,
 		enum.OperationTypeReceiveTransfer: true,
+		enum.OperationTypeAdjustment:      true,
 	}
 
 	for _, op := range operations {
@@ -181,7 +226,126 @@ func (s *Service) buildSummaryResponse(summaryData *dailySummaryData, operationI
 	return &OperationDailySummaryResponse{
 		WalletID:          summaryData.WalletID,
 		DateBalanceWallet: summaryData.Date.Format("2006-01-02"),
+		TimeZone:          summaryData.Date.Location().String(),
 		WalletBalanceDay:  summaryData.WalletBalanceDay,
 		Operations:        operationItems,
 	}
 }
+
+func (s *Service) GetReport(ctx context.Context, request ReportRequest) (*OperationReportResponse, error) {
+	if len(request.WalletIDs) == 0 {
+		return nil, errors.BadRequest("walletId is required")
+	}
+
+	switch request.Granularity {
+	case ReportGranularityDay, ReportGranularityWeek, ReportGranularityMonth:
+	default:
+		return nil, errors.BadRequest("Invalid granularity. Use day, week or month")
+	}
+
+	// A single wallet may carry its own time zone; mixed reports use the tz
+	// parameter or the default
+	walletForLocation := uuid.Nil
+	if len(request.WalletIDs) == 1 {
+		walletForLocation = request.WalletIDs[0]
+	}
+
+	location, err := s.ResolveLocation(ctx, walletForLocation, request.TZ)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", request.From, location)
+	if err != nil {
+		return nil, errors.BadRequest("Invalid from date format")
+	}
+
+	to, err := time.ParseInLocation("2006-01-02", request.To, location)
+	if err != nil {
+		return nil, errors.BadRequest("Invalid to date format")
+	}
+
+	if to.Before(from) {
+		return nil, errors.BadRequest("The from date must not be after the to date")
+	}
+
+	rows, err := s.store.AggregateReport(ctx, request.WalletIDs, from, to.AddDate(0, 0, 1), request.Granularity, location)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to build operations report")
+	}
+
+	return &OperationReportResponse{
+		WalletIDs:   request.WalletIDs,
+		From:        request.From,
+		To:          request.To,
+		Granularity: request.Granularity,
+		TimeZone:    location.String(),
+		Buckets:     s.mapRowsToBuckets(rows, location),
+	}, nil
+}
+
+// mapRowsToBuckets folds the sorted aggregation rows into one item per bucket
+func (s *Service) mapRowsToBuckets(rows []reportRow, location *time.Location) []OperationReportItem {
+	buckets := []OperationReportItem{}
+
+	for _, row := range rows {
+		start := row.Key.Bucket.In(location).Format("2006-01-02")
+		if len(buckets) == 0 || buckets[len(buckets)-1].Start != start {
+			buckets = append(buckets, OperationReportItem{
+				Start:     start,
+				Breakdown: []OperationReportBreakdown{},
+			})
+		}
+
+		bucket := &buckets[len(buckets)-1]
+		bucket.OperationCount += row.Count
+		if row.Key.Status == enum.OperationStatusError {
+			bucket.ErrorCount += row.Count
+		} else if row.Key.Status == enum.OperationStatusSuccess {
+			bucket.NetAmountInCents += row.AmountInCents
+		}
+
+		bucket.Breakdown = append(bucket.Breakdown, OperationReportBreakdown{
+			Type:          row.Key.Type,
+			Status:        row.Key.Status,
+			Count:         row.Count,
+			AmountInCents: row.AmountInCents,
+		})
+	}
+
+	return buckets
+}
+
+// VerifyChain walks the hash chain of a wallet and reports every break
+func (s *Service) VerifyChain(ctx context.Context, walletID uuid.UUID) (*ChainVerification, error) {
+	verifier := newChainVerifier(walletID)
+	if err := s.store.WalkChain(ctx, walletID, verifier.add); err != nil {
+		return nil, errors.InternalServerError("Failed to read operation chain")
+	}
+
+	unchained, err := s.store.CountUnchained(ctx, walletID)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to read operation chain")
+	}
+	verifier.result.Unchained = unchained
+
+	return verifier.result, nil
+}
+
+// ChainHeads returns the current head of every wallet chain
+func (s *Service) ChainHeads(ctx context.Context) ([]ChainHead, error) {
+	heads, err := s.store.ChainHeads(ctx)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to get chain heads")
+	}
+	return heads, nil
+}
+
+// GetChainLink returns the operation at a sequence of a wallet chain, or nil
+func (s *Service) GetChainLink(ctx context.Context, walletID uuid.UUID, sequence int64) (*Operation, error) {
+	operation, err := s.store.FindBySequence(ctx, walletID, sequence)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to get operation")
+	}
+	return operation, nil
+}
@@ -2,8 +2,10 @@ package operation
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"wallet-go/internal/operation/enum"
 	"wallet-go/internal/shared/database"
 
 	"github.com/google/uuid"
@@ -22,13 +24,176 @@ func NewStore(db *database.MongoClient) *Store {
 	}
 }
 
+// maxAppendAttempts bounds retries when another writer appended to the same
+// wallet chain first
+const maxAppendAttempts = 5
+
+// Unique indexes of the operation collection, created by the migrations
+const (
+	operationIDIndex = "operationId_unique"
+	chainIndex       = "walletId_sequence_unique"
+)
+
+// Create appends the operation to the hash chain of its wallet. The unique
+// (walletId, sequence) index makes concurrent appends fail instead of forking
+// the chain; the loser reads the new head and tries again.
 func (s *Store) Create(ctx context.Context, operation *Operation) error {
 	operation.CreatedAt = time.Now()
 
-	_, err := s.collection.InsertOne(ctx, operation)
+	var err error
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		head, headErr := s.FindChainHead(ctx, operation.WalletID)
+		if headErr != nil {
+			return headErr
+		}
+
+		operation.Sequence = 1
+		operation.PrevHash = GenesisHash
+		if head != nil {
+			operation.Sequence = head.Sequence + 1
+			operation.PrevHash = head.Hash
+		}
+		operation.Hash = ComputeHash(operation)
+
+		_, err = s.collection.InsertOne(ctx, operation)
+		switch {
+		case database.IsDuplicateKeyOn(err, operationIDIndex):
+			// Final: a redelivery or a replay of an applied message
+			return ErrOperationExists
+		case !database.IsDuplicateKeyOn(err, chainIndex):
+			return err
+		case mongo.SessionFromContext(ctx) != nil:
+			// A failed write aborts the transaction of ctx; the transaction is
+			// retried as a whole instead
+			return fmt.Errorf("%w: %v", database.ErrTransactionConflict, err)
+		}
+	}
+
 	return err
 }
 
+// FindChainHead returns the last chained operation of a wallet, or nil when
+// the wallet has none
+func (s *Store) FindChainHead(ctx context.Context, walletID uuid.UUID) (*Operation, error) {
+	var operation Operation
+	filter := bson.M{"walletId": walletID, "sequence": bson.M{"$gt": 0}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "sequence", Value: -1}})
+
+	err := s.collection.FindOne(ctx, filter, opts).Decode(&operation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &operation, nil
+}
+
+// FindBySequence returns the chained operation of a wallet at a sequence
+func (s *Store) FindBySequence(ctx context.Context, walletID uuid.UUID, sequence int64) (*Operation, error) {
+	var operation Operation
+	filter := bson.M{"walletId": walletID, "sequence": sequence}
+
+	err := s.collection.FindOne(ctx, filter).Decode(&operation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &operation, nil
+}
+
+// WalkChain calls fn for every chained operation of a wallet in sequence
+// order, without loading the whole chain in memory
+func (s *Store) WalkChain(ctx context.Context, walletID uuid.UUID, fn func(*Operation)) error {
+	filter := bson.M{"walletId": walletID, "sequence": bson.M{"$gt": 0}}
+	opts := options.Find().SetSort(bson.D{{Key: "sequence", Value: 1}})
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var operation Operation
+		if err := cursor.Decode(&operation); err != nil {
+			return err
+		}
+		fn(&operation)
+	}
+
+	return cursor.Err()
+}
+
+// CountUnchained counts operations written before the hash chain existed
+func (s *Store) CountUnchained(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	filter := bson.M{"walletId": walletID, "sequence": bson.M{"$not": bson.M{"$gt": 0}}}
+	return s.collection.CountDocuments(ctx, filter)
+}
+
+// ChainHeads returns the last chained operation of every wallet
+func (s *Store) ChainHeads(ctx context.Context) ([]ChainHead, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"sequence": bson.M{"$gt": 0}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "walletId", Value: 1}, {Key: "sequence", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$walletId",
+			"sequence": bson.M{"$first": "$sequence"},
+			"hash":     bson.M{"$first": "$hash"},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	heads := []ChainHead{}
+	if err := cursor.All(ctx, &heads); err != nil {
+		return nil, err
+	}
+
+	return heads, nil
+}
+
+// LedgerBalances sums the successful operations of each wallet, or of every
+// wallet when walletIDs is empty. The sum is the balance the wallet should
+// have.
+func (s *Store) LedgerBalances(ctx context.Context, walletIDs []uuid.UUID) ([]LedgerBalance, error) {
+	match := bson.M{"status": enum.OperationStatusSuccess}
+	if len(walletIDs) > 0 {
+		match["walletId"] = bson.M{"$in": walletIDs}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":            "$walletId",
+			"amountInCents":  bson.M{"$sum": "$amountInCents"},
+			"operationCount": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	balances := []LedgerBalance{}
+	if err := cursor.All(ctx, &balances); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
 func (s *Store) FindByWalletID(ctx context.Context, walletID uuid.UUID) ([]*Operation, error) {
 	filter := bson.M{"walletId": walletID}
 
@@ -65,6 +230,45 @@ func (s *Store) FindByID(ctx context.Context, operationID uuid.UUID) (*Operation
 	return &operation, nil
 }
 
+// CountActivity counts the successful operations matching the filter
+func (s *Store) CountActivity(ctx context.Context, filter ActivityFilter) (int64, error) {
+	return s.collection.CountDocuments(ctx, activityQuery(filter))
+}
+
+// FindLatestActivity returns the most recent successful operation matching
+// the filter, or nil when there is none
+func (s *Store) FindLatestActivity(ctx context.Context, filter ActivityFilter) (*Operation, error) {
+	var operation Operation
+	opts := options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	err := s.collection.FindOne(ctx, activityQuery(filter), opts).Decode(&operation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &operation, nil
+}
+
+func activityQuery(filter ActivityFilter) bson.M {
+	query := bson.M{
+		"walletId": filter.WalletID,
+		"status":   enum.OperationStatusSuccess,
+	}
+	if len(filter.Types) > 0 {
+		query["type"] = bson.M{"$in": filter.Types}
+	}
+	if filter.CounterpartyID != nil {
+		query["walletTransactionId"] = *filter.CounterpartyID
+	}
+	if filter.Since != nil {
+		query["createdAt"] = bson.M{"$gte": *filter.Since}
+	}
+	return query
+}
+
 func (s *Store) FindByWalletIDAndDateRange(ctx context.Context, walletID uuid.UUID, from, to time.Time) ([]*Operation, error) {
 	filter := bson.M{
 		"walletId": walletID,
@@ -94,8 +298,10 @@ func (s *Store) FindByWalletIDAndDateRange(ctx context.Context, walletID uuid.UU
 }
 
 func (s *Store) FindByWalletIDAndDate(ctx context.Context, walletID uuid.UUID, date time.Time) ([]*Operation, error) {
+	// Day boundaries follow the date's location, so callers must parse the
+	// date in the wallet's time zone rather than UTC
 	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
+	endOfDay := startOfDay.AddDate(0, 0, 1)
 
 	filter := bson.M{
 		"walletId": walletID,
@@ -123,3 +329,53 @@ func (s *Store) FindByWalletIDAndDate(ctx context.Context, walletID uuid.UUID, d
 
 	return operations, cursor.Err()
 }
+
+// AggregateReport groups operations into calendar buckets computed by MongoDB
+// in the given location, broken down by type and status
+func (s *Store) AggregateReport(ctx context.Context, walletIDs []uuid.UUID, from, to time.Time, granularity ReportGranularity, location *time.Location) ([]reportRow, error) {
+	bucket := bson.M{
+		"date":     "$createdAt",
+		"unit":     string(granularity),
+		"timezone": location.String(),
+	}
+	if granularity == ReportGranularityWeek {
+		bucket["startOfWeek"] = "monday"
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"walletId": bson.M{"$in": walletIDs},
+			"createdAt": bson.M{
+				"$gte": from,
+				"$lt":  to,
+			},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"bucket": bson.M{"$dateTrunc": bucket},
+				"type":   "$type",
+				"status": "$status",
+			},
+			"count":         bson.M{"$sum": 1},
+			"amountInCents": bson.M{"$sum": "$amountInCents"},
+		}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "_id.bucket", Value: 1},
+			{Key: "_id.type", Value: 1},
+			{Key: "_id.status", Value: 1},
+		}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []reportRow
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
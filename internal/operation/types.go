@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/auth"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -18,8 +19,62 @@ type Operation struct {
 	WalletTransactionID    *uuid.UUID           `bson:"walletTransactionId,omitempty" json:"walletTransactionId,omitempty"`
 	OperationTransactionID *uuid.UUID           `bson:"operationTransactionId,omitempty" json:"operationTransactionId,omitempty"`
 	Reason                 string               `bson:"reason" json:"reason"`
+	Actor                  *auth.Actor          `bson:"actor,omitempty" json:"actor,omitempty"`
 	CreatedAt              time.Time            `bson:"createdAt" json:"createdAt"`
 	UpdatedAt              *time.Time           `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+	// Sequence, PrevHash and Hash link the operations of a wallet into a hash
+	// chain; see chain.go. Operations written before the chain have sequence 0.
+	Sequence int64  `bson:"sequence,omitempty" json:"sequence,omitempty"`
+	PrevHash string `bson:"prevHash,omitempty" json:"prevHash,omitempty"`
+	Hash     string `bson:"hash,omitempty" json:"hash,omitempty"`
+	// Risk is the decision of the risk rules evaluated before a debit
+	Risk *RiskAssessment `bson:"risk,omitempty" json:"risk,omitempty"`
+	// Rejection classifies an ERROR operation for the metrics; the Reason
+	// text is for people. It is not part of the hash chain.
+	Rejection enum.RejectionReason `bson:"rejection,omitempty" json:"rejection,omitempty"`
+	// RequestID correlates the operation with the request logs; it is not
+	// part of the hash chain
+	RequestID string `bson:"requestId,omitempty" json:"requestId,omitempty"`
+}
+
+// RiskAction is the outcome of a risk rule. The assessment takes the most
+// severe action of the rules that matched: deny, then review, then allow.
+type RiskAction string
+
+const (
+	RiskActionAllow  RiskAction = "allow"
+	RiskActionReview RiskAction = "review"
+	RiskActionDeny   RiskAction = "deny"
+)
+
+type RiskAssessment struct {
+	Action RiskAction `bson:"action" json:"action"`
+	Hits   []RiskHit  `bson:"hits,omitempty" json:"hits,omitempty"`
+}
+
+// RiskHit is a rule that matched the operation
+type RiskHit struct {
+	RuleID uuid.UUID  `bson:"ruleId" json:"ruleId"`
+	Name   string     `bson:"name" json:"name"`
+	Kind   string     `bson:"kind" json:"kind"`
+	Action RiskAction `bson:"action" json:"action"`
+	Detail string     `bson:"detail" json:"detail"`
+}
+
+// LedgerBalance is the sum of the successful operations of a wallet
+type LedgerBalance struct {
+	WalletID       uuid.UUID `bson:"_id"`
+	AmountInCents  int64     `bson:"amountInCents"`
+	OperationCount int64     `bson:"operationCount"`
+}
+
+// ActivityFilter selects successful operations of a wallet for the risk
+// rules. Empty fields match everything.
+type ActivityFilter struct {
+	WalletID       uuid.UUID
+	Types          []enum.OperationType
+	CounterpartyID *uuid.UUID
+	Since          *time.Time
 }
 
 type OperationResponse struct {
@@ -31,19 +86,28 @@ type OperationResponse struct {
 	WalletTransactionID    *uuid.UUID           `json:"walletTransactionId,omitempty"`
 	OperationTransactionID *uuid.UUID           `json:"operationTransactionId,omitempty"`
 	Reason                 string               `json:"reason"`
+	Actor                  *auth.Actor          `json:"actor,omitempty"`
 	CreatedAt              time.Time            `json:"createdAt"`
 	UpdatedAt              *time.Time           `json:"updatedAt,omitempty"`
+	Sequence               int64                `json:"sequence,omitempty"`
+	Hash                   string               `json:"hash,omitempty"`
+	PrevHash               string               `json:"prevHash,omitempty"`
+	Risk                   *RiskAssessment      `json:"risk,omitempty"`
+	Rejection              enum.RejectionReason `json:"rejection,omitempty"`
+	RequestID              string               `json:"requestId,omitempty"`
 }
 
 type OperationFilterRequest struct {
 	WalletID uuid.UUID `form:"walletId" binding:"required"`
 	From     string    `form:"from" binding:"required"`
 	To       string    `form:"to" binding:"required"`
+	TZ       string    `form:"tz"`
 }
 
 type OperationDailySummaryResponse struct {
 	WalletID          uuid.UUID       `json:"walletId"`
 	DateBalanceWallet string          `json:"dateBalanceWallet"`
+	TimeZone          string          `json:"timeZone"`
 	WalletBalanceDay  int64           `json:"walletBalanceDay"`
 	Operations        []OperationItem `json:"operations,omitempty"`
 }
@@ -54,3 +118,54 @@ type OperationItem struct {
 	AmountInCents int64                `json:"amountInCents"`
 	CreatedAt     time.Time            `json:"createdAt"`
 }
+
+type ReportGranularity string
+
+const (
+	ReportGranularityDay   ReportGranularity = "day"
+	ReportGranularityWeek  ReportGranularity = "week"
+	ReportGranularityMonth ReportGranularity = "month"
+)
+
+type ReportRequest struct {
+	WalletIDs   []uuid.UUID
+	From        string
+	To          string
+	Granularity ReportGranularity
+	TZ          string
+}
+
+// reportRow is one group produced by the report aggregation pipeline
+type reportRow struct {
+	Key struct {
+		Bucket time.Time            `bson:"bucket"`
+		Type   enum.OperationType   `bson:"type"`
+		Status enum.OperationStatus `bson:"status"`
+	} `bson:"_id"`
+	Count         int64 `bson:"count"`
+	AmountInCents int64 `bson:"amountInCents"`
+}
+
+type OperationReportResponse struct {
+	WalletIDs   []uuid.UUID           `json:"walletIds"`
+	From        string                `json:"from"`
+	To          string                `json:"to"`
+	Granularity ReportGranularity     `json:"granularity"`
+	TimeZone    string                `json:"timeZone"`
+	Buckets     []OperationReportItem `json:"buckets"`
+}
+
+type OperationReportItem struct {
+	Start            string                     `json:"start"`
+	OperationCount   int64                      `json:"operationCount"`
+	ErrorCount       int64                      `json:"errorCount"`
+	NetAmountInCents int64                      `json:"netAmountInCents"`
+	Breakdown        []OperationReportBreakdown `json:"breakdown"`
+}
+
+type OperationReportBreakdown struct {
+	Type          enum.OperationType   `json:"type"`
+	Status        enum.OperationStatus `json:"status"`
+	Count         int64                `json:"count"`
+	AmountInCents int64                `json:"amountInCents"`
+}
@@ -0,0 +1,144 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"wallet-go/internal/shared/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetTreasury godoc
+// @Summary Get treasury report
+// @Description Platform-wide liabilities, daily deposit/withdrawal totals and flows between wallets
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD)"
+// @Param tz query string false "IANA time zone"
+// @Success 200 {object} TreasuryReport
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/reports/treasury [get]
+func (h *Handler) GetTreasury(c *gin.Context) {
+	report, ok := h.loadTreasury(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportTreasury godoc
+// @Summary Export treasury report as CSV
+// @Description Export the daily totals or the wallet flows of the treasury report as CSV
+// @Tags Admin
+// @Produce text/csv
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD)"
+// @Param tz query string false "IANA time zone"
+// @Param section query string false "daily or flows" default(daily)
+// @Success 200 {string} string
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/reports/treasury/export [get]
+func (h *Handler) ExportTreasury(c *gin.Context) {
+	section := c.DefaultQuery("section", "daily")
+	if section != "daily" && section != "flows" {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid section. Use daily or flows"))
+		return
+	}
+
+	report, ok := h.loadTreasury(c)
+	if !ok {
+		return
+	}
+
+	var records [][]string
+	if section == "daily" {
+		records = dailyRecords(report)
+	} else {
+		records = flowRecords(report)
+	}
+
+	filename := fmt.Sprintf("treasury-%s-%s_%s.csv", section, report.From, report.To)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.WriteAll(records); err != nil {
+		c.Error(err)
+	}
+}
+
+func (h *Handler) loadTreasury(c *gin.Context) (*TreasuryReport, bool) {
+	var request TreasuryReportRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("from and to are required"))
+		return nil, false
+	}
+
+	report, err := h.service.GetTreasuryReport(c.Request.Context(), request)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, appErr)
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, errors.InternalServerError("Failed to build treasury report"))
+		return nil, false
+	}
+
+	return report, true
+}
+
+func dailyRecords(report *TreasuryReport) [][]string {
+	records := [][]string{{
+		"date", "deposit_count", "deposits_in_cents", "withdrawal_count", "withdrawals_in_cents",
+		"transfer_count", "transfers_in_cents", "internal_transfer_net_in_cents",
+	}}
+
+	for _, day := range report.Daily {
+		records = append(records, []string{
+			day.Date,
+			strconv.FormatInt(day.DepositCount, 10),
+			strconv.FormatInt(day.DepositsInCents, 10),
+			strconv.FormatInt(day.WithdrawalCount, 10),
+			strconv.FormatInt(day.WithdrawalsInCents, 10),
+			strconv.FormatInt(day.TransferCount, 10),
+			strconv.FormatInt(day.TransfersInCents, 10),
+			strconv.FormatInt(day.InternalTransferNetInCents, 10),
+		})
+	}
+
+	return records
+}
+
+func flowRecords(report *TreasuryReport) [][]string {
+	records := [][]string{{"source_wallet_id", "destination_wallet_id", "count", "amount_in_cents"}}
+
+	for _, flow := range report.Flows {
+		records = append(records, []string{
+			flow.SourceWalletID.String(),
+			flow.DestinationWalletID.String(),
+			strconv.FormatInt(flow.Count, 10),
+			strconv.FormatInt(flow.AmountInCents, 10),
+		})
+	}
+
+	return records
+}
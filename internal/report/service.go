@@ -0,0 +1,138 @@
+package report
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/errors"
+)
+
+type Service struct {
+	store           *Store
+	defaultLocation *time.Location
+}
+
+func NewService(store *Store, defaultLocation *time.Location) *Service {
+	if defaultLocation == nil {
+		defaultLocation = time.UTC
+	}
+
+	return &Service{
+		store:           store,
+		defaultLocation: defaultLocation,
+	}
+}
+
+func (s *Service) GetTreasuryReport(ctx context.Context, request TreasuryReportRequest) (*TreasuryReport, error) {
+	location := s.defaultLocation
+	if request.TZ != "" {
+		loaded, err := time.LoadLocation(request.TZ)
+		if err != nil {
+			return nil, errors.BadRequest("Invalid time zone. Use an IANA name such as America/Sao_Paulo")
+		}
+		location = loaded
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", request.From, location)
+	if err != nil {
+		return nil, errors.BadRequest("Invalid from date format")
+	}
+
+	to, err := time.ParseInLocation("2006-01-02", request.To, location)
+	if err != nil {
+		return nil, errors.BadRequest("Invalid to date format")
+	}
+
+	if to.Before(from) {
+		return nil, errors.BadRequest("The from date must not be after the to date")
+	}
+
+	// Include the full "to" day
+	to = to.AddDate(0, 0, 1)
+
+	balances, err := s.store.SumBalances(ctx)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to sum wallet balances")
+	}
+
+	dailyRows, err := s.store.DailyTotals(ctx, from, to, location)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to aggregate daily totals")
+	}
+
+	flowRows, err := s.store.TransferFlows(ctx, from, to)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to aggregate transfer flows")
+	}
+
+	report := &TreasuryReport{
+		GeneratedAt:             time.Now(),
+		From:                    request.From,
+		To:                      request.To,
+		TimeZone:                location.String(),
+		WalletCount:             balances.WalletCount,
+		TotalLiabilitiesInCents: balances.AmountInCents,
+		Daily:                   s.mapDailyRows(dailyRows),
+		Flows:                   s.mapFlowRows(flowRows),
+	}
+
+	for _, day := range report.Daily {
+		report.InternalTransferNetInCents += day.InternalTransferNetInCents
+	}
+	report.InternalTransfersBalanced = report.InternalTransferNetInCents == 0
+
+	if !report.InternalTransfersBalanced {
+		slog.WarnContext(ctx, "Treasury report: internal transfers do not net to zero",
+			"from", request.From, "to", request.To, "net_in_cents", report.InternalTransferNetInCents)
+	}
+
+	return report, nil
+}
+
+// mapDailyRows folds the per-type rows into one item per day. Debits are
+// stored as negative amounts and reported here as positive totals.
+func (s *Service) mapDailyRows(rows []dailyRow) []TreasuryDailyItem {
+	items := []TreasuryDailyItem{}
+
+	for _, row := range rows {
+		if len(items) == 0 || items[len(items)-1].Date != row.Key.Day {
+			items = append(items, TreasuryDailyItem{Date: row.Key.Day})
+		}
+
+		item := &items[len(items)-1]
+		switch row.Key.Type {
+		case enum.OperationTypeDeposit:
+			item.DepositCount += row.Count
+			item.DepositsInCents += row.AmountInCents
+		case enum.OperationTypeWithdraw:
+			item.WithdrawalCount += row.Count
+			item.WithdrawalsInCents += -row.AmountInCents
+		case enum.OperationTypeTransfer:
+			item.TransferCount += row.Count
+			item.TransfersInCents += -row.AmountInCents
+			item.InternalTransferNetInCents += row.AmountInCents
+		case enum.OperationTypeReceiveTransfer:
+			item.InternalTransferNetInCents += row.AmountInCents
+		case enum.OperationTypeAdjustment:
+			item.AdjustmentCount += row.Count
+			item.AdjustmentsNetInCents += row.AmountInCents
+		}
+	}
+
+	return items
+}
+
+func (s *Service) mapFlowRows(rows []flowRow) []WalletFlow {
+	flows := make([]WalletFlow, len(rows))
+	for i, row := range rows {
+		flows[i] = WalletFlow{
+			SourceWalletID:      row.Key.Source,
+			DestinationWalletID: row.Key.Destination,
+			Count:               row.Count,
+			AmountInCents:       row.AmountInCents,
+		}
+	}
+	return flows
+}
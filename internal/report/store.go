@@ -0,0 +1,139 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Store runs read-only aggregations across the wallet and operation collections
+type Store struct {
+	walletCollection    *mongo.Collection
+	operationCollection *mongo.Collection
+}
+
+func NewStore(db *database.MongoClient) *Store {
+	return &Store{
+		walletCollection:    db.GetCollection("wallet"),
+		operationCollection: db.GetCollection("operation"),
+	}
+}
+
+// SumBalances returns the number of wallets and the sum of their balances
+func (s *Store) SumBalances(ctx context.Context) (*balanceRow, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":           nil,
+			"walletCount":   bson.M{"$sum": 1},
+			"amountInCents": bson.M{"$sum": "$currentAmountInCents"},
+		}}},
+	}
+
+	cursor, err := s.walletCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []balanceRow
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return &balanceRow{}, nil
+	}
+
+	return &rows[0], nil
+}
+
+// DailyTotals groups successful money-moving operations by local day and type
+func (s *Store) DailyTotals(ctx context.Context, from, to time.Time, location *time.Location) ([]dailyRow, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"status": enum.OperationStatusSuccess,
+			"type": bson.M{"$in": []enum.OperationType{
+				enum.OperationTypeDeposit,
+				enum.OperationTypeWithdraw,
+				enum.OperationTypeTransfer,
+				enum.OperationTypeReceiveTransfer,
+				enum.OperationTypeAdjustment,
+			}},
+			"createdAt": bson.M{
+				"$gte": from,
+				"$lt":  to,
+			},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"day": bson.M{"$dateToString": bson.M{
+					"format":   "%Y-%m-%d",
+					"date":     "$createdAt",
+					"timezone": location.String(),
+				}},
+				"type": "$type",
+			},
+			"count":         bson.M{"$sum": 1},
+			"amountInCents": bson.M{"$sum": "$amountInCents"},
+		}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "_id.day", Value: 1},
+			{Key: "_id.type", Value: 1},
+		}}},
+	}
+
+	cursor, err := s.operationCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []dailyRow
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// TransferFlows groups successful outgoing transfers by source and destination
+// wallet. The debit side is stored as a negative amount, so it is negated here.
+func (s *Store) TransferFlows(ctx context.Context, from, to time.Time) ([]flowRow, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"status": enum.OperationStatusSuccess,
+			"type":   enum.OperationTypeTransfer,
+			"createdAt": bson.M{
+				"$gte": from,
+				"$lt":  to,
+			},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"source":      "$walletId",
+				"destination": "$walletTransactionId",
+			},
+			"count":         bson.M{"$sum": 1},
+			"amountInCents": bson.M{"$sum": bson.M{"$multiply": bson.A{"$amountInCents", -1}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "amountInCents", Value: -1}}}},
+	}
+
+	cursor, err := s.operationCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []flowRow
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"time"
+
+	"wallet-go/internal/operation/enum"
+
+	"github.com/google/uuid"
+)
+
+type TreasuryReportRequest struct {
+	From string `form:"from" binding:"required"`
+	To   string `form:"to" binding:"required"`
+	TZ   string `form:"tz"`
+}
+
+type TreasuryReport struct {
+	GeneratedAt                time.Time           `json:"generatedAt"`
+	From                       string              `json:"from"`
+	To                         string              `json:"to"`
+	TimeZone                   string              `json:"timeZone"`
+	WalletCount                int64               `json:"walletCount"`
+	TotalLiabilitiesInCents    int64               `json:"totalLiabilitiesInCents"`
+	InternalTransferNetInCents int64               `json:"internalTransferNetInCents"`
+	InternalTransfersBalanced  bool                `json:"internalTransfersBalanced"`
+	Daily                      []TreasuryDailyItem `json:"daily"`
+	Flows                      []WalletFlow        `json:"flows"`
+}
+
+type TreasuryDailyItem struct {
+	Date                       string `json:"date"`
+	DepositCount               int64  `json:"depositCount"`
+	DepositsInCents            int64  `json:"depositsInCents"`
+	WithdrawalCount            int64  `json:"withdrawalCount"`
+	WithdrawalsInCents         int64  `json:"withdrawalsInCents"`
+	TransferCount              int64  `json:"transferCount"`
+	TransfersInCents           int64  `json:"transfersInCents"`
+	InternalTransferNetInCents int64  `json:"internalTransferNetInCents"`
+	// Adjustments are manual corrections; the net amount keeps their sign
+	AdjustmentCount       int64 `json:"adjustmentCount"`
+	AdjustmentsNetInCents int64 `json:"adjustmentsNetInCents"`
+}
+
+type WalletFlow struct {
+	SourceWalletID      uuid.UUID `json:"sourceWalletId"`
+	DestinationWalletID uuid.UUID `json:"destinationWalletId"`
+	Count               int64     `json:"count"`
+	AmountInCents       int64     `json:"amountInCents"`
+}
+
+type balanceRow struct {
+	WalletCount   int64 `bson:"walletCount"`
+	AmountInCents int64 `bson:"amountInCents"`
+}
+
+type dailyRow struct {
+	Key struct {
+		Day  string             `bson:"day"`
+		Type enum.OperationType `bson:"type"`
+	} `bson:"_id"`
+	Count         int64 `bson:"count"`
+	AmountInCents int64 `bson:"amountInCents"`
+}
+
+type flowRow struct {
+	Key struct {
+		Source      uuid.UUID `bson:"source"`
+		Destination uuid.UUID `bson:"destination"`
+	} `bson:"_id"`
+	Count         int64 `bson:"count"`
+	AmountInCents int64 `bson:"amountInCents"`
+}
@@ -0,0 +1,150 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/operation/enum"
+)
+
+// History answers questions about past operations; implemented by the
+// operation repositories
+type History interface {
+	CountActivity(ctx context.Context, filter operation.ActivityFilter) (int64, error)
+	FindLatestActivity(ctx context.Context, filter operation.ActivityFilter) (*operation.Operation, error)
+}
+
+// Check evaluates one rule kind. It returns whether the rule matched and a
+// detail for the decision recorded on the operation.
+type Check func(ctx context.Context, history History, rule *Rule, tx Transaction) (bool, string, error)
+
+// Validate reports a configuration error of a rule of its kind
+type Validate func(rule *Rule) error
+
+type kindDefinition struct {
+	check    Check
+	validate Validate
+}
+
+var kinds = map[Kind]kindDefinition{
+	KindVelocity:        {check: checkVelocity, validate: validateVelocity},
+	KindNewCounterparty: {check: checkNewCounterparty, validate: validateTransferOnly},
+	KindRoundTrip:       {check: checkRoundTrip, validate: validateRoundTrip},
+	KindDormancy:        {check: checkDormancy, validate: validateDormancy},
+}
+
+// RegisterKind adds a rule kind. It must be called before the service
+// evaluates rules, typically from an init function.
+func RegisterKind(kind Kind, check Check, validate Validate) {
+	kinds[kind] = kindDefinition{check: check, validate: validate}
+}
+
+var debitTypes = []enum.OperationType{enum.OperationTypeWithdraw, enum.OperationTypeTransfer}
+
+func checkVelocity(ctx context.Context, history History, rule *Rule, tx Transaction) (bool, string, error) {
+	since := time.Now().Add(-rule.window())
+	types := rule.OperationTypes
+	if len(types) == 0 {
+		types = debitTypes
+	}
+
+	count, err := history.CountActivity(ctx, operation.ActivityFilter{
+		WalletID: tx.WalletID,
+		Types:    types,
+		Since:    &since,
+	})
+	if err != nil {
+		return false, "", err
+	}
+
+	if count < rule.MaxCount {
+		return false, "", nil
+	}
+	return true, fmt.Sprintf("%d debits in the last %d minutes", count, rule.WindowMinutes), nil
+}
+
+func checkNewCounterparty(ctx context.Context, history History, rule *Rule, tx Transaction) (bool, string, error) {
+	if tx.CounterpartyID == nil || tx.AmountInCents <= rule.MaxAmountInCents {
+		return false, "", nil
+	}
+
+	count, err := history.CountActivity(ctx, operation.ActivityFilter{
+		WalletID:       tx.WalletID,
+		Types:          []enum.OperationType{enum.OperationTypeTransfer},
+		CounterpartyID: tx.CounterpartyID,
+	})
+	if err != nil || count > 0 {
+		return false, "", err
+	}
+
+	return true, fmt.Sprintf("first transfer to %s above %d cents", tx.CounterpartyID, rule.MaxAmountInCents), nil
+}
+
+func checkRoundTrip(ctx context.Context, history History, rule *Rule, tx Transaction) (bool, string, error) {
+	if tx.CounterpartyID == nil {
+		return false, "", nil
+	}
+
+	since := time.Now().Add(-rule.window())
+	received, err := history.FindLatestActivity(ctx, operation.ActivityFilter{
+		WalletID:       tx.WalletID,
+		Types:          []enum.OperationType{enum.OperationTypeReceiveTransfer},
+		CounterpartyID: tx.CounterpartyID,
+		Since:          &since,
+	})
+	if err != nil || received == nil {
+		return false, "", err
+	}
+
+	return true, fmt.Sprintf("received %d cents from %s at %s", received.AmountInCents, tx.CounterpartyID, received.CreatedAt.UTC().Format(time.RFC3339)), nil
+}
+
+func checkDormancy(ctx context.Context, history History, rule *Rule, tx Transaction) (bool, string, error) {
+	if tx.AmountInCents <= rule.MaxAmountInCents {
+		return false, "", nil
+	}
+
+	latest, err := history.FindLatestActivity(ctx, operation.ActivityFilter{WalletID: tx.WalletID})
+	if err != nil || latest == nil {
+		return false, "", err
+	}
+
+	dormantFor := time.Duration(rule.DormantDays) * 24 * time.Hour
+	if time.Since(latest.CreatedAt) < dormantFor {
+		return false, "", nil
+	}
+
+	return true, fmt.Sprintf("no activity since %s", latest.CreatedAt.UTC().Format(time.RFC3339)), nil
+}
+
+func validateVelocity(rule *Rule) error {
+	if rule.MaxCount <= 0 || rule.WindowMinutes <= 0 {
+		return fmt.Errorf("velocity rules need maxCount and windowMinutes")
+	}
+	return nil
+}
+
+func validateTransferOnly(rule *Rule) error {
+	for _, t := range rule.OperationTypes {
+		if t != enum.OperationTypeTransfer {
+			return fmt.Errorf("%s rules only apply to transfers", rule.Kind)
+		}
+	}
+	return nil
+}
+
+func validateRoundTrip(rule *Rule) error {
+	if rule.WindowMinutes <= 0 {
+		return fmt.Errorf("round_trip rules need windowMinutes")
+	}
+	return validateTransferOnly(rule)
+}
+
+func validateDormancy(rule *Rule) error {
+	if rule.DormantDays <= 0 {
+		return fmt.Errorf("dormancy rules need dormantDays")
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package risk
+
+import (
+	"net/http"
+
+	"wallet-go/internal/shared/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Create godoc
+// @Summary Create risk rule
+// @Description Add a rule evaluated before withdrawals and transfers. Kinds: velocity (maxCount, windowMinutes), new_counterparty (maxAmountInCents), round_trip (windowMinutes), dormancy (dormantDays, maxAmountInCents).
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body RuleRequest true "Rule"
+// @Success 201 {object} Rule
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/risk/rules [post]
+func (h *Handler) Create(c *gin.Context) {
+	var request RuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	rule, err := h.service.Create(c.Request.Context(), request)
+	if err != nil {
+		h.handleError(c, err, "Failed to create risk rule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// List godoc
+// @Summary List risk rules
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} Rule
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /admin/risk/rules [get]
+func (h *Handler) List(c *gin.Context) {
+	rules, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to list risk rules")
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetByID godoc
+// @Summary Get risk rule
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} Rule
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /admin/risk/rules/{id} [get]
+func (h *Handler) GetByID(c *gin.Context) {
+	ruleID, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.service.GetByID(c.Request.Context(), ruleID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get risk rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// Update godoc
+// @Summary Update risk rule
+// @Description Replace the configuration of a rule
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body RuleRequest true "Rule"
+// @Success 200 {object} Rule
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /admin/risk/rules/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	ruleID, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var request RuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	rule, err := h.service.Update(c.Request.Context(), ruleID, request)
+	if err != nil {
+		h.handleError(c, err, "Failed to update risk rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// Delete godoc
+// @Summary Delete risk rule
+// @Tags Admin
+// @Param id path string true "Rule ID"
+// @Success 204
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /admin/risk/rules/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	ruleID, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), ruleID); err != nil {
+		h.handleError(c, err, "Failed to delete risk rule")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid rule ID"))
+		return uuid.Nil, false
+	}
+	return ruleID, true
+}
+
+func (h *Handler) handleError(c *gin.Context, err error, message string) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.Code, appErr)
+		return
+	}
+	c.JSON(http.StatusInternalServerError, errors.InternalServerError(message))
+}
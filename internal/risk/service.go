@@ -0,0 +1,198 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/errors"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	store   *Store
+	history History
+}
+
+func NewService(store *Store, history History) *Service {
+	return &Service{
+		store:   store,
+		history: history,
+	}
+}
+
+// Evaluate runs the enabled rules that apply to the debit. The assessment
+// carries the most severe action of the matching rules; with no match the
+// action is allow.
+func (s *Service) Evaluate(ctx context.Context, tx Transaction) (*operation.RiskAssessment, error) {
+	rules, err := s.store.FindEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.evaluate(ctx, rules, tx)
+}
+
+// evaluate runs the given rules against the debit
+func (s *Service) evaluate(ctx context.Context, rules []*Rule, tx Transaction) (*operation.RiskAssessment, error) {
+	assessment := &operation.RiskAssessment{Action: operation.RiskActionAllow}
+	for _, rule := range rules {
+		if !rule.AppliesTo(tx.Type) {
+			continue
+		}
+
+		definition, ok := kinds[rule.Kind]
+		if !ok {
+			continue
+		}
+
+		hit, detail, err := definition.check(ctx, s.history, rule, tx)
+		if err != nil {
+			return nil, fmt.Errorf("risk rule %s: %w", rule.RuleID, err)
+		}
+		if !hit {
+			continue
+		}
+
+		assessment.Hits = append(assessment.Hits, operation.RiskHit{
+			RuleID: rule.RuleID,
+			Name:   rule.Name,
+			Kind:   string(rule.Kind),
+			Action: rule.Action,
+			Detail: detail,
+		})
+		if severity(rule.Action) > severity(assessment.Action) {
+			assessment.Action = rule.Action
+		}
+	}
+
+	return assessment, nil
+}
+
+func (s *Service) Create(ctx context.Context, request RuleRequest) (*Rule, error) {
+	now := time.Now()
+	actor := auth.ActorFromContext(ctx)
+
+	rule := &Rule{
+		RuleID:    uuid.New(),
+		CreatedAt: now,
+		CreatedBy: actor,
+	}
+	if err := s.apply(rule, request, actor, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Create(ctx, rule); err != nil {
+		return nil, errors.InternalServerError("Failed to create risk rule")
+	}
+
+	return rule, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]*Rule, error) {
+	rules, err := s.store.FindAll(ctx)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to list risk rules")
+	}
+
+	return rules, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, ruleID uuid.UUID) (*Rule, error) {
+	rule, err := s.store.FindByID(ctx, ruleID)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to get risk rule")
+	}
+
+	if rule == nil {
+		return nil, errors.RiskRuleNotFound()
+	}
+
+	return rule, nil
+}
+
+// Update replaces the configuration of a rule
+func (s *Service) Update(ctx context.Context, ruleID uuid.UUID, request RuleRequest) (*Rule, error) {
+	rule, err := s.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(rule, request, auth.ActorFromContext(ctx), time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Update(ctx, rule); err != nil {
+		return nil, errors.InternalServerError("Failed to update risk rule")
+	}
+
+	return rule, nil
+}
+
+func (s *Service) Delete(ctx context.Context, ruleID uuid.UUID) error {
+	deleted, err := s.store.Delete(ctx, ruleID)
+	if err != nil {
+		return errors.InternalServerError("Failed to delete risk rule")
+	}
+
+	if !deleted {
+		return errors.RiskRuleNotFound()
+	}
+
+	return nil
+}
+
+// apply validates the request and copies it onto the rule
+func (s *Service) apply(rule *Rule, request RuleRequest, actor *auth.Actor, now time.Time) error {
+	switch request.Action {
+	case operation.RiskActionAllow, operation.RiskActionReview, operation.RiskActionDeny:
+	default:
+		return errors.BadRequest("Action must be allow, review or deny")
+	}
+
+	for _, t := range request.OperationTypes {
+		if t != enum.OperationTypeWithdraw && t != enum.OperationTypeTransfer {
+			return errors.BadRequest("Operation types must be WITHDRAW or TRANSFER")
+		}
+	}
+
+	definition, ok := kinds[request.Kind]
+	if !ok {
+		return errors.BadRequest(fmt.Sprintf("Unknown rule kind %q", request.Kind))
+	}
+
+	rule.Name = request.Name
+	rule.Kind = request.Kind
+	rule.Action = request.Action
+	rule.Enabled = request.Enabled == nil || *request.Enabled
+	rule.OperationTypes = request.OperationTypes
+	rule.MaxCount = request.MaxCount
+	rule.WindowMinutes = request.WindowMinutes
+	rule.MaxAmountInCents = request.MaxAmountInCents
+	rule.DormantDays = request.DormantDays
+	rule.UpdatedAt = now
+	rule.UpdatedBy = actor
+
+	if definition.validate != nil {
+		if err := definition.validate(rule); err != nil {
+			return errors.BadRequest(err.Error())
+		}
+	}
+
+	return nil
+}
+
+func severity(action operation.RiskAction) int {
+	switch action {
+	case operation.RiskActionDeny:
+		return 2
+	case operation.RiskActionReview:
+		return 1
+	default:
+		return 0
+	}
+}
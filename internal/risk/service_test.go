@@ -0,0 +1,180 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/operation/enum"
+
+	"github.com/google/uuid"
+)
+
+// fakeHistory answers every filter with the same count and latest operation
+type fakeHistory struct {
+	count  int64
+	latest *operation.Operation
+	err    error
+}
+
+func (h fakeHistory) CountActivity(ctx context.Context, filter operation.ActivityFilter) (int64, error) {
+	return h.count, h.err
+}
+
+func (h fakeHistory) FindLatestActivity(ctx context.Context, filter operation.ActivityFilter) (*operation.Operation, error) {
+	return h.latest, h.err
+}
+
+func rule(name string, kind Kind, action operation.RiskAction, configure func(*Rule)) *Rule {
+	r := &Rule{RuleID: uuid.New(), Name: name, Kind: kind, Action: action, Enabled: true}
+	if configure != nil {
+		configure(r)
+	}
+	return r
+}
+
+func velocity(action operation.RiskAction, maxCount int64) *Rule {
+	return rule(fmt.Sprintf("velocity %s", action), KindVelocity, action, func(r *Rule) {
+		r.MaxCount = maxCount
+		r.WindowMinutes = 60
+	})
+}
+
+func TestEvaluate(t *testing.T) {
+	counterparty := uuid.New()
+	withdraw := Transaction{Type: enum.OperationTypeWithdraw, WalletID: uuid.New(), AmountInCents: 50_000}
+	transfer := Transaction{Type: enum.OperationTypeTransfer, WalletID: uuid.New(), CounterpartyID: &counterparty, AmountInCents: 50_000}
+	daysAgo := func(days int) *operation.Operation {
+		return &operation.Operation{AmountInCents: 100, CreatedAt: time.Now().AddDate(0, 0, -days)}
+	}
+
+	tests := []struct {
+		name    string
+		rules   []*Rule
+		history fakeHistory
+		tx      Transaction
+		action  operation.RiskAction
+		hits    []string
+	}{
+		{"no rules", nil, fakeHistory{}, withdraw, operation.RiskActionAllow, nil},
+		{"velocity below the threshold", []*Rule{velocity(operation.RiskActionDeny, 5)}, fakeHistory{count: 4}, withdraw, operation.RiskActionAllow, nil},
+		{"velocity at the threshold", []*Rule{velocity(operation.RiskActionReview, 5)}, fakeHistory{count: 5}, withdraw, operation.RiskActionReview, []string{"velocity review"}},
+		{
+			"deny wins over review", []*Rule{velocity(operation.RiskActionReview, 1), velocity(operation.RiskActionDeny, 1)},
+			fakeHistory{count: 3}, withdraw, operation.RiskActionDeny, []string{"velocity review", "velocity deny"},
+		},
+		{
+			"deny wins in any order", []*Rule{velocity(operation.RiskActionDeny, 1), velocity(operation.RiskActionReview, 1)},
+			fakeHistory{count: 3}, withdraw, operation.RiskActionDeny, []string{"velocity deny", "velocity review"},
+		},
+		{
+			"review wins over allow", []*Rule{velocity(operation.RiskActionAllow, 1), velocity(operation.RiskActionReview, 1)},
+			fakeHistory{count: 3}, withdraw, operation.RiskActionReview, []string{"velocity allow", "velocity review"},
+		},
+		{
+			"allow hits are recorded", []*Rule{velocity(operation.RiskActionAllow, 1)},
+			fakeHistory{count: 3}, withdraw, operation.RiskActionAllow, []string{"velocity allow"},
+		},
+		{
+			"rule of another operation type", []*Rule{rule("transfers only", KindVelocity, operation.RiskActionDeny, func(r *Rule) {
+				r.OperationTypes = []enum.OperationType{enum.OperationTypeTransfer}
+				r.MaxCount = 1
+				r.WindowMinutes = 60
+			})},
+			fakeHistory{count: 3}, withdraw, operation.RiskActionAllow, nil,
+		},
+		{"unknown kind", []*Rule{rule("unknown", Kind("unknown"), operation.RiskActionDeny, nil)}, fakeHistory{count: 3}, withdraw, operation.RiskActionAllow, nil},
+		{
+			"new counterparty above the amount", []*Rule{rule("new counterparty", KindNewCounterparty, operation.RiskActionReview, func(r *Rule) { r.MaxAmountInCents = 49_999 })},
+			fakeHistory{count: 0}, transfer, operation.RiskActionReview, []string{"new counterparty"},
+		},
+		{
+			"new counterparty at the amount", []*Rule{rule("new counterparty", KindNewCounterparty, operation.RiskActionReview, func(r *Rule) { r.MaxAmountInCents = 50_000 })},
+			fakeHistory{count: 0}, transfer, operation.RiskActionAllow, nil,
+		},
+		{
+			"known counterparty", []*Rule{rule("new counterparty", KindNewCounterparty, operation.RiskActionReview, func(r *Rule) { r.MaxAmountInCents = 1 })},
+			fakeHistory{count: 1}, transfer, operation.RiskActionAllow, nil,
+		},
+		{
+			"round trip", []*Rule{rule("round trip", KindRoundTrip, operation.RiskActionDeny, func(r *Rule) { r.WindowMinutes = 60 })},
+			fakeHistory{latest: daysAgo(0)}, transfer, operation.RiskActionDeny, []string{"round trip"},
+		},
+		{
+			"no round trip", []*Rule{rule("round trip", KindRoundTrip, operation.RiskActionDeny, func(r *Rule) { r.WindowMinutes = 60 })},
+			fakeHistory{}, transfer, operation.RiskActionAllow, nil,
+		},
+		{
+			"dormant wallet", []*Rule{rule("dormancy", KindDormancy, operation.RiskActionReview, func(r *Rule) { r.DormantDays = 90; r.MaxAmountInCents = 10_000 })},
+			fakeHistory{latest: daysAgo(100)}, withdraw, operation.RiskActionReview, []string{"dormancy"},
+		},
+		{
+			"active wallet", []*Rule{rule("dormancy", KindDormancy, operation.RiskActionReview, func(r *Rule) { r.DormantDays = 90; r.MaxAmountInCents = 10_000 })},
+			fakeHistory{latest: daysAgo(10)}, withdraw, operation.RiskActionAllow, nil,
+		},
+		{
+			"dormant wallet below the amount", []*Rule{rule("dormancy", KindDormancy, operation.RiskActionReview, func(r *Rule) { r.DormantDays = 90; r.MaxAmountInCents = 50_000 })},
+			fakeHistory{latest: daysAgo(100)}, withdraw, operation.RiskActionAllow, nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, tt.history)
+			assessment, err := service.evaluate(context.Background(), tt.rules, tt.tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if assessment.Action != tt.action {
+				t.Fatalf("action = %s, want %s", assessment.Action, tt.action)
+			}
+			if len(assessment.Hits) != len(tt.hits) {
+				t.Fatalf("hits = %+v, want %v", assessment.Hits, tt.hits)
+			}
+			for i, name := range tt.hits {
+				if assessment.Hits[i].Name != name || assessment.Hits[i].Detail == "" {
+					t.Fatalf("hit %d = %+v, want %s with a detail", i, assessment.Hits[i], name)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateHistoryError(t *testing.T) {
+	service := NewService(nil, fakeHistory{err: fmt.Errorf("history unavailable")})
+	tx := Transaction{Type: enum.OperationTypeWithdraw, WalletID: uuid.New(), AmountInCents: 100}
+
+	if _, err := service.evaluate(context.Background(), []*Rule{velocity(operation.RiskActionDeny, 1)}, tx); err == nil {
+		t.Fatal("evaluate ignored a history error")
+	}
+}
+
+func TestRuleValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		request RuleRequest
+		valid   bool
+	}{
+		{"velocity", RuleRequest{Kind: KindVelocity, Action: operation.RiskActionDeny, MaxCount: 3, WindowMinutes: 10}, true},
+		{"velocity without window", RuleRequest{Kind: KindVelocity, Action: operation.RiskActionDeny, MaxCount: 3}, false},
+		{"unknown action", RuleRequest{Kind: KindVelocity, Action: "block", MaxCount: 3, WindowMinutes: 10}, false},
+		{"unknown kind", RuleRequest{Kind: "unknown", Action: operation.RiskActionDeny}, false},
+		{"deposit type", RuleRequest{Kind: KindDormancy, Action: operation.RiskActionReview, DormantDays: 30, OperationTypes: []enum.OperationType{enum.OperationTypeDeposit}}, false},
+		{"new counterparty on withdrawals", RuleRequest{Kind: KindNewCounterparty, Action: operation.RiskActionReview, OperationTypes: []enum.OperationType{enum.OperationTypeWithdraw}}, false},
+		{"round trip without window", RuleRequest{Kind: KindRoundTrip, Action: operation.RiskActionDeny}, false},
+		{"dormancy without days", RuleRequest{Kind: KindDormancy, Action: operation.RiskActionReview}, false},
+	}
+
+	service := NewService(nil, fakeHistory{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.apply(&Rule{}, tt.request, nil, time.Now())
+			if tt.valid != (err == nil) {
+				t.Fatalf("apply = %v, valid = %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package risk
+
+import (
+	"context"
+
+	"wallet-go/internal/shared/database"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Store struct {
+	collection *mongo.Collection
+}
+
+func NewStore(db *database.MongoClient) *Store {
+	return &Store{
+		collection: db.GetCollection("risk_rule"),
+	}
+}
+
+func (s *Store) Create(ctx context.Context, rule *Rule) error {
+	_, err := s.collection.InsertOne(ctx, rule)
+	return err
+}
+
+func (s *Store) FindByID(ctx context.Context, ruleID uuid.UUID) (*Rule, error) {
+	var rule Rule
+
+	err := s.collection.FindOne(ctx, bson.M{"ruleId": ruleID}).Decode(&rule)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (s *Store) FindAll(ctx context.Context) ([]*Rule, error) {
+	return s.find(ctx, bson.M{})
+}
+
+func (s *Store) FindEnabled(ctx context.Context) ([]*Rule, error) {
+	return s.find(ctx, bson.M{"enabled": true})
+}
+
+func (s *Store) Update(ctx context.Context, rule *Rule) error {
+	_, err := s.collection.ReplaceOne(ctx, bson.M{"ruleId": rule.RuleID}, rule)
+	return err
+}
+
+func (s *Store) Delete(ctx context.Context, ruleID uuid.UUID) (bool, error) {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"ruleId": ruleID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (s *Store) find(ctx context.Context, filter bson.M) ([]*Rule, error) {
+	cursor, err := s.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rules := []*Rule{}
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,85 @@
+package risk
+
+import (
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/auth"
+
+	"github.com/google/uuid"
+)
+
+// Kind selects the check a rule runs; see checks.go
+type Kind string
+
+const (
+	// KindVelocity matches when the wallet already made MaxCount debits in the
+	// last WindowMinutes
+	KindVelocity Kind = "velocity"
+	// KindNewCounterparty matches transfers above MaxAmountInCents to a wallet
+	// that never received a transfer from the source
+	KindNewCounterparty Kind = "new_counterparty"
+	// KindRoundTrip matches transfers back to a wallet that sent money to the
+	// source in the last WindowMinutes
+	KindRoundTrip Kind = "round_trip"
+	// KindDormancy matches debits above MaxAmountInCents from a wallet without
+	// activity in the last DormantDays
+	KindDormancy Kind = "dormancy"
+)
+
+type Rule struct {
+	RuleID  uuid.UUID            `bson:"ruleId" json:"ruleId"`
+	Name    string               `bson:"name" json:"name"`
+	Kind    Kind                 `bson:"kind" json:"kind"`
+	Action  operation.RiskAction `bson:"action" json:"action"`
+	Enabled bool                 `bson:"enabled" json:"enabled"`
+	// OperationTypes limits the rule to WITHDRAW or TRANSFER; empty applies
+	// to both
+	OperationTypes   []enum.OperationType `bson:"operationTypes,omitempty" json:"operationTypes,omitempty"`
+	MaxCount         int64                `bson:"maxCount,omitempty" json:"maxCount,omitempty"`
+	WindowMinutes    int64                `bson:"windowMinutes,omitempty" json:"windowMinutes,omitempty"`
+	MaxAmountInCents int64                `bson:"maxAmountInCents,omitempty" json:"maxAmountInCents,omitempty"`
+	DormantDays      int64                `bson:"dormantDays,omitempty" json:"dormantDays,omitempty"`
+	CreatedAt        time.Time            `bson:"createdAt" json:"createdAt"`
+	UpdatedAt        time.Time            `bson:"updatedAt" json:"updatedAt"`
+	CreatedBy        *auth.Actor          `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	UpdatedBy        *auth.Actor          `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+}
+
+type RuleRequest struct {
+	Name             string               `json:"name" binding:"required"`
+	Kind             Kind                 `json:"kind" binding:"required"`
+	Action           operation.RiskAction `json:"action" binding:"required"`
+	Enabled          *bool                `json:"enabled,omitempty"`
+	OperationTypes   []enum.OperationType `json:"operationTypes,omitempty"`
+	MaxCount         int64                `json:"maxCount,omitempty"`
+	WindowMinutes    int64                `json:"windowMinutes,omitempty"`
+	MaxAmountInCents int64                `json:"maxAmountInCents,omitempty"`
+	DormantDays      int64                `json:"dormantDays,omitempty"`
+}
+
+// Transaction is the debit being assessed
+type Transaction struct {
+	Type           enum.OperationType
+	WalletID       uuid.UUID
+	CounterpartyID *uuid.UUID
+	AmountInCents  int64
+}
+
+// AppliesTo reports whether the rule runs for the operation type
+func (r *Rule) AppliesTo(opType enum.OperationType) bool {
+	if len(r.OperationTypes) == 0 {
+		return true
+	}
+	for _, t := range r.OperationTypes {
+		if t == opType {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) window() time.Duration {
+	return time.Duration(r.WindowMinutes) * time.Minute
+}
@@ -0,0 +1,231 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/database"
+	"wallet-go/internal/shared/middleware"
+	"wallet-go/internal/shared/ratelimit"
+	"wallet-go/internal/shared/signing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// guard builds the authentication, rate limiting and authorization
+// middlewares of each route. With AUTH_ENABLED=false every auth guard is a
+// no-op and requests are rate limited by client IP.
+type guard struct {
+	enabled      bool
+	authenticate []gin.HandlerFunc
+	authorizer   *auth.Authorizer
+	rateLimit    gin.HandlerFunc
+	signed       gin.HandlerFunc
+}
+
+func newGuard(cfg config.AuthConfig, resolver auth.WalletOwnerResolver, apiKeys auth.APIKeyResolver) (*guard, error) {
+	if !cfg.Enabled {
+		slog.Warn("Authentication is disabled (AUTH_ENABLED=false)")
+		return &guard{}, nil
+	}
+
+	keys, err := loadKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without JWT keys only API keys are accepted
+	var verifier *auth.JWTVerifier
+	if keys != nil {
+		verifier = auth.NewJWTVerifier(keys, cfg.Algorithms, cfg.Issuer, cfg.Audience, cfg.CustomerClaim)
+	}
+
+	return &guard{
+		enabled: true,
+		authenticate: []gin.HandlerFunc{
+			middleware.APIKeyAuth(apiKeys),
+			middleware.Authenticate(verifier),
+		},
+		authorizer: auth.NewAuthorizer(resolver),
+	}, nil
+}
+
+// loadKeySet returns nil when no JWT verification key is configured
+func loadKeySet(cfg config.AuthConfig) (*auth.KeySet, error) {
+	var keys *auth.KeySet
+	var err error
+
+	switch {
+	case cfg.JWKSFile != "":
+		keys, err = auth.NewKeySetFromFile(cfg.JWKSFile)
+	case cfg.JWKSURL != "":
+		keys, err = auth.NewKeySetFromURL(cfg.JWKSURL)
+	case cfg.HMACSecret != "":
+		return auth.NewStaticKeySet(map[string]interface{}{"": []byte(cfg.HMACSecret)}), nil
+	default:
+		slog.Warn("No AUTH_JWKS_FILE, AUTH_JWKS_URL or AUTH_JWT_HMAC_SECRET set, only API keys are accepted")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	go keys.StartRefresh(context.Background(), cfg.JWKSRefreshInterval)
+	return keys, nil
+}
+
+// indexTimeout bounds the creation of the indexes the Mongo backends need
+const indexTimeout = 10 * time.Second
+
+// newRateLimit runs after authentication so buckets can be keyed by the
+// principal
+func newRateLimit(cfg config.RateLimitConfig, mongoClient *database.MongoClient, reloader *config.Reloader) (gin.HandlerFunc, error) {
+	if !cfg.Enabled {
+		return noop, nil
+	}
+
+	policy, err := ratelimit.NewPolicy(cfg.Default, cfg.Routes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Limits follow the configuration file; a bad reload keeps the old ones
+	reloader.OnReload(func(next *config.Config) {
+		reloaded, err := ratelimit.NewPolicy(next.RateLimit.Default, next.RateLimit.Routes)
+		if err != nil {
+			slog.Error("Invalid rate limits in reloaded config, keeping the running ones", "error", err)
+			return
+		}
+		policy.Replace(reloaded)
+	})
+
+	var backend ratelimit.Backend
+	switch cfg.Backend {
+	case "memory":
+		backend = ratelimit.NewMemoryBackend()
+	case "mongo":
+		mongoBackend := ratelimit.NewMongoBackend(mongoClient)
+		ctx, cancel := context.WithTimeout(context.Background(), indexTimeout)
+		defer cancel()
+		if err := mongoBackend.EnsureIndexes(ctx); err != nil {
+			return nil, fmt.Errorf("creating rate limit indexes: %w", err)
+		}
+		backend = mongoBackend
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_BACKEND %q, expected memory or mongo", cfg.Backend)
+	}
+
+	return middleware.RateLimit(backend, policy), nil
+}
+
+// newSignature verifies request signatures of the money moving routes
+func newSignature(cfg config.RequestSigningConfig, mongoClient *database.MongoClient) (gin.HandlerFunc, error) {
+	var required bool
+	switch cfg.Mode {
+	case "off":
+		return noop, nil
+	case "optional":
+	case "required":
+		required = true
+	default:
+		return nil, fmt.Errorf("unknown REQUEST_SIGNING_MODE %q, expected off, optional or required", cfg.Mode)
+	}
+
+	keys, err := signing.ParseKeys(cfg.Keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("REQUEST_SIGNING_MODE=%s requires REQUEST_SIGNING_KEYS", cfg.Mode)
+	}
+
+	var nonces signing.NonceStore
+	switch cfg.NonceBackend {
+	case "memory":
+		nonces = signing.NewMemoryNonceStore()
+	case "mongo":
+		mongoNonces := signing.NewMongoNonceStore(mongoClient)
+		ctx, cancel := context.WithTimeout(context.Background(), indexTimeout)
+		defer cancel()
+		if err := mongoNonces.EnsureIndexes(ctx); err != nil {
+			return nil, fmt.Errorf("creating request nonce indexes: %w", err)
+		}
+		nonces = mongoNonces
+	default:
+		return nil, fmt.Errorf("unknown REQUEST_SIGNING_NONCE_BACKEND %q, expected memory or mongo", cfg.NonceBackend)
+	}
+
+	return middleware.RequireSignature(keys, nonces, cfg.Window, required), nil
+}
+
+// authenticated returns the authentication chain and the rate limiter followed
+// by the given handlers
+func (g *guard) authenticated(handlers ...gin.HandlerFunc) []gin.HandlerFunc {
+	chain := []gin.HandlerFunc{noop}
+	if g.enabled {
+		chain = append([]gin.HandlerFunc{}, g.authenticate...)
+	}
+	if g.rateLimit != nil {
+		chain = append(chain, g.rateLimit)
+	}
+	return append(chain, handlers...)
+}
+
+// signature verifies the request signature; it is a no-op with
+// REQUEST_SIGNING_MODE=off
+func (g *guard) signature() gin.HandlerFunc {
+	if g.signed == nil {
+		return noop
+	}
+	return g.signed
+}
+
+func (g *guard) scope(scope string) gin.HandlerFunc {
+	if !g.enabled {
+		return noop
+	}
+	return middleware.RequireScope(scope)
+}
+
+func (g *guard) permission(permission auth.Permission) gin.HandlerFunc {
+	if !g.enabled {
+		return noop
+	}
+	return middleware.RequirePermission(permission)
+}
+
+// wallet requires ownership of the extracted wallets; principals holding one
+// of the permissions may act on any wallet
+func (g *guard) wallet(scope string, extract middleware.WalletIDExtractor, permissions ...auth.Permission) gin.HandlerFunc {
+	if !g.enabled {
+		return noop
+	}
+	return middleware.RequireWalletAccess(g.authorizer, scope, extract, permissions...)
+}
+
+// operationWallet resolves the wallet of the :operationId path parameter
+func operationWallet(operationService *operation.Service) middleware.WalletIDExtractor {
+	return func(c *gin.Context) []uuid.UUID {
+		operationID, err := uuid.Parse(c.Param("operationId"))
+		if err != nil {
+			return nil
+		}
+
+		op, err := operationService.GetByID(c.Request.Context(), operationID)
+		if err != nil {
+			return nil
+		}
+
+		return []uuid.UUID{op.WalletID}
+	}
+}
+
+func noop(c *gin.Context) {
+	c.Next()
+}
@@ -1,93 +1,151 @@
 package router
 
 import (
+	"wallet-go/internal/apikey"
+	"wallet-go/internal/approval"
+	"wallet-go/internal/audit"
+	"wallet-go/internal/bootstrap"
+	"wallet-go/internal/events"
 	"wallet-go/internal/health"
+	"wallet-go/internal/integrity"
 	"wallet-go/internal/operation"
+	"wallet-go/internal/report"
+	"wallet-go/internal/risk"
+	"wallet-go/internal/shared/auth"
 	"wallet-go/internal/shared/config"
-	"wallet-go/internal/shared/database"
-	"wallet-go/internal/shared/kafka"
+	"wallet-go/internal/shared/metrics"
 	"wallet-go/internal/shared/middleware"
-	"wallet-go/internal/shared/utils"
 	"wallet-go/internal/wallet"
+	"wallet-go/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func Setup(mongoClient *database.MongoClient, kafkaProducer *kafka.Producer, cfg *config.Config) *gin.Engine {
-	// Set Gin mode
-	if cfg.Server.Env == "production" {
-		gin.SetMode(gin.ReleaseMode)
-	}
-
-	r := gin.New()
-
-	// Middlewares
-	r.Use(gin.Logger())
-	r.Use(middleware.Recovery())
-	r.Use(middleware.ErrorHandler())
-	r.Use(middleware.CORS())
-
-	// Initialize dependencies
-	lockManager := utils.NewWalletLockManager()
-
-	// Stores
-	walletStore := wallet.NewStore(mongoClient)
-	operationStore := operation.NewStore(mongoClient)
+// Setup builds the HTTP router of the API on the services of c. Readiness
+// includes the Kafka consumers only when they were set on c.HealthService.
+func Setup(c *bootstrap.Container) (*gin.Engine, error) {
+	cfg := c.Config
+	r := newEngine(cfg)
 
-	// Validators
-	walletValidator := wallet.NewValidator()
+	// Handlers
+	walletHandler := wallet.NewHandler(c.WalletService, c.OperationService, c.Producer, cfg.Kafka.Topics.Deposit, cfg.Kafka.Topics.Withdraw, cfg.Kafka.Topics.Transfer)
+	operationHandler := operation.NewHandler(c.OperationService)
+	reportHandler := report.NewHandler(c.ReportService)
+	eventHandler := events.NewHandler(c.EventService)
+	webhookHandler := webhook.NewHandler(c.WebhookService)
+	apiKeyHandler := apikey.NewHandler(c.APIKeyService)
+	integrityHandler := integrity.NewHandler(c.IntegrityService)
+	auditHandler := audit.NewHandler(c.AuditService)
+	approvalHandler := approval.NewHandler(c.ApprovalService)
+	riskHandler := risk.NewHandler(c.RiskService)
+	healthHandler := health.NewHandler(c.HealthService)
+
+	// Authentication and authorization
+	g, err := newGuard(cfg.Auth, c.WalletService, c.APIKeyService)
+	if err != nil {
+		return nil, err
+	}
 
-	// Services
-	walletService := wallet.NewService(walletStore, operationStore, walletValidator, lockManager)
-	operationService := operation.NewService(operationStore)
-	healthService := health.NewService(mongoClient, []string{cfg.Kafka.Brokers[0]}, cfg)
+	g.rateLimit, err = newRateLimit(cfg.RateLimit, c.Mongo, c.Reloader)
+	if err != nil {
+		return nil, err
+	}
 
-	// Handlers
-	walletHandler := wallet.NewHandler(walletService, operationService, kafkaProducer, cfg.Kafka.Topics.Deposit, cfg.Kafka.Topics.Withdraw, cfg.Kafka.Topics.Transfer)
-	operationHandler := operation.NewHandler(operationService)
-	healthHandler := health.NewHandler(healthService)
+	g.signed, err = newSignature(cfg.Signing, c.Mongo)
+	if err != nil {
+		return nil, err
+	}
 
 	// Swagger route (before another routes)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Prometheus scrape endpoint, unauthenticated like /health
+	if cfg.Metrics.Enabled {
+		r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
 	// Redirect root to swagger
-	r.GET("/", func(c *gin.Context) {
-		c.Redirect(302, "/swagger/index.html")
+	r.GET("/", func(ctx *gin.Context) {
+		ctx.Redirect(302, "/swagger/index.html")
 	})
 
 	// API Routes
-	setupWalletRoutes(r, walletHandler, operationHandler)
-	setupOperationRoutes(r, operationHandler)
+	setupWalletRoutes(r, g, walletHandler, operationHandler, eventHandler)
+	setupOperationRoutes(r, g, operationHandler, c.OperationService)
 	setupHealthRoutes(r, healthHandler)
+	setupAdminRoutes(r, g, reportHandler, apiKeyHandler, integrityHandler, auditHandler, approvalHandler, riskHandler)
+	setupWebhookRoutes(r, g, webhookHandler)
+
+	return r, nil
+}
+
+// SetupProbes builds the router of the worker process, which only serves the
+// health probes and the metrics
+func SetupProbes(c *bootstrap.Container) *gin.Engine {
+	r := newEngine(c.Config)
+
+	if c.Config.Metrics.Enabled {
+		r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+	setupHealthRoutes(r, health.NewHandler(c.HealthService))
 
 	return r
 }
 
-func setupWalletRoutes(r *gin.Engine, walletHandler *wallet.Handler, operationHandler *operation.Handler) {
-	walletGroup := r.Group("/wallet")
+func newEngine(cfg *config.Config) *gin.Engine {
+	// Set Gin mode
+	if cfg.Server.Env == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	r := gin.New()
+
+	// Middlewares
+	r.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+	r.Use(middleware.RequestInfo())
+	if cfg.Metrics.Enabled {
+		r.Use(middleware.Metrics())
+	}
+	r.Use(middleware.AccessLog())
+	r.Use(middleware.Recovery())
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.CORS())
+
+	return r
+}
+
+func setupWalletRoutes(r *gin.Engine, g *guard, walletHandler *wallet.Handler, operationHandler *operation.Handler, eventHandler *events.Handler) {
+	walletParam := middleware.WalletIDFromParam("id")
+	walletQuery := middleware.WalletIDsFromQuery("walletId")
+
+	walletGroup := r.Group("/wallet", g.authenticated()...)
 	{
-		walletGroup.POST("", walletHandler.Create)
-		walletGroup.GET("", walletHandler.List)
-		walletGroup.GET("/:id", walletHandler.GetByID)
-		walletGroup.PATCH("/:id", walletHandler.Patch)
-		walletGroup.POST("/:id/deposit", walletHandler.Deposit)
-		walletGroup.POST("/:id/withdraw", walletHandler.Withdraw)
-		walletGroup.POST("/:id/transfer", walletHandler.Transfer)
+		walletGroup.POST("", g.scope(auth.ScopeWalletWrite), walletHandler.Create)
+		walletGroup.GET("", g.permission(auth.PermissionWalletList), walletHandler.List)
+		walletGroup.GET("/:id", g.wallet(auth.ScopeWalletRead, walletParam, auth.PermissionWalletReadAny), walletHandler.GetByID)
+		// Status changes are checked per field by the handler
+		walletGroup.PATCH("/:id", g.wallet(auth.ScopeWalletWrite, walletParam, auth.PermissionWalletBlock, auth.PermissionWalletDeactivate, auth.PermissionWalletSettings), walletHandler.Patch)
+		walletGroup.POST("/:id/deposit", g.signature(), g.wallet(auth.ScopeWalletWrite, walletParam), walletHandler.Deposit)
+		walletGroup.POST("/:id/withdraw", g.signature(), g.wallet(auth.ScopeWalletWrite, walletParam), walletHandler.Withdraw)
+		walletGroup.POST("/:id/transfer", g.signature(), g.wallet(auth.ScopeWalletWrite, walletParam), walletHandler.Transfer)
+		walletGroup.GET("/:id/events", g.wallet(auth.ScopeWalletRead, walletParam, auth.PermissionWalletReadAny), eventHandler.Stream)
 
 		// Rotas de operation movidas para dentro do grupo wallet
-		walletGroup.GET("/daily-summary", operationHandler.GetDailySummary)
-		walletGroup.GET("/daily-summary-details", operationHandler.GetDailySummaryDetails)
+		walletGroup.GET("/daily-summary", g.wallet(auth.ScopeWalletRead, walletQuery, auth.PermissionWalletReadAny), operationHandler.GetDailySummary)
+		walletGroup.GET("/daily-summary-details", g.wallet(auth.ScopeWalletRead, walletQuery, auth.PermissionWalletReadAny), operationHandler.GetDailySummaryDetails)
+		walletGroup.GET("/report", g.wallet(auth.ScopeWalletRead, walletQuery, auth.PermissionReportsRead, auth.PermissionWalletReadAny), operationHandler.GetReport)
 
 	}
 }
 
-func setupOperationRoutes(r *gin.Engine, operationHandler *operation.Handler) {
-	operationGroup := r.Group("/operations")
+func setupOperationRoutes(r *gin.Engine, g *guard, operationHandler *operation.Handler, operationService *operation.Service) {
+	operationGroup := r.Group("/operations", g.authenticated()...)
 	{
-		operationGroup.GET("", operationHandler.List)
-		operationGroup.GET("/:operationId", operationHandler.GetByID)
+		operationGroup.GET("", g.wallet(auth.ScopeWalletRead, middleware.WalletIDsFromQuery("walletId"), auth.PermissionWalletReadAny), operationHandler.List)
+		operationGroup.GET("/:operationId", g.wallet(auth.ScopeWalletRead, operationWallet(operationService), auth.PermissionWalletReadAny), operationHandler.GetByID)
 	}
 }
 
@@ -96,5 +154,59 @@ func setupHealthRoutes(r *gin.Engine, healthHandler *health.Handler) {
 	{
 		healthGroup.GET("", healthHandler.Health)
 		healthGroup.GET("/details", healthHandler.HealthDetails)
+		healthGroup.GET("/live", healthHandler.Live)
+		healthGroup.GET("/ready", healthHandler.Ready)
+	}
+}
+
+func setupAdminRoutes(r *gin.Engine, g *guard, reportHandler *report.Handler, apiKeyHandler *apikey.Handler, integrityHandler *integrity.Handler, auditHandler *audit.Handler, approvalHandler *approval.Handler, riskHandler *risk.Handler) {
+	adminGroup := r.Group("/admin", g.authenticated()...)
+	{
+		reports := g.permission(auth.PermissionReportsRead)
+		adminGroup.GET("/reports/treasury", reports, reportHandler.GetTreasury)
+		adminGroup.GET("/reports/treasury/export", reports, reportHandler.ExportTreasury)
+
+		apiKeys := g.permission(auth.PermissionAPIKeysManage)
+		adminGroup.POST("/api-keys", apiKeys, apiKeyHandler.Create)
+		adminGroup.GET("/api-keys", apiKeys, apiKeyHandler.List)
+		adminGroup.GET("/api-keys/:id", apiKeys, apiKeyHandler.GetByID)
+		adminGroup.POST("/api-keys/:id/rotate", apiKeys, apiKeyHandler.Rotate)
+		adminGroup.POST("/api-keys/:id/revoke", apiKeys, apiKeyHandler.Revoke)
+
+		integrityCheck := g.permission(auth.PermissionIntegrityVerify)
+		adminGroup.GET("/integrity/verify", integrityCheck, integrityHandler.VerifyAll)
+		adminGroup.GET("/integrity/wallets/:id/verify", integrityCheck, integrityHandler.VerifyWallet)
+		adminGroup.POST("/integrity/checkpoints", integrityCheck, integrityHandler.CreateCheckpoint)
+		adminGroup.GET("/integrity/checkpoints", integrityCheck, integrityHandler.ListCheckpoints)
+		adminGroup.GET("/integrity/checkpoints/:id/verify", integrityCheck, integrityHandler.VerifyCheckpoint)
+
+		adminGroup.GET("/audit", g.permission(auth.PermissionAuditRead), auditHandler.List)
+
+		approvals := g.permission(auth.PermissionOperationApprove)
+		adminGroup.GET("/approvals", approvals, approvalHandler.List)
+		adminGroup.GET("/approvals/:id", approvals, approvalHandler.GetByID)
+		adminGroup.POST("/approvals/:id/approve", approvals, approvalHandler.Approve)
+		adminGroup.POST("/approvals/:id/reject", approvals, approvalHandler.Reject)
+
+		riskRules := g.permission(auth.PermissionRiskManage)
+		adminGroup.POST("/risk/rules", riskRules, riskHandler.Create)
+		adminGroup.GET("/risk/rules", riskRules, riskHandler.List)
+		adminGroup.GET("/risk/rules/:id", riskRules, riskHandler.GetByID)
+		adminGroup.PUT("/risk/rules/:id", riskRules, riskHandler.Update)
+		adminGroup.DELETE("/risk/rules/:id", riskRules, riskHandler.Delete)
+	}
+}
+
+func setupWebhookRoutes(r *gin.Engine, g *guard, webhookHandler *webhook.Handler) {
+	webhookGroup := r.Group("/webhooks", g.authenticated(g.permission(auth.PermissionWebhooksManage))...)
+	{
+		webhookGroup.POST("/subscriptions", webhookHandler.CreateSubscription)
+		webhookGroup.GET("/subscriptions", webhookHandler.ListSubscriptions)
+		webhookGroup.GET("/subscriptions/:id", webhookHandler.GetSubscription)
+		webhookGroup.PATCH("/subscriptions/:id", webhookHandler.PatchSubscription)
+		webhookGroup.DELETE("/subscriptions/:id", webhookHandler.DeleteSubscription)
+		webhookGroup.GET("/subscriptions/:id/deliveries", webhookHandler.ListDeliveries)
+		webhookGroup.GET("/deliveries/:deliveryId", webhookHandler.GetDelivery)
+		webhookGroup.POST("/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
 	}
 }
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+)
+
+// Actor identifies who caused a change. It is stored on operations and
+// wallets and travels in Kafka messages so asynchronous work keeps the
+// original caller.
+type Actor struct {
+	Subject string        `bson:"subject" json:"subject"`
+	Kind    PrincipalKind `bson:"kind" json:"kind"`
+	Roles   []Role        `bson:"roles,omitempty" json:"roles,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor stores an explicit actor, used when the work runs outside the
+// request that started it
+func WithActor(ctx context.Context, actor *Actor) context.Context {
+	if actor == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the explicit actor of ctx or the actor of its
+// principal, or nil when neither is set
+func ActorFromContext(ctx context.Context) *Actor {
+	if actor, ok := ctx.Value(actorKey{}).(*Actor); ok {
+		return actor
+	}
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return principal.Actor()
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+
+	"wallet-go/internal/shared/errors"
+
+	"github.com/google/uuid"
+)
+
+// WalletOwnerResolver returns the customer that owns a wallet, or an empty
+// string when the wallet does not exist
+type WalletOwnerResolver interface {
+	WalletOwner(ctx context.Context, walletID uuid.UUID) (string, error)
+}
+
+// APIKeyResolver maps a plaintext API key to the principal of the service that
+// owns it
+type APIKeyResolver interface {
+	ResolveAPIKey(ctx context.Context, key string) (*Principal, error)
+}
+
+// Authorizer decides whether a principal may act on wallets. Admins may act on
+// any wallet and services only need the scope; customers also need to own
+// every wallet. Staff permissions are checked by the middleware before it.
+type Authorizer struct {
+	resolver WalletOwnerResolver
+}
+
+func NewAuthorizer(resolver WalletOwnerResolver) *Authorizer {
+	return &Authorizer{
+		resolver: resolver,
+	}
+}
+
+func (a *Authorizer) AuthorizeWallets(ctx context.Context, principal *Principal, scope string, walletIDs ...uuid.UUID) error {
+	if principal == nil {
+		return errors.Unauthorized("Authentication required")
+	}
+
+	if principal.IsAdmin() {
+		return nil
+	}
+
+	if scope != "" && !principal.HasScope(scope) {
+		return errors.Forbidden("Missing scope " + scope)
+	}
+
+	if actsForAnyCustomer(principal) {
+		return nil
+	}
+
+	for _, walletID := range walletIDs {
+		owner, err := a.resolver.WalletOwner(ctx, walletID)
+		if err != nil {
+			return errors.InternalServerError("Failed to authorize wallet access")
+		}
+
+		// Unknown wallets are left to the handler, which answers 404
+		if owner != "" && owner != principal.CustomerID {
+			return errors.Forbidden("You do not have access to this wallet")
+		}
+	}
+
+	return nil
+}
+
+// AuthorizeCustomer checks that the principal may act for the customer, as
+// when opening a wallet, with the ownership rules of AuthorizeWallets.
+// Requests without a principal (authentication disabled) are allowed.
+func AuthorizeCustomer(principal *Principal, customerID string) error {
+	if principal == nil || actsForAnyCustomer(principal) {
+		return nil
+	}
+	if principal.CustomerID != customerID {
+		return errors.Forbidden("You can only act for your own customer ID")
+	}
+	return nil
+}
+
+// actsForAnyCustomer is true for the principals not bound to one customer:
+// admins and services
+func actsForAnyCustomer(principal *Principal) bool {
+	return principal.IsAdmin() || principal.Kind == PrincipalKindService
+}
+
+// CheckPermission is used by handlers whose required permission depends on
+// the request body. Requests without a principal (authentication disabled)
+// are allowed.
+func CheckPermission(ctx context.Context, permission Permission) error {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil || principal.Can(permission) {
+		return nil
+	}
+	return errors.Forbidden("Missing permission " + string(permission))
+}
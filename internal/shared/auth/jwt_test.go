@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"wallet-go/internal/shared/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var hmacSecret = []byte("test-secret")
+
+func TestJWTVerifier(t *testing.T) {
+	// The algorithms a deployment with only AUTH_JWT_HMAC_SECRET accepts
+	t.Setenv("AUTH_JWT_HMAC_SECRET", string(hmacSecret))
+	cfg, err := config.LoadFile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacVerifier := NewJWTVerifier(NewStaticKeySet(map[string]interface{}{"": hmacSecret}), cfg.Auth.Algorithms, "", "", "customer_id")
+
+	first, second := generateRSAKey(t), generateRSAKey(t)
+	jwksVerifier := NewJWTVerifier(jwksFile(t, map[string]*rsa.PrivateKey{"key-1": first, "key-2": second}), []string{"RS256", "ES256"}, "", "", "")
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{"sub": "user-1", "customer_id": "customer-1", "exp": time.Now().Add(time.Hour).Unix()}
+	}
+	expired := valid()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+
+	tests := []struct {
+		name     string
+		verifier *JWTVerifier
+		token    string
+		accepted bool
+	}{
+		{"HS256 with only the secret configured", hmacVerifier, sign(t, jwt.SigningMethodHS256, "", valid(), hmacSecret), true},
+		{"HS256 with another secret", hmacVerifier, sign(t, jwt.SigningMethodHS256, "", valid(), []byte("other")), false},
+		{"RS256 with only the secret configured", hmacVerifier, sign(t, jwt.SigningMethodRS256, "", valid(), first), false},
+		{"alg none", hmacVerifier, sign(t, jwt.SigningMethodNone, "", valid(), jwt.UnsafeAllowNoneSignatureType), false},
+		{"expired", hmacVerifier, sign(t, jwt.SigningMethodHS256, "", expired, hmacSecret), false},
+		{"without expiry", hmacVerifier, sign(t, jwt.SigningMethodHS256, "", jwt.MapClaims{"sub": "user-1"}, hmacSecret), false},
+		{"JWKS key by kid", jwksVerifier, sign(t, jwt.SigningMethodRS256, "key-2", valid(), second), true},
+		{"JWKS key of another kid", jwksVerifier, sign(t, jwt.SigningMethodRS256, "key-1", valid(), second), false},
+		{"JWKS unknown kid", jwksVerifier, sign(t, jwt.SigningMethodRS256, "key-3", valid(), first), false},
+		{"JWKS without kid and several keys", jwksVerifier, sign(t, jwt.SigningMethodRS256, "", valid(), first), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := tt.verifier.Verify(tt.token)
+			if tt.accepted != (err == nil) {
+				t.Fatalf("Verify = %v, %v; accepted = %v", principal, err, tt.accepted)
+			}
+			if tt.accepted && principal.Subject != "user-1" {
+				t.Fatalf("subject = %q, want user-1", principal.Subject)
+			}
+		})
+	}
+
+	principal, err := hmacVerifier.Verify(sign(t, jwt.SigningMethodHS256, "", valid(), hmacSecret))
+	if err != nil || principal.CustomerID != "customer-1" {
+		t.Fatalf("customer claim = %v, %v; want customer-1", principal, err)
+	}
+}
+
+func TestJWTVerifierRejectsHMACWithPublicKey(t *testing.T) {
+	// An RSA public key must never be used as an HMAC secret, even when the
+	// algorithms allow both families
+	key := generateRSAKey(t)
+	verifier := NewJWTVerifier(NewStaticKeySet(map[string]interface{}{"key-1": &key.PublicKey}), []string{"RS256", "HS256"}, "", "", "")
+
+	publicKey := key.PublicKey.N.Bytes()
+	token := sign(t, jwt.SigningMethodHS256, "key-1", jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()}, publicKey)
+	if _, err := verifier.Verify(token); err == nil {
+		t.Fatal("HS256 token verified with an RSA public key")
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.MapClaims, key interface{}) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func generateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// jwksFile writes the public keys as a JWKS file and loads it
+func jwksFile(t *testing.T, keys map[string]*rsa.PrivateKey) *KeySet {
+	t.Helper()
+
+	encode := func(value *big.Int) string { return base64.RawURLEncoding.EncodeToString(value.Bytes()) }
+	set := jwkSet{}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk{Kty: "RSA", Kid: kid, Use: "sig", N: encode(key.N), E: encode(big.NewInt(int64(key.E)))})
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keySet, err := NewKeySetFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keySet
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+)
+
+const (
+	ScopeWalletRead  = "wallet:read"
+	ScopeWalletWrite = "wallet:write"
+	ScopeAdmin       = "admin"
+)
+
+type PrincipalKind string
+
+const (
+	PrincipalKindUser    PrincipalKind = "user"
+	PrincipalKindService PrincipalKind = "service"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject    string        `json:"subject"`
+	CustomerID string        `json:"customerId,omitempty"`
+	Kind       PrincipalKind `json:"kind"`
+	Scopes     []string      `json:"scopes"`
+	Roles      []Role        `json:"roles,omitempty"`
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) HasRole(role Role) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin is true for the admin role and, for backwards compatibility, the
+// admin scope
+func (p *Principal) IsAdmin() bool {
+	return p.HasScope(ScopeAdmin) || p.HasRole(RoleAdmin)
+}
+
+// EffectiveRoles returns the roles used for permission checks. Users without
+// roles are customers.
+func (p *Principal) EffectiveRoles() []Role {
+	if p.IsAdmin() {
+		return []Role{RoleAdmin}
+	}
+	if len(p.Roles) == 0 && p.Kind == PrincipalKindUser {
+		return []Role{RoleCustomer}
+	}
+	return p.Roles
+}
+
+// Can reports whether any role of the principal grants the permission
+func (p *Principal) Can(permission Permission) bool {
+	for _, role := range p.EffectiveRoles() {
+		if RoleGrants(role, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) Actor() *Actor {
+	return &Actor{
+		Subject: p.Subject,
+		Kind:    p.Kind,
+		Roles:   p.EffectiveRoles(),
+	}
+}
+
+type principalKey struct{}
+
+// GinContextKey is where the middleware stores the principal in gin.Context
+const GinContextKey = "principal"
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of the request, or nil when the
+// request was not authenticated
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
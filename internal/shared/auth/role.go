@@ -0,0 +1,80 @@
+package auth
+
+// Role groups permissions for staff and customers. Roles come from the
+// "roles" claim of the token; users without roles are customers.
+type Role string
+
+const (
+	RoleCustomer   Role = "customer"
+	RoleSupport    Role = "support"
+	RoleCompliance Role = "compliance"
+	RoleAdmin      Role = "admin"
+)
+
+// Permission is an administrative action enforced per route
+type Permission string
+
+const (
+	PermissionWalletList       Permission = "wallet.list"
+	PermissionWalletReadAny    Permission = "wallet.read_any"
+	PermissionWalletBlock      Permission = "wallet.block"
+	PermissionWalletDeactivate Permission = "wallet.deactivate"
+	PermissionWalletSettings   Permission = "wallet.settings"
+	PermissionOperationReverse Permission = "operation.reverse"
+	PermissionOperationApprove Permission = "operation.approve"
+	PermissionLimitsManage     Permission = "limits.manage"
+	PermissionRiskManage       Permission = "risk.manage"
+	PermissionReportsRead      Permission = "reports.read"
+	PermissionIntegrityVerify  Permission = "integrity.verify"
+	PermissionAuditRead        Permission = "audit.read"
+	PermissionWebhooksManage   Permission = "webhooks.manage"
+	PermissionAPIKeysManage    Permission = "apikeys.manage"
+)
+
+// rolePermissions is the permission matrix. Customers act only on their own
+// wallets through scopes and hold no administrative permission; admins hold
+// every permission.
+var rolePermissions = map[Role][]Permission{
+	RoleCustomer: {},
+	RoleSupport: {
+		PermissionWalletList,
+		PermissionWalletReadAny,
+		PermissionWalletBlock,
+	},
+	RoleCompliance: {
+		PermissionWalletList,
+		PermissionWalletReadAny,
+		PermissionWalletBlock,
+		PermissionWalletDeactivate,
+		PermissionOperationReverse,
+		PermissionOperationApprove,
+		PermissionLimitsManage,
+		PermissionRiskManage,
+		PermissionReportsRead,
+		PermissionIntegrityVerify,
+		PermissionAuditRead,
+	},
+}
+
+// ParseRole returns the role for a claim value and whether it is known
+func ParseRole(value string) (Role, bool) {
+	role := Role(value)
+	switch role {
+	case RoleCustomer, RoleSupport, RoleCompliance, RoleAdmin:
+		return role, true
+	}
+	return "", false
+}
+
+// RoleGrants reports whether the matrix grants the permission to the role
+func RoleGrants(role Role, permission Permission) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -1,31 +1,70 @@
 package config
 
 import (
+	"errors"
 	"os"
-	"strconv"
+	"time"
 )
 
 type Config struct {
-	Server  ServerConfig
-	MongoDB MongoDBConfig
-	Kafka   KafkaConfig
-	Health  HealthConfig
+	Server     ServerConfig
+	MongoDB    MongoDBConfig
+	Kafka      KafkaConfig
+	Worker     WorkerConfig
+	Health     HealthConfig
+	Wallet     WalletConfig
+	Webhook    WebhookConfig
+	Auth       AuthConfig
+	RateLimit  RateLimitConfig
+	Integrity  IntegrityConfig
+	Encryption EncryptionConfig
+	Signing    RequestSigningConfig
+	Approval   ApprovalConfig
+	Metrics    MetricsConfig
+	Tracing    TracingConfig
+	Log        LogConfig
+	Reload     ReloadConfig
+
+	// File is the configuration file the settings were read from, or empty
+	File string
 }
 
 type ServerConfig struct {
 	Port string
 	Env  string
+	// ShutdownTimeout bounds the drain of HTTP requests, Kafka consumers and
+	// the producer on SIGTERM
+	ShutdownTimeout time.Duration
 }
 
 type MongoDBConfig struct {
 	URI      string
 	Database string
+	// MaxPoolSize and MinPoolSize bound the connections per server
+	MaxPoolSize int
+	MinPoolSize int
+	// ReadPreference is primary, primaryPreferred, secondary,
+	// secondaryPreferred or nearest. Reads from secondaries may be stale;
+	// transactions always read from the primary.
+	ReadPreference         string
+	ConnectTimeout         time.Duration
+	ServerSelectionTimeout time.Duration
+	// Timeout bounds every operation; 0 leaves it to the request context
+	Timeout time.Duration
+	// MigrateOnStartup applies the pending schema migrations when the API or
+	// the worker starts; when off, run walletctl migrate up before deploying
+	MigrateOnStartup bool
 }
 
 type KafkaConfig struct {
+	// Backend is "kafka" or "memory", an in-process bus for local and test
+	// runs that only works when the API and the worker share the process
+	Backend string
 	Brokers []string
 	GroupID string
 	Topics  KafkaTopics
+	// Partitions of the topics created at startup; existing topics keep theirs
+	Partitions int
 }
 
 type KafkaTopics struct {
@@ -34,50 +73,280 @@ type KafkaTopics struct {
 	Transfer string
 }
 
+// WorkerConfig controls the process that consumes the Kafka topics
+type WorkerConfig struct {
+	// Concurrency is the number of readers of each topic. Readers share the
+	// consumer group, so more readers than partitions stay idle.
+	Concurrency int
+	// Port serves the probes and metrics of cmd/worker
+	Port string
+}
+
 type HealthConfig struct {
 	ShowDetails bool
+	// CheckInterval is how long MongoDB and broker results are cached;
+	// ConsumerCheckInterval does the same for group membership and lag, which
+	// cost several broker round trips
+	CheckInterval         time.Duration
+	ConsumerCheckInterval time.Duration
+	// CheckTimeout bounds each dependency check
+	CheckTimeout time.Duration
+	// MaxConsumerLag is the lag above which a topic makes the service not
+	// ready; LagThresholds overrides it per topic as "topic=messages"
+	MaxConsumerLag int64
+	LagThresholds  []string
+}
+
+type WalletConfig struct {
+	// DefaultTimeZone is the IANA zone used for day boundaries when neither the
+	// request nor the wallet defines one. UTC by default, as before wallets had
+	// a time zone, so existing reports keep their buckets.
+	DefaultTimeZone string
+}
+
+type WebhookConfig struct {
+	WorkerEnabled  bool
+	PollInterval   time.Duration
+	RequestTimeout time.Duration
+	MaxAttempts    int
+	BaseBackoff    time.Duration
+}
+
+type AuthConfig struct {
+	Enabled bool
+	// JWKSFile or JWKSURL provide the verification keys; HMACSecret adds a
+	// single shared key for HS* tokens
+	JWKSFile            string
+	JWKSURL             string
+	JWKSRefreshInterval time.Duration
+	HMACSecret          string
+	Algorithms          []string
+	Issuer              string
+	Audience            string
+	// CustomerClaim is the JWT claim matched against Wallet.CustomerID
+	CustomerClaim string
 }
 
-// TODO: adjust to use replics primary, secundary and secundary2
+// UsesHMAC reports whether HMACSecret is the verification key: it is only
+// used when no JWKS is configured
+func (c AuthConfig) UsesHMAC() bool {
+	return c.HMACSecret != "" && c.JWKSFile == "" && c.JWKSURL == ""
+}
+
+// defaultJWTAlgorithms accepts HS256 when the HMAC secret is the only key,
+// and the asymmetric algorithms of a JWKS otherwise
+func defaultJWTAlgorithms(l *loader) []string {
+	auth := AuthConfig{
+		HMACSecret: l.string("AUTH_JWT_HMAC_SECRET", ""),
+		JWKSFile:   l.string("AUTH_JWKS_FILE", ""),
+		JWKSURL:    l.string("AUTH_JWKS_URL", ""),
+	}
+	if auth.UsesHMAC() {
+		return []string{"HS256"}
+	}
+	return []string{"RS256", "ES256"}
+}
+
+type RateLimitConfig struct {
+	Enabled bool
+	// Backend is "memory" (per instance) or "mongo" (shared between instances)
+	Backend string
+	// Default and Routes use "<requests>/<duration>"; routes are given as
+	// "METHOD /path=<requests>/<duration>" with gin path syntax
+	Default string
+	Routes  []string
+}
+
+type IntegrityConfig struct {
+	// SigningKeyFile is a PKCS#8 PEM Ed25519 key; without it no checkpoints
+	// are created
+	SigningKeyFile     string
+	CheckpointInterval time.Duration
+}
+
+type EncryptionConfig struct {
+	// KeyFile is the local key file of the field encryption keys; without it
+	// customer IDs are stored in plaintext
+	KeyFile string
+}
+
+type RequestSigningConfig struct {
+	// Mode is "off", "optional" (signatures are checked when present) or
+	// "required" on the deposit, withdraw and transfer routes
+	Mode string
+	// Keys are "keyId:base64secret" entries
+	Keys []string
+	// Window is the accepted clock skew of the signature timestamp
+	Window time.Duration
+	// NonceBackend is "memory" (per instance) or "mongo" (shared between
+	// instances)
+	NonceBackend string
+}
+
+type ApprovalConfig struct {
+	// ThresholdInCents parks withdrawals and transfers above the amount until
+	// a second person approves them; 0 disables approvals
+	ThresholdInCents int64
+	// TTL is how long a parked debit waits before it expires
+	TTL            time.Duration
+	ExpiryInterval time.Duration
+}
+
+type MetricsConfig struct {
+	// Enabled exposes Prometheus metrics on /metrics
+	Enabled bool
+}
 
-func Load() *Config {
-	return &Config{
+type TracingConfig struct {
+	// Exporter is "none", "stdout" (local use) or "otlp"
+	Exporter string
+	// Endpoint is the URL of the OTLP/HTTP collector; http:// disables TLS
+	Endpoint    string
+	ServiceName string
+	// SampleRatio is the fraction of new traces recorded
+	SampleRatio float64
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error
+	Level string
+	// Format is "json" or "text"
+	Format string
+}
+
+// ReloadConfig controls the hot reload of the configuration file
+type ReloadConfig struct {
+	// Interval is how often the file is checked for changes; 0 reloads only
+	// on SIGHUP
+	Interval time.Duration
+}
+
+// Load reads the file named by CONFIG_FILE, if any, and the environment.
+// Environment variables override the file.
+func Load() (*Config, error) {
+	return LoadFile(os.Getenv("CONFIG_FILE"))
+}
+
+// LoadFile reads and validates the configuration. Keys of the file are the
+// environment variable names, either flat (SERVER_PORT) or nested by their
+// underscore-separated parts (server: port:).
+func LoadFile(path string) (*Config, error) {
+	l, err := newLoader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Port:            l.string("SERVER_PORT", "8080"),
+			Env:             l.string("ENV", "development"),
+			ShutdownTimeout: l.duration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
 		},
 		MongoDB: MongoDBConfig{
-			URI:      getEnv("MONGODB_URI", "mongodb://localhost:27017"),
-			Database: getEnv("MONGODB_DATABASE", "wallet"),
+			URI:                    l.string("MONGODB_URI", "mongodb://localhost:27017"),
+			Database:               l.string("MONGODB_DATABASE", "wallet"),
+			MaxPoolSize:            l.int("MONGODB_MAX_POOL_SIZE", 100),
+			MinPoolSize:            l.int("MONGODB_MIN_POOL_SIZE", 0),
+			ReadPreference:         l.string("MONGODB_READ_PREFERENCE", "primary"),
+			ConnectTimeout:         l.duration("MONGODB_CONNECT_TIMEOUT", 10*time.Second),
+			ServerSelectionTimeout: l.duration("MONGODB_SERVER_SELECTION_TIMEOUT", 30*time.Second),
+			Timeout:                l.duration("MONGODB_TIMEOUT", 0),
+			MigrateOnStartup:       l.bool("MONGODB_MIGRATE_ON_STARTUP", true),
 		},
 		Kafka: KafkaConfig{
-			Brokers: []string{getEnv("KAFKA_BROKERS", "localhost:29092")},
-			GroupID: getEnv("KAFKA_GROUP_ID", "wallet-group"),
+			Backend: l.string("KAFKA_BACKEND", "kafka"),
+			Brokers: l.list("KAFKA_BROKERS", []string{"localhost:29092"}),
+			GroupID: l.string("KAFKA_GROUP_ID", "wallet-group"),
 			Topics: KafkaTopics{
-				Deposit:  getEnv("KAFKA_TOPIC_DEPOSIT", "wallet.deposit"),
-				Withdraw: getEnv("KAFKA_TOPIC_WITHDRAW", "wallet.withdraw"),
-				Transfer: getEnv("KAFKA_TOPIC_TRANSFER", "wallet.transfer"),
+				Deposit:  l.string("KAFKA_TOPIC_DEPOSIT", "wallet.deposit"),
+				Withdraw: l.string("KAFKA_TOPIC_WITHDRAW", "wallet.withdraw"),
+				Transfer: l.string("KAFKA_TOPIC_TRANSFER", "wallet.transfer"),
 			},
+			Partitions: l.int("KAFKA_TOPIC_PARTITIONS", 1),
+		},
+		Worker: WorkerConfig{
+			Concurrency: l.int("WORKER_CONCURRENCY", 1),
+			Port:        l.string("WORKER_PORT", "8081"),
 		},
 		Health: HealthConfig{
-			ShowDetails: getBoolEnv("HEALTH_SHOW_DETAILS", false),
+			ShowDetails:           l.bool("HEALTH_SHOW_DETAILS", false),
+			CheckInterval:         l.duration("HEALTH_CHECK_INTERVAL", 5*time.Second),
+			ConsumerCheckInterval: l.duration("HEALTH_CONSUMER_CHECK_INTERVAL", 30*time.Second),
+			CheckTimeout:          l.duration("HEALTH_CHECK_TIMEOUT", 3*time.Second),
+			MaxConsumerLag:        int64(l.int("HEALTH_MAX_CONSUMER_LAG", 10000)),
+			LagThresholds:         l.list("HEALTH_LAG_THRESHOLDS", nil),
+		},
+		Wallet: WalletConfig{
+			DefaultTimeZone: l.string("WALLET_DEFAULT_TIME_ZONE", "UTC"),
+		},
+		Webhook: WebhookConfig{
+			WorkerEnabled:  l.bool("WEBHOOK_WORKER_ENABLED", true),
+			PollInterval:   l.duration("WEBHOOK_POLL_INTERVAL", 2*time.Second),
+			RequestTimeout: l.duration("WEBHOOK_REQUEST_TIMEOUT", 10*time.Second),
+			MaxAttempts:    l.int("WEBHOOK_MAX_ATTEMPTS", 8),
+			BaseBackoff:    l.duration("WEBHOOK_BASE_BACKOFF", 30*time.Second),
 		},
+		Auth: AuthConfig{
+			Enabled:             l.bool("AUTH_ENABLED", false),
+			JWKSFile:            l.string("AUTH_JWKS_FILE", ""),
+			JWKSURL:             l.string("AUTH_JWKS_URL", ""),
+			JWKSRefreshInterval: l.duration("AUTH_JWKS_REFRESH_INTERVAL", 15*time.Minute),
+			HMACSecret:          l.string("AUTH_JWT_HMAC_SECRET", ""),
+			Algorithms:          l.list("AUTH_JWT_ALGORITHMS", defaultJWTAlgorithms(l)),
+			Issuer:              l.string("AUTH_JWT_ISSUER", ""),
+			Audience:            l.string("AUTH_JWT_AUDIENCE", ""),
+			CustomerClaim:       l.string("AUTH_CUSTOMER_CLAIM", "sub"),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: l.bool("RATE_LIMIT_ENABLED", true),
+			Backend: l.string("RATE_LIMIT_BACKEND", "memory"),
+			Default: l.string("RATE_LIMIT_DEFAULT", "300/1m"),
+			Routes: l.list("RATE_LIMIT_ROUTES", []string{
+				"POST /wallet/:id/deposit=30/1m",
+				"POST /wallet/:id/withdraw=10/1m",
+				"POST /wallet/:id/transfer=10/1m",
+			}),
+		},
+		Integrity: IntegrityConfig{
+			SigningKeyFile:     l.string("INTEGRITY_SIGNING_KEY_FILE", ""),
+			CheckpointInterval: l.duration("INTEGRITY_CHECKPOINT_INTERVAL", time.Hour),
+		},
+		Encryption: EncryptionConfig{
+			KeyFile: l.string("ENCRYPTION_KEY_FILE", ""),
+		},
+		Signing: RequestSigningConfig{
+			Mode:         l.string("REQUEST_SIGNING_MODE", "off"),
+			Keys:         l.list("REQUEST_SIGNING_KEYS", nil),
+			Window:       l.duration("REQUEST_SIGNING_WINDOW", 5*time.Minute),
+			NonceBackend: l.string("REQUEST_SIGNING_NONCE_BACKEND", "memory"),
+		},
+		Approval: ApprovalConfig{
+			ThresholdInCents: int64(l.int("APPROVAL_THRESHOLD_IN_CENTS", 0)),
+			TTL:              l.duration("APPROVAL_TTL", 24*time.Hour),
+			ExpiryInterval:   l.duration("APPROVAL_EXPIRY_INTERVAL", time.Minute),
+		},
+		Metrics: MetricsConfig{
+			Enabled: l.bool("METRICS_ENABLED", true),
+		},
+		Tracing: TracingConfig{
+			Exporter:    l.string("OTEL_TRACES_EXPORTER", "none"),
+			Endpoint:    l.string("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+			ServiceName: l.string("OTEL_SERVICE_NAME", "wallet-go"),
+			SampleRatio: l.float("OTEL_TRACES_SAMPLE_RATIO", 1),
+		},
+		Log: LogConfig{
+			Level:  l.string("LOG_LEVEL", "info"),
+			Format: l.string("LOG_FORMAT", "json"),
+		},
+		Reload: ReloadConfig{
+			Interval: l.duration("CONFIG_RELOAD_INTERVAL", 10*time.Second),
+		},
+		File: path,
 	}
-}
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	if err := errors.Join(l.finish(), cfg.Validate()); err != nil {
+		return nil, err
 	}
-	return defaultValue
-}
 
-func getBoolEnv(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		b, err := strconv.ParseBool(value)
-		if err == nil {
-			return b
-		}
-	}
-	return defaultValue
+	return cfg, nil
 }
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// loader resolves each setting from the environment, then the file, then
+// the default, and collects every invalid value instead of stopping at the
+// first one
+type loader struct {
+	path  string
+	file  map[string]string
+	known map[string]bool
+	errs  []error
+}
+
+func newLoader(path string) (*loader, error) {
+	l := &loader{
+		path:  path,
+		file:  map[string]string{},
+		known: map[string]bool{},
+	}
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var tree map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s: unknown format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	if err := flatten("", tree, l.file); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// flatten turns nested keys into environment variable names, so that
+// server: {port: 8080} and SERVER_PORT: 8080 set the same value
+func flatten(prefix string, tree map[string]interface{}, out map[string]string) error {
+	for key, value := range tree {
+		name := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if err := flatten(name, v, out); err != nil {
+				return err
+			}
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				text, err := scalar(name, item)
+				if err != nil {
+					return err
+				}
+				items[i] = text
+			}
+			out[name] = strings.Join(items, ",")
+		default:
+			text, err := scalar(name, v)
+			if err != nil {
+				return err
+			}
+			out[name] = text
+		}
+	}
+	return nil
+}
+
+func scalar(name string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	default:
+		return "", fmt.Errorf("%s: unsupported value %v", name, value)
+	}
+}
+
+func (l *loader) lookup(key string) (string, bool) {
+	l.known[key] = true
+	if value := os.Getenv(key); value != "" {
+		return value, true
+	}
+	if value := l.file[key]; value != "" {
+		return value, true
+	}
+	return "", false
+}
+
+func (l *loader) invalid(key, value, expected string) {
+	l.errs = append(l.errs, fmt.Errorf("%s: invalid value %q, expected %s", key, value, expected))
+}
+
+// finish reports the invalid values and the file keys that match no setting,
+// which are usually typos
+func (l *loader) finish() error {
+	var unknown []string
+	for key := range l.file {
+		if !l.known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		l.errs = append(l.errs, fmt.Errorf("%s: unknown setting in %s", key, l.path))
+	}
+
+	return errors.Join(l.errs...)
+}
+
+func (l *loader) string(key, defaultValue string) string {
+	if value, ok := l.lookup(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (l *loader) bool(key string, defaultValue bool) bool {
+	if value, ok := l.lookup(key); ok {
+		b, err := strconv.ParseBool(value)
+		if err == nil {
+			return b
+		}
+		l.invalid(key, value, "true or false")
+	}
+	return defaultValue
+}
+
+func (l *loader) float(key string, defaultValue float64) float64 {
+	if value, ok := l.lookup(key); ok {
+		f, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return f
+		}
+		l.invalid(key, value, "a number")
+	}
+	return defaultValue
+}
+
+func (l *loader) int(key string, defaultValue int) int {
+	if value, ok := l.lookup(key); ok {
+		i, err := strconv.Atoi(value)
+		if err == nil {
+			return i
+		}
+		l.invalid(key, value, "an integer")
+	}
+	return defaultValue
+}
+
+func (l *loader) duration(key string, defaultValue time.Duration) time.Duration {
+	if value, ok := l.lookup(key); ok {
+		d, err := time.ParseDuration(value)
+		if err == nil {
+			return d
+		}
+		l.invalid(key, value, "a duration such as 30s or 5m")
+	}
+	return defaultValue
+}
+
+func (l *loader) list(key string, defaultValue []string) []string {
+	if value, ok := l.lookup(key); ok {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		if len(items) > 0 {
+			return items
+		}
+	}
+	return defaultValue
+}
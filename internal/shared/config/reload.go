@@ -0,0 +1,143 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Reloader re-reads the configuration file when it changes or on demand and
+// hands the new settings to the listeners. Only the settings copied by
+// applySafe change at runtime; changes to any other setting are logged and
+// wait for a restart.
+type Reloader struct {
+	mu        sync.Mutex
+	current   *Config
+	modTime   time.Time
+	listeners []func(*Config)
+}
+
+func NewReloader(cfg *Config) *Reloader {
+	r := &Reloader{current: cfg}
+	if cfg.File != "" {
+		if info, err := os.Stat(cfg.File); err == nil {
+			r.modTime = info.ModTime()
+		}
+	}
+	return r
+}
+
+// OnReload registers fn, called with the running settings after each
+// successful reload
+func (r *Reloader) OnReload(fn func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Current returns the running settings
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Start polls the file for changes until ctx is done. Without a file or an
+// interval it does nothing; Reload can still be called, e.g. on SIGHUP.
+func (r *Reloader) Start(ctx context.Context) {
+	cfg := r.Current()
+	if cfg.File == "" || cfg.Reload.Interval <= 0 {
+		return
+	}
+
+	slog.Info("Watching config file", "file", cfg.File, "interval", cfg.Reload.Interval.String())
+
+	ticker := time.NewTicker(cfg.Reload.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.fileChanged(cfg.File) {
+				r.Reload()
+			}
+		}
+	}
+}
+
+func (r *Reloader) fileChanged(file string) bool {
+	info, err := os.Stat(file)
+	if err != nil {
+		slog.Warn("Could not read config file", "file", file, "error", err)
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !info.ModTime().Equal(r.modTime)
+}
+
+// Reload reads the file again. An invalid file keeps the running settings.
+func (r *Reloader) Reload() {
+	cfg, listeners, ok := r.reload()
+	if !ok {
+		return
+	}
+
+	for _, listener := range listeners {
+		listener(cfg)
+	}
+	slog.Info("Config reloaded", "file", cfg.File)
+}
+
+func (r *Reloader) reload() (*Config, []func(*Config), bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current.File == "" {
+		slog.Info("No CONFIG_FILE set, nothing to reload")
+		return nil, nil, false
+	}
+	if info, err := os.Stat(r.current.File); err == nil {
+		r.modTime = info.ModTime()
+	}
+
+	next, err := LoadFile(r.current.File)
+	if err != nil {
+		slog.Error("Config reload failed, keeping the running settings", "file", r.current.File, "error", err)
+		return nil, nil, false
+	}
+
+	running := *r.current
+	applySafe(&running, next)
+	if changed := changedSections(&running, next); len(changed) > 0 {
+		slog.Warn("Config changes that need a restart were not applied", "sections", changed)
+	}
+
+	r.current = &running
+	return r.current, append([]func(*Config){}, r.listeners...), true
+}
+
+// applySafe copies the settings that can change while the service runs
+func applySafe(running, next *Config) {
+	running.Log.Level = next.Log.Level
+	running.RateLimit.Default = next.RateLimit.Default
+	running.RateLimit.Routes = next.RateLimit.Routes
+	running.Approval.ThresholdInCents = next.Approval.ThresholdInCents
+}
+
+func changedSections(running, next *Config) []string {
+	var changed []string
+	a, b := reflect.ValueOf(running).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < a.NumField(); i++ {
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			changed = append(changed, a.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
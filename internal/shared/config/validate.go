@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate checks the settings that would otherwise fail later, or silently
+// fall back, and reports all of them at once
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		v.fail("SERVER_PORT", c.Server.Port, "a port between 1 and 65535")
+	}
+
+	v.positive("SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout)
+
+	v.required("MONGODB_URI", c.MongoDB.URI)
+	v.required("MONGODB_DATABASE", c.MongoDB.Database)
+	v.oneOf("MONGODB_READ_PREFERENCE", c.MongoDB.ReadPreference, "primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest")
+	if c.MongoDB.MaxPoolSize < 0 {
+		v.fail("MONGODB_MAX_POOL_SIZE", c.MongoDB.MaxPoolSize, "0 (no limit) or more")
+	}
+	if c.MongoDB.MinPoolSize < 0 || (c.MongoDB.MaxPoolSize > 0 && c.MongoDB.MinPoolSize > c.MongoDB.MaxPoolSize) {
+		v.fail("MONGODB_MIN_POOL_SIZE", c.MongoDB.MinPoolSize, "between 0 and MONGODB_MAX_POOL_SIZE")
+	}
+	v.positive("MONGODB_CONNECT_TIMEOUT", c.MongoDB.ConnectTimeout)
+	v.positive("MONGODB_SERVER_SELECTION_TIMEOUT", c.MongoDB.ServerSelectionTimeout)
+	v.notNegative("MONGODB_TIMEOUT", c.MongoDB.Timeout)
+
+	v.oneOf("KAFKA_BACKEND", c.Kafka.Backend, "kafka", "memory")
+	if len(c.Kafka.Brokers) == 0 {
+		v.fail("KAFKA_BROKERS", "", "a comma-separated list of host:port")
+	}
+	for _, broker := range c.Kafka.Brokers {
+		if _, _, err := net.SplitHostPort(broker); err != nil {
+			v.fail("KAFKA_BROKERS", broker, "host:port")
+		}
+	}
+	v.required("KAFKA_GROUP_ID", c.Kafka.GroupID)
+	v.required("KAFKA_TOPIC_DEPOSIT", c.Kafka.Topics.Deposit)
+	v.required("KAFKA_TOPIC_WITHDRAW", c.Kafka.Topics.Withdraw)
+	v.required("KAFKA_TOPIC_TRANSFER", c.Kafka.Topics.Transfer)
+	if c.Kafka.Partitions < 1 {
+		v.fail("KAFKA_TOPIC_PARTITIONS", c.Kafka.Partitions, "1 or more")
+	}
+
+	if c.Worker.Concurrency < 1 {
+		v.fail("WORKER_CONCURRENCY", c.Worker.Concurrency, "1 or more")
+	}
+	if port, err := strconv.Atoi(c.Worker.Port); err != nil || port < 1 || port > 65535 {
+		v.fail("WORKER_PORT", c.Worker.Port, "a port between 1 and 65535")
+	}
+
+	v.positive("HEALTH_CHECK_INTERVAL", c.Health.CheckInterval)
+	v.positive("HEALTH_CONSUMER_CHECK_INTERVAL", c.Health.ConsumerCheckInterval)
+	v.positive("HEALTH_CHECK_TIMEOUT", c.Health.CheckTimeout)
+	if c.Health.MaxConsumerLag < 0 {
+		v.fail("HEALTH_MAX_CONSUMER_LAG", c.Health.MaxConsumerLag, "0 or more")
+	}
+
+	if _, err := time.LoadLocation(c.Wallet.DefaultTimeZone); err != nil {
+		v.fail("WALLET_DEFAULT_TIME_ZONE", c.Wallet.DefaultTimeZone, "an IANA time zone")
+	}
+
+	v.positive("WEBHOOK_POLL_INTERVAL", c.Webhook.PollInterval)
+	v.positive("WEBHOOK_REQUEST_TIMEOUT", c.Webhook.RequestTimeout)
+	v.positive("WEBHOOK_BASE_BACKOFF", c.Webhook.BaseBackoff)
+	if c.Webhook.MaxAttempts < 1 {
+		v.fail("WEBHOOK_MAX_ATTEMPTS", c.Webhook.MaxAttempts, "1 or more")
+	}
+
+	v.positive("AUTH_JWKS_REFRESH_INTERVAL", c.Auth.JWKSRefreshInterval)
+	if c.Auth.Enabled && len(c.Auth.Algorithms) == 0 {
+		v.fail("AUTH_JWT_ALGORITHMS", "", "at least one algorithm")
+	}
+	if c.Auth.Enabled && c.Auth.UsesHMAC() && !hasHMACAlgorithm(c.Auth.Algorithms) {
+		v.fail("AUTH_JWT_ALGORITHMS", strings.Join(c.Auth.Algorithms, ","), "an HS* algorithm when AUTH_JWT_HMAC_SECRET is the only key")
+	}
+
+	v.oneOf("RATE_LIMIT_BACKEND", c.RateLimit.Backend, "memory", "mongo")
+	v.positive("INTEGRITY_CHECKPOINT_INTERVAL", c.Integrity.CheckpointInterval)
+
+	v.oneOf("REQUEST_SIGNING_MODE", c.Signing.Mode, "off", "optional", "required")
+	v.oneOf("REQUEST_SIGNING_NONCE_BACKEND", c.Signing.NonceBackend, "memory", "mongo")
+	v.positive("REQUEST_SIGNING_WINDOW", c.Signing.Window)
+
+	if c.Approval.ThresholdInCents < 0 {
+		v.fail("APPROVAL_THRESHOLD_IN_CENTS", c.Approval.ThresholdInCents, "0 (disabled) or more")
+	}
+	v.positive("APPROVAL_TTL", c.Approval.TTL)
+	v.positive("APPROVAL_EXPIRY_INTERVAL", c.Approval.ExpiryInterval)
+
+	v.oneOf("OTEL_TRACES_EXPORTER", c.Tracing.Exporter, "none", "stdout", "otlp")
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		v.fail("OTEL_TRACES_SAMPLE_RATIO", c.Tracing.SampleRatio, "between 0 and 1")
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToUpper(c.Log.Level))); err != nil {
+		v.fail("LOG_LEVEL", c.Log.Level, "debug, info, warn or error")
+	}
+	v.oneOf("LOG_FORMAT", c.Log.Format, "json", "text")
+
+	v.notNegative("CONFIG_RELOAD_INTERVAL", c.Reload.Interval)
+
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(key string, value interface{}, expected string) {
+	v.errs = append(v.errs, fmt.Errorf("%s: invalid value %q, expected %s", key, fmt.Sprint(value), expected))
+}
+
+func (v *validator) required(key, value string) {
+	if value == "" {
+		v.errs = append(v.errs, fmt.Errorf("%s: is required", key))
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, option := range allowed {
+		if value == option {
+			return
+		}
+	}
+	v.fail(key, value, strings.Join(allowed, ", "))
+}
+
+func (v *validator) positive(key string, value time.Duration) {
+	if value <= 0 {
+		v.fail(key, value, "a positive duration")
+	}
+}
+
+func (v *validator) notNegative(key string, value time.Duration) {
+	if value < 0 {
+		v.fail(key, value, "0 or a positive duration")
+	}
+}
+
+func hasHMACAlgorithm(algorithms []string) bool {
+	for _, algorithm := range algorithms {
+		if strings.HasPrefix(algorithm, "HS") {
+			return true
+		}
+	}
+	return false
+}
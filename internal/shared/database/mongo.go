@@ -2,10 +2,15 @@ package database
 
 import (
 	"context"
-	"time"
+	"errors"
+	"strings"
+
+	"wallet-go/internal/shared/config"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
 type MongoClient struct {
@@ -13,23 +18,44 @@ type MongoClient struct {
 	Database *mongo.Database
 }
 
-func NewMongoClient(uri string) (*MongoClient, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func NewMongoClient(cfg config.MongoDBConfig) (*MongoClient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ServerSelectionTimeout)
 	defer cancel()
 
-	clientOptions := options.Client().ApplyURI(uri)
+	mode, err := readpref.ModeFromString(cfg.ReadPreference)
+	if err != nil {
+		return nil, err
+	}
+	readPreference, err := readpref.New(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every command becomes a span of the trace in its context
+	clientOptions := options.Client().
+		ApplyURI(cfg.URI).
+		SetMonitor(otelmongo.NewMonitor()).
+		SetMaxPoolSize(uint64(cfg.MaxPoolSize)).
+		SetMinPoolSize(uint64(cfg.MinPoolSize)).
+		SetReadPreference(readPreference).
+		SetConnectTimeout(cfg.ConnectTimeout).
+		SetServerSelectionTimeout(cfg.ServerSelectionTimeout)
+	if cfg.Timeout > 0 {
+		clientOptions.SetTimeout(cfg.Timeout)
+	}
+
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	// Test the connection
-	err = client.Ping(ctx, nil)
+	// Test the connection to the primary, which serves the writes
+	err = client.Ping(ctx, readpref.Primary())
 	if err != nil {
 		return nil, err
 	}
 
-	database := client.Database("wallet")
+	database := client.Database(cfg.Database)
 
 	return &MongoClient{
 		Client:   client,
@@ -44,3 +70,43 @@ func (mc *MongoClient) Disconnect(ctx context.Context) error {
 func (mc *MongoClient) GetCollection(name string) *mongo.Collection {
 	return mc.Database.Collection(name)
 }
+
+// maxTransactionAttempts bounds the retries of a transaction that lost a race
+// on a unique index, such as two appends to the same operation chain
+const maxTransactionAttempts = 5
+
+// ErrTransactionConflict marks an error of a write that lost a race another
+// attempt can win, such as an append to an operation chain that moved. Stores
+// wrap it so WithTransaction retries; other errors are final.
+var ErrTransactionConflict = errors.New("transaction lost a race")
+
+// IsDuplicateKeyOn reports whether err is a duplicate key error on the named
+// unique index
+func IsDuplicateKeyOn(err error, index string) bool {
+	return mongo.IsDuplicateKeyError(err) && strings.Contains(err.Error(), "index: "+index+" ")
+}
+
+// WithTransaction runs fn in a transaction. The stores join it through the
+// context passed to fn, so fn must use that context for every read and write.
+// fn may run more than once: the driver retries transient errors, and an
+// ErrTransactionConflict is retried on a fresh snapshot.
+func (mc *MongoClient) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := mc.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// Transactions must read from the primary, whatever the read preference
+	// of the client
+	opts := options.Transaction().SetReadPreference(readpref.Primary())
+
+	for attempt := 1; ; attempt++ {
+		_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+			return nil, fn(sessionCtx)
+		}, opts)
+		if !errors.Is(err, ErrTransactionConflict) || attempt == maxTransactionAttempts {
+			return err
+		}
+	}
+}
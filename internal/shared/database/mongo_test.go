@@ -0,0 +1,43 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIsDuplicateKeyOn(t *testing.T) {
+	duplicate := func(index string) error {
+		return mongo.WriteException{WriteErrors: []mongo.WriteError{{
+			Code:    11000,
+			Message: "E11000 duplicate key error collection: wallet.operation index: " + index + " dup key: { walletId: 1 }",
+		}}}
+	}
+
+	tests := []struct {
+		name  string
+		err   error
+		index string
+		want  bool
+	}{
+		{"same index", duplicate("walletId_sequence_unique"), "walletId_sequence_unique", true},
+		{"other index", duplicate("operationId_unique"), "walletId_sequence_unique", false},
+		{"index name prefix", duplicate("operationId_unique_v2"), "operationId_unique", false},
+		{"not a duplicate", errors.New("index: operationId_unique dup key"), "operationId_unique", false},
+		{"nil", nil, "operationId_unique", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDuplicateKeyOn(tt.err, tt.index); got != tt.want {
+				t.Fatalf("IsDuplicateKeyOn(%v, %s) = %v, want %v", tt.err, tt.index, got, tt.want)
+			}
+		})
+	}
+
+	if wrapped := fmt.Errorf("%w: %v", ErrTransactionConflict, duplicate("walletId_sequence_unique")); !errors.Is(wrapped, ErrTransactionConflict) {
+		t.Fatal("wrapped chain conflict is not an ErrTransactionConflict")
+	}
+}
@@ -0,0 +1,55 @@
+// Package mongotest gives tests a scratch MongoDB database. Tests using it are
+// skipped unless MONGODB_TEST_URI is set, e.g.
+//
+//	MONGODB_TEST_URI=mongodb://localhost:27017 go test ./...
+package mongotest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"wallet-go/internal/migration"
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/database"
+
+	"github.com/google/uuid"
+)
+
+// Connect returns a client on a new database with the migrations applied. The
+// database is dropped when the test ends.
+func Connect(t testing.TB) *database.MongoClient {
+	t.Helper()
+
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI not set")
+	}
+
+	client, err := database.NewMongoClient(config.MongoDBConfig{
+		URI:                    uri,
+		Database:               fmt.Sprintf("wallet_test_%s", uuid.NewString()[:8]),
+		ReadPreference:         "primary",
+		ConnectTimeout:         5 * time.Second,
+		ServerSelectionTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("connecting to %s: %v", uri, err)
+	}
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		if err := client.Database.Drop(ctx); err != nil {
+			t.Errorf("dropping %s: %v", client.Database.Name(), err)
+		}
+		client.Disconnect(ctx)
+	})
+
+	if _, err := migration.NewMigrator(client).Up(context.Background()); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	return client
+}
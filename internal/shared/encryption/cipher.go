@@ -0,0 +1,164 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Envelope is an encrypted value together with its wrapped data key
+type Envelope struct {
+	KeyID      string `bson:"keyId" json:"keyId"`
+	WrappedKey []byte `bson:"wrappedKey" json:"wrappedKey"`
+	Ciphertext []byte `bson:"ciphertext" json:"ciphertext"`
+}
+
+// FieldCipher encrypts fields and computes blind indexes
+type FieldCipher struct {
+	provider KeyProvider
+}
+
+func NewFieldCipher(provider KeyProvider) *FieldCipher {
+	return &FieldCipher{
+		provider: provider,
+	}
+}
+
+// Encrypt seals the value with a fresh data key. The associated data (e.g.
+// the record ID) is authenticated, so an envelope copied to another record
+// fails to decrypt.
+func (c *FieldCipher) Encrypt(ctx context.Context, plaintext, associatedData string) (*Envelope, error) {
+	dataKey := randomBytes(keySize)
+
+	ciphertext, err := seal(dataKey, []byte(plaintext), []byte(associatedData))
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, wrapped, err := c.provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrap data key: %w", err)
+	}
+
+	return &Envelope{
+		KeyID:      keyID,
+		WrappedKey: wrapped,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+func (c *FieldCipher) Decrypt(ctx context.Context, envelope *Envelope, associatedData string) (string, error) {
+	dataKey, err := c.provider.UnwrapKey(ctx, envelope.KeyID, envelope.WrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("unwrap data key: %w", err)
+	}
+
+	plaintext, err := open(dataKey, envelope.Ciphertext, []byte(associatedData))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsRewrap reports whether the envelope was wrapped with a retired key
+func (c *FieldCipher) NeedsRewrap(envelope *Envelope) bool {
+	return envelope.KeyID != c.provider.CurrentKeyID()
+}
+
+// Rewrap wraps the data key again with the current key. The ciphertext is not
+// touched.
+func (c *FieldCipher) Rewrap(ctx context.Context, envelope *Envelope) (*Envelope, error) {
+	dataKey, err := c.provider.UnwrapKey(ctx, envelope.KeyID, envelope.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+
+	keyID, wrapped, err := c.provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrap data key: %w", err)
+	}
+
+	return &Envelope{
+		KeyID:      keyID,
+		WrappedKey: wrapped,
+		Ciphertext: envelope.Ciphertext,
+	}, nil
+}
+
+// BlindIndex is a deterministic HMAC of the value, so equality lookups work
+// without storing the plaintext. The field name is mixed in so equal values
+// of different fields do not share an index.
+func (c *FieldCipher) BlindIndex(ctx context.Context, field, value string) (string, error) {
+	key, err := c.provider.BlindIndexKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// seal encrypts with AES-256-GCM and prefixes the nonce
+func seal(key, plaintext, associatedData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := randomBytes(aead.NonceSize())
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func open(key, sealed, associatedData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, associatedData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(size int) []byte {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return buf
+}
+
+// LoadFieldCipher builds a cipher backed by a local key file. An empty path
+// returns nil, which leaves fields in plaintext.
+func LoadFieldCipher(keyFile string) (*FieldCipher, error) {
+	if keyFile == "" {
+		return nil, nil
+	}
+
+	provider, err := NewLocalKeyProviderFromFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFieldCipher(provider), nil
+}
@@ -0,0 +1,162 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// newKeyFile generates a key file in a temp dir and returns its path
+func newKeyFile(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := GenerateKeyFile(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func loadCipher(t *testing.T, path string) *FieldCipher {
+	t.Helper()
+
+	cipher, err := LoadFieldCipher(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cipher
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cipher := loadCipher(t, newKeyFile(t))
+
+	envelope, err := cipher.Encrypt(ctx, "customer-1", "wallet-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(envelope.Ciphertext, []byte("customer-1")) {
+		t.Fatal("ciphertext contains the plaintext")
+	}
+
+	plaintext, err := cipher.Decrypt(ctx, envelope, "wallet-1")
+	if err != nil || plaintext != "customer-1" {
+		t.Fatalf("Decrypt = %q, %v; want customer-1", plaintext, err)
+	}
+
+	// Every value gets its own data key and nonce
+	again, _ := cipher.Encrypt(ctx, "customer-1", "wallet-1")
+	if bytes.Equal(again.Ciphertext, envelope.Ciphertext) || bytes.Equal(again.WrappedKey, envelope.WrappedKey) {
+		t.Fatal("two encryptions of the same value are equal")
+	}
+}
+
+func TestDecryptRejectsWrongKeyAndData(t *testing.T) {
+	ctx := context.Background()
+	cipher := loadCipher(t, newKeyFile(t))
+	other := loadCipher(t, newKeyFile(t))
+
+	envelope, err := cipher.Encrypt(ctx, "customer-1", "wallet-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := other.Decrypt(ctx, envelope, "wallet-1"); err == nil {
+		t.Fatal("another key file decrypted the envelope")
+	}
+	if _, err := cipher.Decrypt(ctx, envelope, "wallet-2"); err == nil {
+		t.Fatal("envelope decrypted under another record ID")
+	}
+
+	tampered := *envelope
+	tampered.Ciphertext = append([]byte(nil), envelope.Ciphertext...)
+	tampered.Ciphertext[len(tampered.Ciphertext)-1] ^= 1
+	if _, err := cipher.Decrypt(ctx, &tampered, "wallet-1"); err == nil {
+		t.Fatal("tampered ciphertext decrypted")
+	}
+}
+
+func TestRewrapRetiresOldKey(t *testing.T) {
+	ctx := context.Background()
+	path := newKeyFile(t)
+	original := loadCipher(t, path)
+
+	envelope, err := original.Encrypt(ctx, "customer-1", "wallet-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKeyID := envelope.KeyID
+
+	// Rotate with a new current key; the old one stays for unwrapping
+	file, err := readKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Keys["k-rotated"] = newEncodedKey()
+	file.CurrentKeyID = "k-rotated"
+	if err := writeKeyFile(path, file); err != nil {
+		t.Fatal(err)
+	}
+	rotated := loadCipher(t, path)
+
+	if !rotated.NeedsRewrap(envelope) {
+		t.Fatal("envelope of the retired key does not need a rewrap")
+	}
+	rewrapped, err := rotated.Rewrap(ctx, envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewrapped.KeyID != "k-rotated" || rotated.NeedsRewrap(rewrapped) {
+		t.Fatalf("rewrapped with %q, want k-rotated", rewrapped.KeyID)
+	}
+	if !bytes.Equal(rewrapped.Ciphertext, envelope.Ciphertext) {
+		t.Fatal("rewrap changed the ciphertext")
+	}
+
+	// Once the old key is removed only the rewrapped envelope decrypts
+	delete(file.Keys, oldKeyID)
+	if err := writeKeyFile(path, file); err != nil {
+		t.Fatal(err)
+	}
+	retired := loadCipher(t, path)
+
+	if plaintext, err := retired.Decrypt(ctx, rewrapped, "wallet-1"); err != nil || plaintext != "customer-1" {
+		t.Fatalf("Decrypt of the rewrapped envelope = %q, %v", plaintext, err)
+	}
+	if _, err := retired.Decrypt(ctx, envelope, "wallet-1"); err == nil {
+		t.Fatal("envelope of a removed key decrypted")
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	ctx := context.Background()
+	path := newKeyFile(t)
+	cipher := loadCipher(t, path)
+
+	index := func(c *FieldCipher, field, value string) string {
+		t.Helper()
+		result, err := c.BlindIndex(ctx, field, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	first := index(cipher, "customerId", "customer-1")
+	if index(cipher, "customerId", "customer-1") != first {
+		t.Fatal("blind index is not deterministic")
+	}
+	if index(loadCipher(t, path), "customerId", "customer-1") != first {
+		t.Fatal("blind index changed after reloading the same key file")
+	}
+	if index(cipher, "customerId", "customer-2") == first {
+		t.Fatal("different values share a blind index")
+	}
+	if index(cipher, "email", "customer-1") == first {
+		t.Fatal("equal values of different fields share a blind index")
+	}
+	if index(loadCipher(t, newKeyFile(t)), "customerId", "customer-1") == first {
+		t.Fatal("blind indexes of different keys are equal")
+	}
+}
@@ -0,0 +1,161 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const keySize = 32
+
+// keyFile is the JSON layout of a local key file. Old keys stay in the file
+// so data keys wrapped with them can still be unwrapped until rewrapped.
+type keyFile struct {
+	CurrentKeyID string            `json:"currentKeyId"`
+	Keys         map[string]string `json:"keys"`
+	IndexKey     string            `json:"indexKey"`
+}
+
+// LocalKeyProvider wraps data keys with AES-256-GCM keys read from a file
+type LocalKeyProvider struct {
+	currentKeyID string
+	keys         map[string][]byte
+	indexKey     []byte
+}
+
+func NewLocalKeyProviderFromFile(path string) (*LocalKeyProvider, error) {
+	file, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &LocalKeyProvider{
+		currentKeyID: file.CurrentKeyID,
+		keys:         make(map[string][]byte, len(file.Keys)),
+	}
+
+	for keyID, encoded := range file.Keys {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q in %s: %w", keyID, path, err)
+		}
+		provider.keys[keyID] = key
+	}
+
+	if _, ok := provider.keys[provider.currentKeyID]; !ok {
+		return nil, fmt.Errorf("current key %q is not in %s", provider.currentKeyID, path)
+	}
+
+	if provider.indexKey, err = decodeKey(file.IndexKey); err != nil {
+		return nil, fmt.Errorf("index key in %s: %w", path, err)
+	}
+
+	return provider, nil
+}
+
+func (p *LocalKeyProvider) CurrentKeyID() string {
+	return p.currentKeyID
+}
+
+func (p *LocalKeyProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := seal(p.keys[p.currentKeyID], dataKey, []byte(p.currentKeyID))
+	if err != nil {
+		return "", nil, err
+	}
+	return p.currentKeyID, wrapped, nil
+}
+
+func (p *LocalKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key encryption key %q", keyID)
+	}
+	return open(key, wrapped, []byte(keyID))
+}
+
+func (p *LocalKeyProvider) BlindIndexKey(ctx context.Context) ([]byte, error) {
+	return p.indexKey, nil
+}
+
+// GenerateKeyFile writes a new key file with one wrapping key and an index key.
+// It refuses to overwrite an existing file.
+func GenerateKeyFile(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+
+	keyID := newKeyID()
+	file := &keyFile{
+		CurrentKeyID: keyID,
+		Keys:         map[string]string{keyID: newEncodedKey()},
+		IndexKey:     newEncodedKey(),
+	}
+
+	return writeKeyFile(path, file)
+}
+
+// RotateKeyFile adds a wrapping key to the file and makes it current. Existing
+// data keys keep working; rewrap them to retire the old key.
+func RotateKeyFile(path string) (string, error) {
+	file, err := readKeyFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	keyID := newKeyID()
+	if _, exists := file.Keys[keyID]; exists {
+		return "", fmt.Errorf("key %q already exists, try again in a second", keyID)
+	}
+
+	file.Keys[keyID] = newEncodedKey()
+	file.CurrentKeyID = keyID
+
+	return keyID, writeKeyFile(path, file)
+}
+
+func readKeyFile(path string) (*keyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse key file %s: %w", path, err)
+	}
+	if file.Keys == nil {
+		file.Keys = map[string]string{}
+	}
+
+	return &file, nil
+}
+
+func writeKeyFile(path string, file *keyFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+func newEncodedKey() string {
+	return base64.StdEncoding.EncodeToString(randomBytes(keySize))
+}
+
+func newKeyID() string {
+	return "k" + time.Now().UTC().Format("20060102150405")
+}
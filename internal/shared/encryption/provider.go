@@ -0,0 +1,20 @@
+// Package encryption implements envelope encryption for sensitive fields:
+// every value is encrypted with its own data key, and the data key is wrapped
+// by a key encryption key held by a KeyProvider.
+package encryption
+
+import (
+	"context"
+)
+
+// KeyProvider holds the key encryption keys. Implementations may keep keys
+// locally or delegate wrapping to a KMS.
+type KeyProvider interface {
+	// CurrentKeyID is the key used to wrap new data keys
+	CurrentKeyID() string
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+	// BlindIndexKey is the HMAC key of blind indexes. It is not rotated with
+	// the wrapping keys, since changing it invalidates every index.
+	BlindIndexKey(ctx context.Context) ([]byte, error)
+}
@@ -77,6 +77,149 @@ func OperationNotFound() *AppError {
 	}
 }
 
+// Webhook errors
+func WebhookSubscriptionNotFound() *AppError {
+	return &AppError{
+		Code:    http.StatusNotFound,
+		Type:    "Not Found",
+		Message: "Webhook subscription not found!",
+	}
+}
+
+func WebhookDeliveryNotFound() *AppError {
+	return &AppError{
+		Code:    http.StatusNotFound,
+		Type:    "Not Found",
+		Message: "Webhook delivery not found!",
+	}
+}
+
+// API key errors
+func APIKeyNotFound() *AppError {
+	return &AppError{
+		Code:    http.StatusNotFound,
+		Type:    "Not Found",
+		Message: "API key not found!",
+	}
+}
+
+// Integrity errors
+func CheckpointNotFound() *AppError {
+	return &AppError{
+		Code:    http.StatusNotFound,
+		Type:    "Not Found",
+		Message: "Checkpoint not found!",
+	}
+}
+
+// Approval errors
+func ApprovalNotFound() *AppError {
+	return &AppError{
+		Code:    http.StatusNotFound,
+		Type:    "Not Found",
+		Message: "Approval not found!",
+	}
+}
+
+func ApprovalNotPending() *AppError {
+	return &AppError{
+		Code:    http.StatusConflict,
+		Type:    "Conflict",
+		Message: "Approval is no longer pending!",
+	}
+}
+
+func ApprovalSelfDecision() *AppError {
+	return &AppError{
+		Code:    http.StatusForbidden,
+		Type:    "Forbidden",
+		Message: "The requester cannot approve their own operation!",
+	}
+}
+
+// Risk errors
+func RiskRuleNotFound() *AppError {
+	return &AppError{
+		Code:    http.StatusNotFound,
+		Type:    "Not Found",
+		Message: "Risk rule not found!",
+	}
+}
+
+func RiskDenied(rules string) *AppError {
+	return &AppError{
+		Code:    http.StatusUnprocessableEntity,
+		Type:    "Unprocessable Entity",
+		Message: "Operation denied by risk rules: " + rules,
+	}
+}
+
+// Dead letter errors
+func DeadLetterNotFound() *AppError {
+	return &AppError{
+		Code:    http.StatusNotFound,
+		Type:    "Not Found",
+		Message: "Dead letter not found!",
+	}
+}
+
+func DeadLetterNotPending() *AppError {
+	return &AppError{
+		Code:    http.StatusConflict,
+		Type:    "Conflict",
+		Message: "Dead letter was already replayed or discarded!",
+	}
+}
+
+func DeadLetterNotReplayable() *AppError {
+	return &AppError{
+		Code:    http.StatusUnprocessableEntity,
+		Type:    "Unprocessable Entity",
+		Message: "Dead letter has no operation ID, so a replay could apply it twice. Check the wallet and discard it instead!",
+	}
+}
+
+// Request signature errors
+func SignatureMissing() *AppError {
+	return &AppError{
+		Code:    http.StatusUnauthorized,
+		Type:    "Signature Missing",
+		Message: "This endpoint requires a signed request",
+	}
+}
+
+func SignatureInvalid() *AppError {
+	return &AppError{
+		Code:    http.StatusUnauthorized,
+		Type:    "Signature Invalid",
+		Message: "Request signature does not match",
+	}
+}
+
+func SignatureKeyUnknown() *AppError {
+	return &AppError{
+		Code:    http.StatusUnauthorized,
+		Type:    "Signature Key Unknown",
+		Message: "Unknown signature key ID",
+	}
+}
+
+func SignatureExpired() *AppError {
+	return &AppError{
+		Code:    http.StatusUnauthorized,
+		Type:    "Signature Expired",
+		Message: "Request timestamp is outside the accepted window",
+	}
+}
+
+func SignatureReplayed() *AppError {
+	return &AppError{
+		Code:    http.StatusUnauthorized,
+		Type:    "Signature Replayed",
+		Message: "Request nonce was already used",
+	}
+}
+
 // Generic errors
 func InternalServerError(message string) *AppError {
 	return &AppError{
@@ -86,6 +229,22 @@ func InternalServerError(message string) *AppError {
 	}
 }
 
+func Unauthorized(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusUnauthorized,
+		Type:    "Unauthorized",
+		Message: message,
+	}
+}
+
+func Forbidden(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusForbidden,
+		Type:    "Forbidden",
+		Message: message,
+	}
+}
+
 func BadRequest(message string) *AppError {
 	return &AppError{
 		Code:    http.StatusBadRequest,
@@ -93,3 +252,19 @@ func BadRequest(message string) *AppError {
 		Message: message,
 	}
 }
+
+func PayloadTooLarge(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusRequestEntityTooLarge,
+		Type:    "Payload Too Large",
+		Message: message,
+	}
+}
+
+func TooManyRequests(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusTooManyRequests,
+		Type:    "Too Many Requests",
+		Message: message,
+	}
+}
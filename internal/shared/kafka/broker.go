@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"wallet-go/internal/shared/config"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Broker connects the Producer and the Consumer to a message bus: Kafka, or
+// the in-process MemoryBus for local and test runs. Both partition messages by
+// key, so the messages of a wallet are consumed in the order they were sent.
+type Broker interface {
+	Writer() MessageWriter
+	// Reader joins the consumer group groupID on topic
+	Reader(topic, groupID string) MessageReader
+	CreateTopics(topics []string, partitions int) error
+}
+
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	// Close waits for the messages being written
+	Close() error
+}
+
+type MessageReader interface {
+	// ReadMessage blocks until a message of the partitions assigned to the
+	// reader is available and commits it for the group
+	ReadMessage(ctx context.Context) (kafka.Message, error)
+	// Joined reports whether the reader has joined its group at least once
+	Joined() bool
+	Close() error
+}
+
+// NewBroker returns the broker selected by KAFKA_BACKEND
+func NewBroker(cfg config.KafkaConfig) (Broker, error) {
+	switch cfg.Backend {
+	case "kafka":
+		return &kafkaBroker{brokers: cfg.Brokers}, nil
+	case "memory":
+		return NewMemoryBus(cfg.Partitions), nil
+	default:
+		return nil, fmt.Errorf("unknown Kafka backend %q", cfg.Backend)
+	}
+}
+
+// kafkaBroker talks to a Kafka cluster
+type kafkaBroker struct {
+	brokers []string
+}
+
+func (b *kafkaBroker) Writer() MessageWriter {
+	return &kafka.Writer{
+		Addr: kafka.TCP(b.brokers...),
+		// The key is the wallet ID, so a wallet always maps to one partition
+		Balancer: &kafka.Hash{},
+	}
+}
+
+func (b *kafkaBroker) Reader(topic, groupID string) MessageReader {
+	return &kafkaReader{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:  b.brokers,
+			Topic:    topic,
+			GroupID:  groupID,
+			MinBytes: 10e3, // 10KB
+			MaxBytes: 10e6, // 10MB
+		}),
+	}
+}
+
+func (b *kafkaBroker) CreateTopics(topics []string, partitions int) error {
+	return CreateTopics(b.brokers, topics, partitions)
+}
+
+type kafkaReader struct {
+	reader *kafka.Reader
+
+	mu     sync.Mutex
+	joined bool
+}
+
+func (r *kafkaReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	return r.reader.ReadMessage(ctx)
+}
+
+func (r *kafkaReader) Joined() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Stats resets its counters, so a join is remembered once seen
+	if !r.joined && r.reader.Stats().Rebalances > 0 {
+		r.joined = true
+	}
+	return r.joined
+}
+
+func (r *kafkaReader) Close() error {
+	return r.reader.Close()
+}
@@ -3,141 +3,298 @@ package kafka
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
 	"time"
 
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/errors"
+	"wallet-go/internal/shared/metrics"
+	"wallet-go/internal/shared/requestinfo"
+	"wallet-go/internal/shared/tracing"
+
 	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// WalletService interface simplificada para quebrar dependência circular
+// WalletService interface simplificada para quebrar dependência circular.
+// operationID is the ID of the message; a message whose operation is already
+// recorded is skipped, so redeliveries and replays apply once.
 type WalletService interface {
-	DepositFromKafka(ctx context.Context, walletID uuid.UUID, amountInCents int64) error
-	WithdrawFromKafka(ctx context.Context, walletID uuid.UUID, amountInCents int64) error
-	TransferFromKafka(ctx context.Context, sourceID uuid.UUID, amountInCents int64, destinationID uuid.UUID) error
+	DepositFromKafka(ctx context.Context, operationID, walletID uuid.UUID, amountInCents int64) error
+	WithdrawFromKafka(ctx context.Context, operationID, walletID uuid.UUID, amountInCents int64) error
+	TransferFromKafka(ctx context.Context, operationID, sourceID uuid.UUID, amountInCents int64, destinationID uuid.UUID) error
 }
 
 // WalletKafkaTransactionMessage representa mensagem de transação simples
 type WalletKafkaTransactionMessage struct {
-	WalletID      uuid.UUID `json:"walletId"`
-	AmountInCents int64     `json:"amountInCents"`
+	// OperationID is assigned by the producer; messages sent before it
+	// existed have none and are not deduplicated
+	OperationID   uuid.UUID   `json:"operationId,omitempty"`
+	WalletID      uuid.UUID   `json:"walletId"`
+	AmountInCents int64       `json:"amountInCents"`
+	Actor         *auth.Actor `json:"actor,omitempty"`
 }
 
 // WalletKafkaTransactionTransferMessage representa mensagem de transferência
 type WalletKafkaTransactionTransferMessage struct {
-	WalletID            uuid.UUID `json:"walletId"`
-	AmountInCents       int64     `json:"amountInCents"`
-	WalletDestinationID uuid.UUID `json:"walletDestinationId"`
+	OperationID         uuid.UUID   `json:"operationId,omitempty"`
+	WalletID            uuid.UUID   `json:"walletId"`
+	AmountInCents       int64       `json:"amountInCents"`
+	WalletDestinationID uuid.UUID   `json:"walletDestinationId"`
+	Actor               *auth.Actor `json:"actor,omitempty"`
+}
+
+// FailedMessage is a consumed message that could not be processed
+type FailedMessage struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Err       error
+}
+
+// DeadLetterQueue keeps the messages that failed with an unexpected error, so
+// they can be inspected and replayed. Business rejections, such as
+// insufficient funds, are recorded as error operations and are not added.
+type DeadLetterQueue interface {
+	Add(ctx context.Context, message FailedMessage) error
 }
 
 type Consumer struct {
-	readers       map[string]*kafka.Reader
+	readers       []*topicReader
+	topics        config.KafkaTopics
 	walletService WalletService
+	deadLetters   DeadLetterQueue
+
+	mu      sync.Mutex
+	stop    context.CancelFunc
+	running sync.WaitGroup
 }
 
-func NewConsumer(brokers []string, groupID string) (*Consumer, error) {
-	readers := make(map[string]*kafka.Reader)
+// topicReader is one member of the consumer group reading a topic
+type topicReader struct {
+	topic  string
+	reader MessageReader
+}
 
-	// Create readers for each topic
-	topics := []string{"wallet.deposit", "wallet.withdraw", "wallet.transfer"}
-	for _, topic := range topics {
-		reader := kafka.NewReader(kafka.ReaderConfig{
-			Brokers:  brokers,
-			Topic:    topic,
-			GroupID:  groupID,
-			MinBytes: 10e3, // 10KB
-			MaxBytes: 10e6, // 10MB
-		})
-		readers[topic] = reader
+// NewConsumer creates concurrency readers of broker for each topic of cfg.
+// Messages of a partition are processed in order by the reader it is
+// assigned to.
+func NewConsumer(broker Broker, cfg config.KafkaConfig, concurrency int) (*Consumer, error) {
+	if concurrency < 1 {
+		return nil, fmt.Errorf("kafka consumer concurrency must be 1 or more, got %d", concurrency)
+	}
+
+	var readers []*topicReader
+	for _, topic := range []string{cfg.Topics.Deposit, cfg.Topics.Withdraw, cfg.Topics.Transfer} {
+		for i := 0; i < concurrency; i++ {
+			readers = append(readers, &topicReader{
+				topic:  topic,
+				reader: broker.Reader(topic, cfg.GroupID),
+			})
+		}
 	}
 
 	return &Consumer{
 		readers: readers,
+		topics:  cfg.Topics,
 	}, nil
 }
 
+// Assigned reports whether every reader has joined the consumer group at
+// least once. The readers join in the background after Start.
+func (c *Consumer) Assigned() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, r := range c.readers {
+		if !r.reader.Joined() {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *Consumer) SetWalletService(service WalletService) {
 	c.walletService = service
 }
 
-func (c *Consumer) StartConsumers() {
-	log.Println("Starting Kafka consumers...")
-	for topic, reader := range c.readers {
-		log.Printf("Starting consumer for topic: %s", topic)
-		go c.consumeMessages(topic, reader)
-		time.Sleep(100 * time.Millisecond) // Pequeno delay entre consumers
+// SetDeadLetterQueue keeps failed messages in queue; without it they are only
+// logged
+func (c *Consumer) SetDeadLetterQueue(queue DeadLetterQueue) {
+	c.deadLetters = queue
+}
+
+// Start reads every topic in the background until ctx is done or Shutdown
+// is called
+func (c *Consumer) Start(ctx context.Context) {
+	ctx, stop := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.stop = stop
+	c.mu.Unlock()
+
+	for _, r := range c.readers {
+		slog.Info("Starting Kafka consumer", "topic", r.topic)
+		c.running.Add(1)
+		go func(r *topicReader) {
+			defer c.running.Done()
+			c.consumeMessages(ctx, r.topic, r.reader)
+		}(r)
 	}
 }
 
-func (c *Consumer) consumeMessages(topic string, reader *kafka.Reader) {
-	for {
-		log.Printf("Waiting for message on topic: %s", topic)
+// Shutdown stops reading, waits for the messages being processed and closes
+// the readers. Messages still in flight when ctx is done are abandoned.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	if c.stop != nil {
+		c.stop()
+	}
+	c.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		c.running.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = fmt.Errorf("kafka consumers did not drain: %w", ctx.Err())
+	}
 
-		message, err := reader.ReadMessage(context.Background())
+	for _, r := range c.readers {
+		if closeErr := r.reader.Close(); closeErr != nil {
+			slog.Error("Error closing Kafka reader", "topic", r.topic, "error", closeErr)
+		}
+	}
+	return err
+}
+
+func (c *Consumer) consumeMessages(ctx context.Context, topic string, reader MessageReader) {
+	for {
+		message, err := reader.ReadMessage(ctx)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
-			log.Printf("Error reading message from topic %s: %v", topic, err)
+			slog.Error("Error reading Kafka message", "topic", topic, "error", err)
 			continue
 		}
 
-		log.Printf("Received message on topic %s: %s", topic, string(message.Value))
+		metrics.SetKafkaLag(topic, message.Partition, message.HighWaterMark-message.Offset-1)
 
-		if err := c.processMessage(topic, message.Value); err != nil {
-			log.Printf("Error processing message from topic %s: %v", topic, err)
-		} else {
-			log.Printf("Successfully processed message from topic %s", topic)
-		}
+		// The message was committed when read, so it is processed to the end
+		// even if shutdown starts meanwhile
+		start := time.Now()
+		err = c.handleMessage(topic, message)
+		metrics.ObserveKafkaMessage(topic, err, time.Since(start))
+	}
+}
+
+// handleMessage continues the trace and the request ID of the producer from
+// the message headers
+func (c *Consumer) handleMessage(topic string, message kafka.Message) error {
+	carrier := headerCarrier{headers: &message.Headers}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+	if requestID := carrier.Get(requestinfo.HeaderRequestID); requestID != "" {
+		ctx = requestinfo.WithInfo(ctx, &requestinfo.Info{RequestID: requestID})
+	}
+	ctx, span := tracing.Tracer().Start(ctx, "kafka.consume "+topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.source.name", topic),
+			attribute.Int("messaging.kafka.partition", message.Partition),
+			attribute.Int64("messaging.kafka.message.offset", message.Offset),
+		))
+
+	attrs := []any{"topic", topic, "partition", message.Partition, "offset", message.Offset}
+	slog.DebugContext(ctx, "Received Kafka message", attrs...)
+
+	err := c.processMessage(ctx, topic, message.Value)
+	tracing.End(span, err)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Error processing Kafka message", append(attrs, "error", err)...)
+		c.deadLetter(ctx, topic, message, err)
+	} else {
+		slog.InfoContext(ctx, "Processed Kafka message", attrs...)
 	}
+	return err
 }
 
-func (c *Consumer) processMessage(topic string, data []byte) error {
-	log.Printf("Processing message for topic: %s, data: %s", topic, string(data))
+// deadLetter adds the message to the dead letter queue unless the error is a
+// business rejection, which is final and already recorded
+func (c *Consumer) deadLetter(ctx context.Context, topic string, message kafka.Message, err error) {
+	if c.deadLetters == nil {
+		return
+	}
+	if appErr, ok := err.(*errors.AppError); ok && appErr.Code < http.StatusInternalServerError {
+		return
+	}
+
+	headers := make(map[string]string, len(message.Headers))
+	for _, header := range message.Headers {
+		headers[header.Key] = string(header.Value)
+	}
 
-	ctx := context.Background()
+	failed := FailedMessage{
+		Topic:     topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Key:       message.Key,
+		Value:     message.Value,
+		Headers:   headers,
+		Err:       err,
+	}
+	if addErr := c.deadLetters.Add(ctx, failed); addErr != nil {
+		slog.ErrorContext(ctx, "Failed to add Kafka message to the dead letter queue",
+			"topic", topic, "partition", message.Partition, "offset", message.Offset, "error", addErr)
+	}
+}
 
+func (c *Consumer) processMessage(ctx context.Context, topic string, data []byte) error {
 	switch topic {
-	case "wallet.deposit":
-		log.Println("Processing deposit...")
+	case c.topics.Deposit:
 		var msg WalletKafkaTransactionMessage
 		if err := json.Unmarshal(data, &msg); err != nil {
-			log.Printf("Error unmarshaling deposit message: %v", err)
 			return err
 		}
-		log.Printf("Calling DepositFromKafka with walletID: %s, amount: %d", msg.WalletID, msg.AmountInCents)
-		return c.walletService.DepositFromKafka(ctx, msg.WalletID, msg.AmountInCents)
+		slog.DebugContext(ctx, "Processing deposit", "operation_id", msg.OperationID, "wallet_id", msg.WalletID, "amount_in_cents", msg.AmountInCents)
+		return c.walletService.DepositFromKafka(auth.WithActor(ctx, msg.Actor), msg.OperationID, msg.WalletID, msg.AmountInCents)
 
-	case "wallet.withdraw":
-		log.Println("Processing withdraw...")
+	case c.topics.Withdraw:
 		var msg WalletKafkaTransactionMessage
 		if err := json.Unmarshal(data, &msg); err != nil {
-			log.Printf("Error unmarshaling withdraw message: %v", err)
 			return err
 		}
-		log.Printf("Calling WithdrawFromKafka with walletID: %s, amount: %d", msg.WalletID, msg.AmountInCents)
-		return c.walletService.WithdrawFromKafka(ctx, msg.WalletID, msg.AmountInCents)
+		slog.DebugContext(ctx, "Processing withdraw", "operation_id", msg.OperationID, "wallet_id", msg.WalletID, "amount_in_cents", msg.AmountInCents)
+		return c.walletService.WithdrawFromKafka(auth.WithActor(ctx, msg.Actor), msg.OperationID, msg.WalletID, msg.AmountInCents)
 
-	case "wallet.transfer":
-		log.Println("Processing transfer...")
+	case c.topics.Transfer:
 		var msg WalletKafkaTransactionTransferMessage
 		if err := json.Unmarshal(data, &msg); err != nil {
-			log.Printf("Error unmarshaling transfer message: %v", err)
 			return err
 		}
-		log.Printf("Calling TransferFromKafka with sourceID: %s, amount: %d, destinationID: %s",
-			msg.WalletID, msg.AmountInCents, msg.WalletDestinationID)
-		return c.walletService.TransferFromKafka(ctx, msg.WalletID, msg.AmountInCents, msg.WalletDestinationID)
+		slog.DebugContext(ctx, "Processing transfer", "operation_id", msg.OperationID, "wallet_id", msg.WalletID,
+			"amount_in_cents", msg.AmountInCents, "destination_wallet_id", msg.WalletDestinationID)
+		return c.walletService.TransferFromKafka(auth.WithActor(ctx, msg.Actor), msg.OperationID, msg.WalletID, msg.AmountInCents, msg.WalletDestinationID)
 
 	default:
-		log.Printf("Unknown topic: %s", topic)
+		slog.WarnContext(ctx, "Unknown Kafka topic", "topic", topic)
 	}
 
 	return nil
 }
-
-func (c *Consumer) Close() error {
-	for _, reader := range c.readers {
-		if err := reader.Close(); err != nil {
-			log.Printf("Error closing kafka reader: %v", err)
-		}
-	}
-	return nil
-}
@@ -0,0 +1,38 @@
+package kafka
+
+import (
+	"github.com/segmentio/kafka-go"
+)
+
+// headerCarrier adapts Kafka message headers to the OpenTelemetry
+// TextMapCarrier, so trace context travels with the message
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, header := range *c.headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, header := range *c.headers {
+		if header.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, len(*c.headers))
+	for i, header := range *c.headers {
+		keys[i] = header.Key
+	}
+	return keys
+}
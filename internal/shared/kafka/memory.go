@@ -0,0 +1,239 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// MemoryBus is an in-process stand-in for Kafka with the same semantics as
+// the Consumer sees them: topics are split in partitions by the hash of the
+// key, each partition of a group belongs to one reader, which reads it in
+// order, and a message is committed when it is read. Messages only reach
+// readers of the same process and are lost when it exits.
+type MemoryBus struct {
+	mu         sync.Mutex
+	partitions int
+	topics     map[string]*memoryTopic
+	balancer   kafka.Hash
+	// changed is closed and replaced whenever a message is written or a
+	// partition moves to another reader, waking the waiting readers
+	changed chan struct{}
+}
+
+type memoryTopic struct {
+	partitions [][]kafka.Message
+	groups     map[string]*memoryGroup
+}
+
+// memoryGroup is a consumer group of a topic. Partition p belongs to
+// members[p % len(members)].
+type memoryGroup struct {
+	offsets []int64
+	members []*memoryReader
+}
+
+func NewMemoryBus(partitions int) *MemoryBus {
+	if partitions < 1 {
+		partitions = 1
+	}
+	return &MemoryBus{
+		partitions: partitions,
+		topics:     make(map[string]*memoryTopic),
+		changed:    make(chan struct{}),
+	}
+}
+
+func (b *MemoryBus) Writer() MessageWriter {
+	return &memoryWriter{bus: b}
+}
+
+func (b *MemoryBus) Reader(topic, groupID string) MessageReader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	reader := &memoryReader{bus: b, topic: topic, groupID: groupID, closed: make(chan struct{})}
+	group := b.group(topic, groupID)
+	group.members = append(group.members, reader)
+	b.notify()
+	return reader
+}
+
+// CreateTopics creates the missing topics; existing topics keep their
+// partitions, as in Kafka
+func (b *MemoryBus) CreateTopics(topics []string, partitions int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, name := range topics {
+		if _, ok := b.topics[name]; !ok {
+			b.topics[name] = &memoryTopic{
+				partitions: make([][]kafka.Message, partitions),
+				groups:     make(map[string]*memoryGroup),
+			}
+		}
+	}
+	return nil
+}
+
+// topic returns the topic, created with the default partitions when missing.
+// The caller holds mu.
+func (b *MemoryBus) topic(name string) *memoryTopic {
+	topic, ok := b.topics[name]
+	if !ok {
+		topic = &memoryTopic{
+			partitions: make([][]kafka.Message, b.partitions),
+			groups:     make(map[string]*memoryGroup),
+		}
+		b.topics[name] = topic
+	}
+	return topic
+}
+
+// group returns the consumer group of a topic, which starts at the first
+// message. The caller holds mu.
+func (b *MemoryBus) group(topicName, groupID string) *memoryGroup {
+	topic := b.topic(topicName)
+	group, ok := topic.groups[groupID]
+	if !ok {
+		group = &memoryGroup{offsets: make([]int64, len(topic.partitions))}
+		topic.groups[groupID] = group
+	}
+	return group
+}
+
+// notify wakes the readers waiting for a change. The caller holds mu.
+func (b *MemoryBus) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+type memoryWriter struct {
+	bus *MemoryBus
+}
+
+func (w *memoryWriter) WriteMessages(ctx context.Context, messages ...kafka.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b := w.bus
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, message := range messages {
+		if message.Topic == "" {
+			return fmt.Errorf("kafka message without topic")
+		}
+		topic := b.topic(message.Topic)
+
+		ids := make([]int, len(topic.partitions))
+		for i := range ids {
+			ids[i] = i
+		}
+		partition := b.balancer.Balance(message, ids...)
+
+		// Readers get their own copy, as if it came over the network
+		stored := kafka.Message{
+			Topic:     message.Topic,
+			Partition: partition,
+			Offset:    int64(len(topic.partitions[partition])),
+			Key:       append([]byte(nil), message.Key...),
+			Value:     append([]byte(nil), message.Value...),
+			Headers:   append([]kafka.Header(nil), message.Headers...),
+			Time:      time.Now(),
+		}
+		topic.partitions[partition] = append(topic.partitions[partition], stored)
+	}
+
+	b.notify()
+	return nil
+}
+
+// Close returns at once: writes are synchronous
+func (w *memoryWriter) Close() error {
+	return nil
+}
+
+type memoryReader struct {
+	bus     *MemoryBus
+	topic   string
+	groupID string
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (r *memoryReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		b := r.bus
+		b.mu.Lock()
+		message, ok := r.next()
+		changed := b.changed
+		b.mu.Unlock()
+
+		if ok {
+			return message, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		case <-r.closed:
+			return kafka.Message{}, io.EOF
+		case <-changed:
+		}
+	}
+}
+
+// next takes the first uncommitted message of the partitions assigned to the
+// reader. The caller holds mu.
+func (r *memoryReader) next() (kafka.Message, bool) {
+	topic := r.bus.topic(r.topic)
+	group := r.bus.group(r.topic, r.groupID)
+
+	for p, messages := range topic.partitions {
+		if len(group.members) == 0 || group.members[p%len(group.members)] != r {
+			continue
+		}
+		offset := group.offsets[p]
+		if offset >= int64(len(messages)) {
+			continue
+		}
+
+		group.offsets[p]++
+		message := messages[offset]
+		message.HighWaterMark = int64(len(messages))
+		return message, true
+	}
+	return kafka.Message{}, false
+}
+
+// Joined is true from the start: the reader joins its group when created
+func (r *memoryReader) Joined() bool {
+	return true
+}
+
+// Close leaves the group, so its partitions move to the other readers
+func (r *memoryReader) Close() error {
+	r.closeOnce.Do(func() {
+		b := r.bus
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		group := b.group(r.topic, r.groupID)
+		for i, member := range group.members {
+			if member == r {
+				group.members = append(group.members[:i], group.members[i+1:]...)
+				break
+			}
+		}
+		close(r.closed)
+		b.notify()
+	})
+	return nil
+}
@@ -0,0 +1,148 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestMemoryBusKeepsKeyOrder(t *testing.T) {
+	bus := NewMemoryBus(4)
+	writeN(t, bus, "deposit", 3, 20)
+
+	// Two readers share the partitions; each key must still come in order
+	readers := []MessageReader{bus.Reader("deposit", "group"), bus.Reader("deposit", "group")}
+	next := map[string]int{}
+	partitionOf := map[string]int{}
+	for _, reader := range readers {
+		for {
+			message, ok := tryRead(t, reader)
+			if !ok {
+				break
+			}
+			key := string(message.Key)
+			if want := strconv.Itoa(next[key]); string(message.Value) != want {
+				t.Fatalf("key %s: got message %s, want %s", key, message.Value, want)
+			}
+			next[key]++
+
+			if partition, seen := partitionOf[key]; seen && partition != message.Partition {
+				t.Fatalf("key %s moved from partition %d to %d", key, partition, message.Partition)
+			}
+			partitionOf[key] = message.Partition
+		}
+	}
+
+	for key, count := range next {
+		if count != 20 {
+			t.Errorf("key %s: read %d messages, want 20", key, count)
+		}
+	}
+	if len(next) != 3 {
+		t.Errorf("read %d keys, want 3", len(next))
+	}
+}
+
+func TestMemoryBusGroups(t *testing.T) {
+	bus := NewMemoryBus(2)
+	writeN(t, bus, "deposit", 4, 5)
+
+	// Each group reads every message once; a message is committed when read
+	first := drain(t, bus.Reader("deposit", "first"))
+	second := bus.Reader("deposit", "second")
+	secondCount := len(drain(t, second))
+	if len(first) != 20 || secondCount != 20 {
+		t.Fatalf("groups read %d and %d messages, want 20 each", len(first), secondCount)
+	}
+
+	writeN(t, bus, "deposit", 1, 1)
+	if again := drain(t, second); len(again) != 1 {
+		t.Fatalf("group read %d messages after a new write, want only the new one", len(again))
+	}
+}
+
+func TestMemoryBusRebalancesOnClose(t *testing.T) {
+	bus := NewMemoryBus(2)
+	leaving := bus.Reader("deposit", "group")
+	staying := bus.Reader("deposit", "group")
+	writeN(t, bus, "deposit", 8, 1)
+
+	// The partitions of the closed reader move to the one that stays
+	read := len(drain(t, staying))
+	if err := leaving.Close(); err != nil {
+		t.Fatal(err)
+	}
+	read += len(drain(t, staying))
+	if read != 8 {
+		t.Fatalf("remaining reader read %d messages, want 8", read)
+	}
+
+	if _, err := leaving.ReadMessage(context.Background()); err == nil {
+		t.Fatal("a closed reader returned a message")
+	}
+}
+
+func TestMemoryBusReadWaitsForWrite(t *testing.T) {
+	bus := NewMemoryBus(1)
+	reader := bus.Reader("deposit", "group")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		bus.Writer().WriteMessages(context.Background(), kafka.Message{Topic: "deposit", Key: []byte("a"), Value: []byte("late")})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	message, err := reader.ReadMessage(ctx)
+	if err != nil || string(message.Value) != "late" {
+		t.Fatalf("ReadMessage = %q, %v, want the late message", message.Value, err)
+	}
+}
+
+// writeN writes count messages for each of keys keys; values count up per key
+func writeN(t *testing.T, bus *MemoryBus, topic string, keys, count int) {
+	t.Helper()
+
+	writer := bus.Writer()
+	for i := 0; i < count; i++ {
+		for k := 0; k < keys; k++ {
+			message := kafka.Message{Topic: topic, Key: []byte(fmt.Sprintf("wallet-%d", k)), Value: []byte(strconv.Itoa(i))}
+			if err := writer.WriteMessages(context.Background(), message); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// tryRead returns the next message, or false when none is ready
+func tryRead(t *testing.T, reader MessageReader) (kafka.Message, bool) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	message, err := reader.ReadMessage(ctx)
+	if err == context.DeadlineExceeded {
+		return kafka.Message{}, false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return message, true
+}
+
+func drain(t *testing.T, reader MessageReader) []kafka.Message {
+	t.Helper()
+
+	var messages []kafka.Message
+	for {
+		message, ok := tryRead(t, reader)
+		if !ok {
+			return messages
+		}
+		messages = append(messages, message)
+	}
+}
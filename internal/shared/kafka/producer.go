@@ -3,48 +3,74 @@ package kafka
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
+
+	"wallet-go/internal/shared/metrics"
+	"wallet-go/internal/shared/requestinfo"
+	"wallet-go/internal/shared/tracing"
 
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Producer struct {
-	writer *kafka.Writer
+	writer MessageWriter
 }
 
-func NewProducer(brokers []string) (*Producer, error) {
-	writer := &kafka.Writer{
-		Addr:     kafka.TCP(brokers...),
-		Balancer: &kafka.LeastBytes{},
-	}
-
+func NewProducer(broker Broker) (*Producer, error) {
 	return &Producer{
-		writer: writer,
+		writer: broker.Writer(),
 	}, nil
 }
 
+// SendMessage publishes value as JSON. The trace context and request ID of ctx
+// are injected into the message headers so the consumer continues the same
+// trace and logs under the same request ID.
 func (p *Producer) SendMessage(ctx context.Context, topic string, key string, value interface{}) error {
 	data, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
+	return p.SendRaw(ctx, topic, key, data)
+}
+
+// SendRaw publishes an already encoded value, such as a message replayed from
+// the dead letter queue, with the same headers as SendMessage
+func (p *Producer) SendRaw(ctx context.Context, topic string, key string, value []byte) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "kafka.produce "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+			attribute.String("messaging.kafka.message.key", key),
+		))
+	defer func() { tracing.End(span, err) }()
 
 	message := kafka.Message{
 		Topic: topic,
 		Key:   []byte(key),
-		Value: data,
+		Value: value,
+	}
+	carrier := headerCarrier{headers: &message.Headers}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if requestID := requestinfo.FromContext(ctx).RequestID; requestID != "" {
+		carrier.Set(requestinfo.HeaderRequestID, requestID)
 	}
 
 	err = p.writer.WriteMessages(ctx, message)
+	metrics.ObserveKafkaProduce(topic, err)
 	if err != nil {
-		log.Printf("Failed to send message to topic %s: %v", topic, err)
+		slog.ErrorContext(ctx, "Failed to send Kafka message", "topic", topic, "error", err)
 		return err
 	}
 
-	log.Printf("Message successfully sent to topic: %s", topic)
+	slog.DebugContext(ctx, "Sent Kafka message", "topic", topic, "key", key)
 	return nil
 }
 
+// Close waits for the messages being written and closes the connections
 func (p *Producer) Close() error {
 	return p.writer.Close()
 }
@@ -2,15 +2,18 @@ package kafka
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 )
 
 // CreateTopics cria os tópicos Kafka necessários para a aplicação
-func CreateTopics(brokers []string, topics []string) error {
-	conn, err := kafka.Dial("tcp", brokers[0])
+func CreateTopics(brokers []string, topics []string, partitions int) error {
+	conn, err := dialAny(brokers)
 	if err != nil {
 		return err
 	}
@@ -21,7 +24,7 @@ func CreateTopics(brokers []string, topics []string) error {
 		return err
 	}
 
-	controllerConn, err := kafka.Dial("tcp", controller.Host+":"+string(rune(controller.Port)))
+	controllerConn, err := kafka.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
 	if err != nil {
 		return err
 	}
@@ -31,7 +34,7 @@ func CreateTopics(brokers []string, topics []string) error {
 	for i, topic := range topics {
 		topicConfigs[i] = kafka.TopicConfig{
 			Topic:             topic,
-			NumPartitions:     1,
+			NumPartitions:     partitions,
 			ReplicationFactor: 1,
 		}
 	}
@@ -42,22 +45,37 @@ func CreateTopics(brokers []string, topics []string) error {
 // WaitForKafka aguarda o Kafka ficar disponível com retry
 func WaitForKafka(brokers []string, maxRetries int) error {
 	for i := 0; i < maxRetries; i++ {
-		conn, err := kafka.DialContext(context.Background(), "tcp", brokers[0])
+		conn, err := dialAny(brokers)
 		if err == nil {
 			conn.Close()
-			log.Println("Kafka is ready")
+			slog.Info("Kafka is ready")
 			return nil
 		}
 
-		log.Printf("Waiting for Kafka... attempt %d/%d", i+1, maxRetries)
+		slog.Info("Waiting for Kafka", "attempt", i+1, "max_attempts", maxRetries)
 		time.Sleep(2 * time.Second)
 	}
 
 	// Última tentativa
-	conn, err := kafka.Dial("tcp", brokers[0])
+	conn, err := dialAny(brokers)
 	if err != nil {
 		return err
 	}
 	conn.Close()
 	return nil
 }
+
+// dialAny connects to the first reachable broker
+func dialAny(brokers []string) (*kafka.Conn, error) {
+	dialer := &kafka.Dialer{Timeout: 10 * time.Second}
+
+	err := fmt.Errorf("no Kafka brokers configured")
+	for _, broker := range brokers {
+		var conn *kafka.Conn
+		conn, err = dialer.DialContext(context.Background(), "tcp", broker)
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
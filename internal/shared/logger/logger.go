@@ -0,0 +1,100 @@
+// Package logger configures the structured slog logger of the service.
+// Records logged with a context carry its request ID and trace IDs.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"wallet-go/internal/shared/config"
+	"wallet-go/internal/shared/requestinfo"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// level is shared by the handlers installed by Setup, so SetLevel applies to
+// loggers that were already derived from the default one
+var level = new(slog.LevelVar)
+
+// Setup installs the default logger. The standard log package writes through
+// it as well, so output stays in one format.
+func Setup(cfg config.LogConfig) error {
+	handler, err := newHandler(os.Stdout, cfg)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+func newHandler(w io.Writer, cfg config.LogConfig) (slog.Handler, error) {
+	if err := SetLevel(cfg.Level); err != nil {
+		return nil, err
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch cfg.Format {
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("unknown LOG_FORMAT %q, expected json or text", cfg.Format)
+	}
+
+	return contextHandler{Handler: handler}, nil
+}
+
+// SetLevel changes the minimum level of the default logger
+func SetLevel(value string) error {
+	parsed, err := ParseLevel(value)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+	return nil
+}
+
+// ParseLevel accepts debug, info, warn and error
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToUpper(value))); err != nil {
+		return 0, fmt.Errorf("unknown LOG_LEVEL %q, expected debug, info, warn or error", value)
+	}
+	return level, nil
+}
+
+// contextHandler adds the correlation attributes of the record context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := requestinfo.FromContext(ctx).RequestID; requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
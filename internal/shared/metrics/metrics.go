@@ -0,0 +1,163 @@
+// Package metrics holds the Prometheus collectors of the service. Collectors
+// are registered on Registry, which is served by Handler on /metrics.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wallet"
+
+// Registry holds every collector of the service plus the Go runtime and
+// process collectors
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// HTTP
+var (
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests being served.",
+	})
+)
+
+// Kafka
+var (
+	kafkaMessages = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_processed_total",
+		Help:      "Consumed messages by topic and result (success or failure).",
+	}, []string{"topic", "result"})
+
+	kafkaProcessing = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "message_processing_seconds",
+		Help:      "Time spent processing a consumed message.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"topic"})
+
+	kafkaLag = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "consumer_lag",
+		Help:      "Messages behind the partition high watermark after the last read.",
+	}, []string{"topic", "partition"})
+
+	kafkaProduced = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_produced_total",
+		Help:      "Produced messages by topic and result (success or failure).",
+	}, []string{"topic", "result"})
+)
+
+// Wallet
+var (
+	lockWait = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "wallet",
+		Name:      "lock_wait_seconds",
+		Help:      "Time spent waiting for a wallet lock.",
+		Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5},
+	})
+
+	operations = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "wallet",
+		Name:      "operations_total",
+		Help:      "Recorded operations by type and status.",
+	}, []string{"type", "status"})
+
+	rejections = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "wallet",
+		Name:      "rejections_total",
+		Help:      "Rejected operations by type and reason.",
+	}, []string{"type", "reason"})
+)
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// HTTPRequestStarted tracks an in-flight request; call the returned function
+// when it finishes
+func HTTPRequestStarted() func() {
+	httpInFlight.Inc()
+	return httpInFlight.Dec
+}
+
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+func ObserveKafkaMessage(topic string, err error, duration time.Duration) {
+	kafkaMessages.WithLabelValues(topic, result(err)).Inc()
+	kafkaProcessing.WithLabelValues(topic).Observe(duration.Seconds())
+}
+
+func SetKafkaLag(topic string, partition int, lag int64) {
+	if lag < 0 {
+		lag = 0
+	}
+	kafkaLag.WithLabelValues(topic, strconv.Itoa(partition)).Set(float64(lag))
+}
+
+func ObserveKafkaProduce(topic string, err error) {
+	kafkaProduced.WithLabelValues(topic, result(err)).Inc()
+}
+
+func ObserveLockWait(duration time.Duration) {
+	lockWait.Observe(duration.Seconds())
+}
+
+func ObserveOperation(opType, status string) {
+	operations.WithLabelValues(opType, status).Inc()
+}
+
+func ObserveRejection(opType, reason string) {
+	rejections.WithLabelValues(opType, reason).Inc()
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WalletIDExtractor returns the wallets a request acts on. Malformed IDs are
+// skipped so the handler can answer with its usual 400.
+type WalletIDExtractor func(c *gin.Context) []uuid.UUID
+
+func WalletIDFromParam(name string) WalletIDExtractor {
+	return func(c *gin.Context) []uuid.UUID {
+		walletID, err := uuid.Parse(c.Param(name))
+		if err != nil {
+			return nil
+		}
+		return []uuid.UUID{walletID}
+	}
+}
+
+func WalletIDsFromQuery(name string) WalletIDExtractor {
+	return func(c *gin.Context) []uuid.UUID {
+		var walletIDs []uuid.UUID
+		for _, value := range c.QueryArray(name) {
+			if walletID, err := uuid.Parse(value); err == nil {
+				walletIDs = append(walletIDs, walletID)
+			}
+		}
+		return walletIDs
+	}
+}
+
+// APIKeyHeader carries service-to-service credentials
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuth resolves the X-API-Key header to a principal. Requests without
+// the header are passed on untouched so Authenticate can check a bearer token.
+func APIKeyAuth(resolver auth.APIKeyResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		principal, err := resolver.ResolveAPIKey(c.Request.Context(), key)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		setPrincipal(c, principal)
+		c.Next()
+	}
+}
+
+// Authenticate validates the bearer token and stores the principal in both
+// the gin context and the request context. Requests already authenticated by
+// APIKeyAuth pass through; a nil verifier only accepts API keys.
+func Authenticate(verifier *auth.JWTVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if auth.PrincipalFromContext(c.Request.Context()) != nil {
+			c.Next()
+			return
+		}
+
+		if verifier == nil {
+			abortWithError(c, errors.Unauthorized("Missing API key"))
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			abortWithError(c, errors.Unauthorized("Missing bearer token"))
+			return
+		}
+
+		principal, err := verifier.Verify(token)
+		if err != nil {
+			abortWithError(c, errors.Unauthorized("Invalid token"))
+			return
+		}
+
+		setPrincipal(c, principal)
+		c.Next()
+	}
+}
+
+// RequireScope rejects principals without the scope; admins always pass
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.PrincipalFromContext(c.Request.Context())
+		if principal == nil {
+			abortWithError(c, errors.Unauthorized("Authentication required"))
+			return
+		}
+
+		if !principal.IsAdmin() && !principal.HasScope(scope) {
+			abortWithError(c, errors.Forbidden("Missing scope "+scope))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission rejects principals whose roles do not grant the permission
+func RequirePermission(permission auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.PrincipalFromContext(c.Request.Context())
+		if principal == nil {
+			abortWithError(c, errors.Unauthorized("Authentication required"))
+			return
+		}
+
+		if !principal.Can(permission) {
+			abortWithError(c, errors.Forbidden("Missing permission "+string(permission)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireWalletAccess enforces that the principal owns every wallet returned by
+// the extractor, unless it has the admin scope or one of the permissions
+func RequireWalletAccess(authorizer *auth.Authorizer, scope string, extract WalletIDExtractor, permissions ...auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		principal := auth.PrincipalFromContext(ctx)
+
+		if principal != nil {
+			for _, permission := range permissions {
+				if principal.Can(permission) {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		if err := authorizer.AuthorizeWallets(ctx, principal, scope, extract(c)...); err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func setPrincipal(c *gin.Context, principal *auth.Principal) {
+	c.Set(auth.GinContextKey, principal)
+	c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+}
+
+func abortWithError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.AbortWithStatusJSON(appErr.Code, appErr)
+		return
+	}
+	appErr := errors.InternalServerError("Internal server error")
+	c.AbortWithStatusJSON(http.StatusInternalServerError, appErr)
+}
@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 
 	"wallet-go/internal/shared/errors"
 	"github.com/gin-gonic/gin"
@@ -14,7 +14,7 @@ func ErrorHandler() gin.HandlerFunc {
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last()
 
-			log.Printf("Error processing request: %v", err)
+			slog.ErrorContext(c.Request.Context(), "Error processing request", "error", err.Err)
 
 			switch e := err.Err.(type) {
 			case *errors.AppError:
@@ -31,7 +31,7 @@ func ErrorHandler() gin.HandlerFunc {
 
 func Recovery() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		log.Printf("Panic recovered: %v", recovered)
+		slog.ErrorContext(c.Request.Context(), "Panic recovered", "panic", recovered)
 
 		appErr := errors.InternalServerError("Internal server error")
 		c.JSON(appErr.Code, appErr)
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"time"
+
+	"wallet-go/internal/shared/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that hit no route, so arbitrary paths do not
+// create new series
+const unmatchedRoute = "unmatched"
+
+// Metrics records latency and throughput per route template
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		done := metrics.HTTPRequestStarted()
+		defer done()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/errors"
+	"wallet-go/internal/shared/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit takes a token from one bucket per dimension of the request: the
+// API key or customer of the principal (or the client IP when there is none)
+// and the wallet in the :id parameter. The request is rejected with 429 when
+// any bucket is empty; headers report the most restrictive bucket.
+func RateLimit(backend ratelimit.Backend, policy *ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			c.Next()
+			return
+		}
+
+		limit := policy.LimitFor(c.Request.Method, route)
+		prefix := c.Request.Method + " " + route + "|"
+
+		var tightest *ratelimit.Result
+		for _, key := range rateLimitKeys(c) {
+			result, err := backend.Take(c.Request.Context(), prefix+key, limit)
+			if err != nil {
+				// Fail open: an unavailable backend must not take the API down
+				slog.ErrorContext(c.Request.Context(), "Rate limit backend error", "error", err)
+				c.Next()
+				return
+			}
+
+			if tightest == nil || !result.Allowed || (tightest.Allowed && result.Remaining < tightest.Remaining) {
+				tightest = &result
+			}
+			if !result.Allowed {
+				break
+			}
+		}
+
+		if tightest != nil {
+			setRateLimitHeaders(c, *tightest)
+			if !tightest.Allowed {
+				abortWithError(c, errors.TooManyRequests("Rate limit exceeded, retry later"))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitKeys(c *gin.Context) []string {
+	var keys []string
+
+	principal := auth.PrincipalFromContext(c.Request.Context())
+	switch {
+	case principal == nil:
+		keys = append(keys, string(ratelimit.DimensionIP)+":"+c.ClientIP())
+	case principal.Kind == auth.PrincipalKindService:
+		keys = append(keys, string(ratelimit.DimensionAPIKey)+":"+principal.Subject)
+	case principal.CustomerID != "":
+		keys = append(keys, string(ratelimit.DimensionCustomer)+":"+principal.CustomerID)
+	default:
+		keys = append(keys, string(ratelimit.DimensionCustomer)+":"+principal.Subject)
+	}
+
+	if walletID := c.Param("id"); walletID != "" && strings.HasPrefix(c.FullPath(), "/wallet/:id") {
+		keys = append(keys, string(ratelimit.DimensionWallet)+":"+walletID)
+	}
+
+	return keys
+}
+
+func setRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter.Seconds())))
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter.Seconds())))
+	}
+}
+
+func ceilSeconds(seconds float64) int {
+	return int(math.Max(1, math.Ceil(seconds)))
+}
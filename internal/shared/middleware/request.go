@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"wallet-go/internal/shared/requestinfo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestInfo stores the request ID, client IP and user agent in the request
+// context. The X-Request-ID header is reused when the client sends one and
+// generated otherwise, and is always echoed in the response.
+func RequestInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestinfo.HeaderRequestID)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.NewString()
+		}
+
+		info := &requestinfo.Info{
+			RequestID: requestID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+
+		c.Header(requestinfo.HeaderRequestID, requestID)
+		c.Request = c.Request.WithContext(requestinfo.WithInfo(c.Request.Context(), info))
+		c.Next()
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"wallet-go/internal/shared/errors"
+	"wallet-go/internal/shared/signing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxSignedBodySize = 1 << 20
+
+// RequireSignature verifies the HMAC request signature of the route. Unsigned
+// requests pass unless required is set; a signed request is always verified.
+// The timestamp must be within window of the server clock and the nonce is
+// remembered for twice the window, so a captured request cannot be replayed.
+func RequireSignature(keys map[string][]byte, nonces signing.NonceStore, window time.Duration, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyID := c.GetHeader(signing.HeaderKeyID)
+		signature := c.GetHeader(signing.HeaderSignature)
+		rawTimestamp := c.GetHeader(signing.HeaderTimestamp)
+		nonce := c.GetHeader(signing.HeaderNonce)
+
+		if keyID == "" && signature == "" {
+			if required {
+				abortWithError(c, errors.SignatureMissing())
+				return
+			}
+			c.Next()
+			return
+		}
+		if keyID == "" || signature == "" || rawTimestamp == "" || nonce == "" {
+			abortWithError(c, errors.SignatureMissing())
+			return
+		}
+
+		secret, ok := keys[keyID]
+		if !ok {
+			abortWithError(c, errors.SignatureKeyUnknown())
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(rawTimestamp, 10, 64)
+		if err != nil {
+			abortWithError(c, errors.SignatureExpired())
+			return
+		}
+		skew := time.Since(time.Unix(timestamp, 0))
+		if skew > window || skew < -window {
+			abortWithError(c, errors.SignatureExpired())
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSignedBodySize+1))
+		if err != nil {
+			abortWithError(c, errors.BadRequest("Could not read request body"))
+			return
+		}
+		if len(body) > maxSignedBodySize {
+			abortWithError(c, errors.PayloadTooLarge("Request body too large"))
+			return
+		}
+		// The handler binds the body again
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		canonical := signing.Canonical(c.Request.Method, c.Request.URL.RequestURI(), timestamp, nonce, body)
+		if !signing.Verify(secret, canonical, signature) {
+			abortWithError(c, errors.SignatureInvalid())
+			return
+		}
+
+		// Only verified requests consume the nonce
+		fresh, err := nonces.Remember(c.Request.Context(), keyID+":"+nonce, 2*window)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error storing request nonce", "error", err)
+			abortWithError(c, errors.InternalServerError("Could not verify request signature"))
+			return
+		}
+		if !fresh {
+			abortWithError(c, errors.SignatureReplayed())
+			return
+		}
+
+		c.Next()
+	}
+}
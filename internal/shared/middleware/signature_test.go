@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"wallet-go/internal/shared/errors"
+	"wallet-go/internal/shared/signing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var signingSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestRequireSignature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.POST("/wallet/:id/withdraw",
+		RequireSignature(map[string][]byte{"billing": signingSecret}, signing.NewMemoryNonceStore(), 5*time.Minute, true),
+		func(c *gin.Context) {
+			// The handler still reads the verified body
+			body, _ := io.ReadAll(c.Request.Body)
+			c.String(http.StatusOK, string(body))
+		})
+
+	body := []byte(`{"amountInCents":100}`)
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name    string
+		request *http.Request
+		status  int
+		error   string
+	}{
+		{"unsigned on a required route", httptest.NewRequest(http.MethodPost, "/wallet/1/withdraw", bytes.NewReader(body)), http.StatusUnauthorized, "Signature Missing"},
+		{"signed", signedRequest("billing", signingSecret, now, "nonce-1", body), http.StatusOK, ""},
+		{"replayed nonce", signedRequest("billing", signingSecret, now, "nonce-1", body), http.StatusUnauthorized, "Signature Replayed"},
+		{"unknown key", signedRequest("other", signingSecret, now, "nonce-2", body), http.StatusUnauthorized, "Signature Key Unknown"},
+		{"wrong secret", signedRequest("billing", []byte("another secret of thirty-two b."), now, "nonce-3", body), http.StatusUnauthorized, "Signature Invalid"},
+		{"timestamp too old", signedRequest("billing", signingSecret, now-int64((6*time.Minute).Seconds()), "nonce-4", body), http.StatusUnauthorized, "Signature Expired"},
+		{"timestamp too far ahead", signedRequest("billing", signingSecret, now+int64((6*time.Minute).Seconds()), "nonce-5", body), http.StatusUnauthorized, "Signature Expired"},
+		{"within clock skew", signedRequest("billing", signingSecret, now-int64((4*time.Minute).Seconds()), "nonce-6", body), http.StatusOK, ""},
+		{"oversized body", signedRequest("billing", signingSecret, now, "nonce-7", bytes.Repeat([]byte("a"), maxSignedBodySize+1)), http.StatusRequestEntityTooLarge, "Payload Too Large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, tt.request)
+
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d %s, want %d", recorder.Code, recorder.Body.String(), tt.status)
+			}
+			if tt.status == http.StatusOK {
+				if recorder.Body.String() != string(body) {
+					t.Fatalf("handler read %q, want the signed body", recorder.Body.String())
+				}
+				return
+			}
+
+			var appErr errors.AppError
+			if err := json.Unmarshal(recorder.Body.Bytes(), &appErr); err != nil || appErr.Type != tt.error || appErr.Code != tt.status {
+				t.Fatalf("error body = %s, want %s with status %d", recorder.Body.String(), tt.error, tt.status)
+			}
+		})
+	}
+}
+
+func TestRequireSignatureTamperedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.POST("/wallet/:id/withdraw",
+		RequireSignature(map[string][]byte{"billing": signingSecret}, signing.NewMemoryNonceStore(), 5*time.Minute, false),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := signedRequest("billing", signingSecret, time.Now().Unix(), "nonce-1", []byte(`{"amountInCents":100}`))
+	request.Body = io.NopCloser(strings.NewReader(`{"amountInCents":100000}`))
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("tampered body = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+
+	// Unsigned requests pass when the signature is optional
+	recorder = httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/wallet/1/withdraw", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unsigned optional request = %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
+
+func signedRequest(keyID string, secret []byte, timestamp int64, nonce string, body []byte) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/wallet/1/withdraw", bytes.NewReader(body))
+	canonical := signing.Canonical(request.Method, request.URL.RequestURI(), timestamp, nonce, body)
+
+	request.Header.Set(signing.HeaderKeyID, keyID)
+	request.Header.Set(signing.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(signing.HeaderNonce, nonce)
+	request.Header.Set(signing.HeaderSignature, signing.Sign(secret, canonical))
+	return request
+}
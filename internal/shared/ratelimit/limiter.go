@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket: Burst tokens refilled evenly over Period
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Rate returns the refill rate in tokens per second
+func (l Limit) Rate() float64 {
+	return float64(l.Burst) / l.Period.Seconds()
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Burst, l.Period)
+}
+
+// ParseLimit parses "<requests>/<duration>", e.g. "10/1m"
+func ParseLimit(value string) (Limit, error) {
+	count, period, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <requests>/<duration>", value)
+	}
+
+	burst, err := strconv.Atoi(count)
+	if err != nil || burst <= 0 {
+		return Limit{}, fmt.Errorf("invalid request count in rate limit %q", value)
+	}
+
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Limit{}, fmt.Errorf("invalid duration in rate limit %q", value)
+	}
+
+	return Limit{Burst: burst, Period: duration}, nil
+}
+
+// Result is the state of a bucket after a request took (or failed to take) a
+// token
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until a token is available, zero when allowed
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// Backend stores token buckets
+type Backend interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// resultFor builds the result from the tokens left in a bucket
+func resultFor(allowed bool, tokens float64, limit Limit) Result {
+	rate := limit.Rate()
+	result := Result{
+		Allowed:    allowed,
+		Limit:      limit.Burst,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: secondsToDuration((float64(limit.Burst) - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	return result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// period of the limit the bucket refills by; an idle bucket is full
+	// once it passes
+	period time.Duration
+}
+
+// MemoryBackend keeps buckets in process. Limits are per instance, so use the
+// Mongo backend when the API runs with several replicas.
+type MemoryBackend struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (b *MemoryBackend) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.sweep(now, limit.Period)
+
+	current, ok := b.buckets[key]
+	if !ok {
+		current = &bucket{tokens: float64(limit.Burst), updated: now, period: limit.Period}
+		b.buckets[key] = current
+	}
+
+	elapsed := now.Sub(current.updated).Seconds()
+	current.tokens = math.Min(float64(limit.Burst), current.tokens+elapsed*limit.Rate())
+	current.updated = now
+	current.period = limit.Period
+
+	if current.tokens < 1 {
+		return resultFor(false, current.tokens, limit), nil
+	}
+
+	current.tokens--
+	return resultFor(true, current.tokens, limit), nil
+}
+
+// sweep drops buckets idle for longer than their own period, which are full
+// anyway. The period of the request only spaces the sweeps: a short route
+// must not drop the buckets of a long one.
+func (b *MemoryBackend) sweep(now time.Time, interval time.Duration) {
+	if now.Sub(b.lastSweep) < interval {
+		return
+	}
+	b.lastSweep = now
+
+	for key, current := range b.buckets {
+		if now.Sub(current.updated) > current.period {
+			delete(b.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryBackendKeepsLongPeriodBuckets(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	backend := NewMemoryBackend()
+	backend.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	hourly := Limit{Burst: 2, Period: time.Hour}
+	minutely := Limit{Burst: 300, Period: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		if result, _ := backend.Take(ctx, "withdraw:customer-1", hourly); !result.Allowed {
+			t.Fatalf("request %d of the hourly limit was rejected", i+1)
+		}
+	}
+
+	// Requests to a short-period route sweep after a few idle minutes
+	for minute := 0; minute < 5; minute++ {
+		now = now.Add(2 * time.Minute)
+		if result, _ := backend.Take(ctx, "default:customer-2", minutely); !result.Allowed {
+			t.Fatalf("request to the minutely route was rejected")
+		}
+	}
+
+	// Ten minutes refill a sixth of a token, so the hourly bucket is still empty
+	if result, _ := backend.Take(ctx, "withdraw:customer-1", hourly); result.Allowed {
+		t.Fatal("hourly limit restarted from a full bucket after a short-period sweep")
+	}
+
+	// Buckets idle for longer than their own period are still dropped
+	now = now.Add(2 * time.Hour)
+	backend.Take(ctx, "default:customer-2", minutely)
+	if _, ok := backend.buckets["withdraw:customer-1"]; ok {
+		t.Fatal("hourly bucket idle for two hours was not swept")
+	}
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+
+	"wallet-go/internal/shared/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoBackend shares buckets between API instances. Each take is a single
+// findOneAndUpdate with an update pipeline, so refill and decrement are atomic
+// and use the server clock.
+type MongoBackend struct {
+	collection *mongo.Collection
+}
+
+func NewMongoBackend(client *database.MongoClient) *MongoBackend {
+	return &MongoBackend{
+		collection: client.GetCollection("rate_limit"),
+	}
+}
+
+// EnsureIndexes creates the TTL index that removes idle buckets, so the
+// collection does not grow with every key ever seen. Migration 4 creates the
+// same index.
+func (b *MongoBackend) EnsureIndexes(ctx context.Context) error {
+	_, err := b.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expireAt", Value: 1}},
+		Options: options.Index().SetName("expireAt_ttl").SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+type mongoBucket struct {
+	Key     string  `bson:"_id"`
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+func (b *MongoBackend) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	burst := float64(limit.Burst)
+	elapsedSeconds := bson.M{"$divide": bson.A{
+		bson.M{"$subtract": bson.A{"$$NOW", bson.M{"$ifNull": bson.A{"$updatedAt", "$$NOW"}}}},
+		1000,
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$min": bson.A{
+				burst,
+				bson.M{"$add": bson.A{
+					bson.M{"$ifNull": bson.A{"$tokens", burst}},
+					bson.M{"$multiply": bson.A{elapsedSeconds, limit.Rate()}},
+				}},
+			}},
+			"updatedAt": "$$NOW",
+			// Idle buckets are full again after one period; the TTL index on
+			// expireAt removes them
+			"expireAt": bson.M{"$add": bson.A{"$$NOW", limit.Period.Milliseconds()}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed": bson.M{"$gte": bson.A{"$tokens", 1}},
+			"tokens": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$tokens", 1}},
+				bson.M{"$subtract": bson.A{"$tokens", 1}},
+				"$tokens",
+			}},
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var result mongoBucket
+	err := b.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&result)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return resultFor(result.Allowed, result.Tokens, limit), nil
+}
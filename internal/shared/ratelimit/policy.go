@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Dimension is what a bucket is keyed by
+type Dimension string
+
+const (
+	DimensionAPIKey   Dimension = "apikey"
+	DimensionCustomer Dimension = "customer"
+	DimensionWallet   Dimension = "wallet"
+	DimensionIP       Dimension = "ip"
+)
+
+// Policy maps routes ("METHOD /path" as registered in gin) to limits
+type Policy struct {
+	mu      sync.RWMutex
+	Default Limit
+	Routes  map[string]Limit
+}
+
+// NewPolicy parses the default limit and the route overrides, each given as
+// "METHOD /path=<requests>/<duration>"
+func NewPolicy(defaultLimit string, routes []string) (*Policy, error) {
+	limit, err := ParseLimit(defaultLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{
+		Default: limit,
+		Routes:  make(map[string]Limit, len(routes)),
+	}
+
+	for _, route := range routes {
+		name, value, found := strings.Cut(route, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid route rate limit %q, expected \"METHOD /path=<requests>/<duration>\"", route)
+		}
+
+		routeLimit, err := ParseLimit(value)
+		if err != nil {
+			return nil, err
+		}
+		policy.Routes[strings.Join(strings.Fields(name), " ")] = routeLimit
+	}
+
+	return policy, nil
+}
+
+// Replace swaps in the limits of next, used when the configuration is
+// reloaded. Buckets keep their tokens and refill at the new rate.
+func (p *Policy) Replace(next *Policy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Default = next.Default
+	p.Routes = next.Routes
+}
+
+// LimitFor returns the limit of a route
+func (p *Policy) LimitFor(method, path string) Limit {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if limit, ok := p.Routes[method+" "+path]; ok {
+		return limit
+	}
+	return p.Default
+}
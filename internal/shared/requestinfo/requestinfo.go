@@ -0,0 +1,30 @@
+// Package requestinfo carries metadata of the HTTP request that started a
+// piece of work, for audit entries and logs.
+package requestinfo
+
+import (
+	"context"
+)
+
+// HeaderRequestID is read from and echoed to clients
+const HeaderRequestID = "X-Request-ID"
+
+type Info struct {
+	RequestID string
+	IP        string
+	UserAgent string
+}
+
+type infoKey struct{}
+
+func WithInfo(ctx context.Context, info *Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+// FromContext returns the request metadata, or an empty Info outside requests
+func FromContext(ctx context.Context) *Info {
+	if info, ok := ctx.Value(infoKey{}).(*Info); ok {
+		return info
+	}
+	return &Info{}
+}
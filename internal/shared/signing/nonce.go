@@ -0,0 +1,116 @@
+package signing
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"wallet-go/internal/shared/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NonceStore remembers nonces for the replay window
+type NonceStore interface {
+	// Remember returns false when the nonce was already used
+	Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonceStore keeps nonces in process; use the Mongo store when the API
+// runs with several replicas. Expiries are kept in a min-heap, so a request
+// only drops the nonces that expired instead of scanning all of them.
+type MemoryNonceStore struct {
+	mu       sync.Mutex
+	nonces   map[string]time.Time
+	expiries nonceExpiries
+	now      func() time.Time
+}
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+func (s *MemoryNonceStore) Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for len(s.expiries) > 0 && now.After(s.expiries[0].expireAt) {
+		expired := heap.Pop(&s.expiries).(nonceExpiry)
+		delete(s.nonces, expired.nonce)
+	}
+
+	if _, used := s.nonces[nonce]; used {
+		return false, nil
+	}
+
+	expireAt := now.Add(ttl)
+	s.nonces[nonce] = expireAt
+	heap.Push(&s.expiries, nonceExpiry{nonce: nonce, expireAt: expireAt})
+	return true, nil
+}
+
+type nonceExpiry struct {
+	nonce    string
+	expireAt time.Time
+}
+
+// nonceExpiries implements heap.Interface, earliest expiry first
+type nonceExpiries []nonceExpiry
+
+func (q nonceExpiries) Len() int           { return len(q) }
+func (q nonceExpiries) Less(i, j int) bool { return q[i].expireAt.Before(q[j].expireAt) }
+func (q nonceExpiries) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *nonceExpiries) Push(x interface{}) { *q = append(*q, x.(nonceExpiry)) }
+
+func (q *nonceExpiries) Pop() interface{} {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
+
+// MongoNonceStore shares nonces between instances. The nonce is the _id, so a
+// replay fails the insert; the TTL index on expireAt removes old nonces.
+type MongoNonceStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoNonceStore(db *database.MongoClient) *MongoNonceStore {
+	return &MongoNonceStore{
+		collection: db.GetCollection("request_nonce"),
+	}
+}
+
+// EnsureIndexes creates the TTL index that removes nonces once their window
+// has passed. Migration 4 creates the same index.
+func (s *MongoNonceStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expireAt", Value: 1}},
+		Options: options.Index().SetName("expireAt_ttl").SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+type nonceDocument struct {
+	Nonce    string    `bson:"_id"`
+	ExpireAt time.Time `bson:"expireAt"`
+}
+
+func (s *MongoNonceStore) Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	_, err := s.collection.InsertOne(ctx, nonceDocument{
+		Nonce:    nonce,
+		ExpireAt: time.Now().Add(ttl),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
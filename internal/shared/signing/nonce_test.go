@@ -0,0 +1,73 @@
+package signing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryNonceStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryNonceStore()
+	store.now = func() time.Time { return now }
+
+	remember := func(nonce string, ttl time.Duration) bool {
+		t.Helper()
+		fresh, err := store.Remember(ctx, nonce, ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fresh
+	}
+
+	if !remember("key:a", 10*time.Minute) {
+		t.Fatal("first use of a nonce was rejected")
+	}
+	if remember("key:a", 10*time.Minute) {
+		t.Fatal("replayed nonce was accepted")
+	}
+	if !remember("key:b", time.Minute) {
+		t.Fatal("first use of another nonce was rejected")
+	}
+
+	// b expires first, though it was stored last
+	now = now.Add(2 * time.Minute)
+	if _, ok := store.nonces["key:a"]; !ok {
+		t.Fatal("nonce a dropped before its expiry")
+	}
+	if !remember("key:b", time.Minute) {
+		t.Fatal("expired nonce b was still rejected")
+	}
+	if remember("key:a", 10*time.Minute) {
+		t.Fatal("nonce a accepted again inside its window")
+	}
+
+	now = now.Add(10 * time.Minute)
+	remember("key:c", time.Minute)
+	if _, ok := store.nonces["key:a"]; ok {
+		t.Fatal("expired nonce a was not dropped")
+	}
+}
+
+func TestMemoryNonceStoreDropsOnlyExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryNonceStore()
+	store.now = func() time.Time { return now }
+
+	for i := 0; i < 100; i++ {
+		store.Remember(ctx, fmt.Sprintf("key:%d", i), time.Duration(i+1)*time.Second)
+	}
+
+	now = now.Add(50*time.Second + time.Millisecond)
+	store.Remember(ctx, "key:new", time.Minute)
+
+	if len(store.nonces) != 51 || len(store.expiries) != 51 {
+		t.Fatalf("%d nonces and %d expiries kept, want 51", len(store.nonces), len(store.expiries))
+	}
+	if _, ok := store.nonces["key:50"]; !ok {
+		t.Fatal("nonce expiring after now was dropped")
+	}
+}
@@ -0,0 +1,61 @@
+package signing
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	body := []byte(`{"amountInCents":100}`)
+	digest := sha256.Sum256(body)
+
+	got := Canonical("post", "/wallet/1/withdraw?dryRun=true", 1700000000, "nonce-1", body)
+	want := "POST\n/wallet/1/withdraw?dryRun=true\n1700000000\nnonce-1\n" + hex.EncodeToString(digest[:])
+	if got != want {
+		t.Fatalf("Canonical = %q, want %q", got, want)
+	}
+
+	// An empty body is the digest of no bytes
+	empty := Canonical("GET", "/wallet/1", 1700000000, "nonce-1", nil)
+	if !strings.HasSuffix(empty, "\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855") {
+		t.Fatalf("Canonical of an empty body = %q", empty)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	canonical := Canonical("POST", "/wallet/1/withdraw", 1700000000, "nonce-1", []byte(`{}`))
+	header := Sign(secret, canonical)
+
+	if !strings.HasPrefix(header, "v1=") {
+		t.Fatalf("Sign = %q, want the v1= prefix", header)
+	}
+	if !Verify(secret, canonical, " "+header+" ") {
+		t.Fatal("Verify rejected its own signature")
+	}
+	if Verify([]byte("another secret of thirty-two b."), canonical, header) {
+		t.Fatal("Verify accepted a signature of another secret")
+	}
+	tampered := Canonical("POST", "/wallet/1/withdraw", 1700000000, "nonce-1", []byte(`{"amountInCents":1}`))
+	if Verify(secret, tampered, header) {
+		t.Fatal("Verify accepted a signature of another body")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+	keys, err := ParseKeys([]string{"billing:" + secret})
+	if err != nil || len(keys["billing"]) != 32 {
+		t.Fatalf("ParseKeys = %v, %v; want the billing key", keys, err)
+	}
+
+	for _, entry := range []string{"billing", ":" + secret, "billing:not-base64", "billing:" + base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := ParseKeys([]string{entry}); err == nil {
+			t.Fatalf("ParseKeys accepted %q", entry)
+		}
+	}
+}
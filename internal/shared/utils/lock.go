@@ -2,6 +2,9 @@ package utils
 
 import (
 	"sync"
+	"time"
+
+	"wallet-go/internal/shared/metrics"
 
 	"github.com/google/uuid"
 )
@@ -21,7 +24,9 @@ func (walletLockManager *WalletLockManager) GetLock(walletID uuid.UUID) *sync.Mu
 
 func (walletLockManager *WalletLockManager) LockWallet(walletID uuid.UUID) {
 	lock := walletLockManager.GetLock(walletID)
+	start := time.Now()
 	lock.Lock()
+	metrics.ObserveLockWait(time.Since(start))
 }
 
 func (walletLockManager *WalletLockManager) UnlockWallet(walletID uuid.UUID) {
@@ -1,10 +1,12 @@
 package wallet
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
 	"net/http"
 
 	"wallet-go/internal/operation"
+	"wallet-go/internal/shared/auth"
 	"wallet-go/internal/shared/errors"
 	"wallet-go/internal/shared/kafka"
 
@@ -38,7 +40,7 @@ func NewHandler(service *Service, operationService *operation.Service, producer
 // @Tags Wallet
 // @Accept json
 // @Produce json
-// @Param request body object{customer_id=string} true "Wallet creation request"
+// @Param request body object{customer_id=string,time_zone=string} true "Wallet creation request"
 // @Success 201 {object} object{id=string,customer_id=string,current_amount_in_cents=int,active=bool,created_at=string}
 // @Failure 400 {object} object{error=string,message=string}
 // @Failure 500 {object} object{error=string,message=string}
@@ -50,6 +52,13 @@ func (h *Handler) Create(c *gin.Context) {
 		return
 	}
 
+	// Customers may only open wallets for themselves
+	if err := auth.AuthorizeCustomer(auth.PrincipalFromContext(c.Request.Context()), request.CustomerID); err != nil {
+		appErr := err.(*errors.AppError)
+		c.JSON(appErr.Code, appErr)
+		return
+	}
+
 	wallet, err := h.service.Create(c.Request.Context(), request)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
@@ -129,7 +138,7 @@ func (h *Handler) List(c *gin.Context) {
 		// Carregar operações para cada carteira
 		operations, err := h.operationService.GetByWalletID(c.Request.Context(), wallet.WalletID)
 		if err != nil {
-			fmt.Printf("DEBUG: Erro ao carregar operações para wallet %s: %v\n", wallet.WalletID, err)
+			slog.WarnContext(c.Request.Context(), "Failed to load wallet operations", "wallet_id", wallet.WalletID, "error", err)
 			// Não quebrar por causa das operações - apenas não incluir
 		} else {
 			wallet.Operations = operations
@@ -143,12 +152,12 @@ func (h *Handler) List(c *gin.Context) {
 
 // Patch godoc
 // @Summary Update wallet
-// @Description Update wallet status (active/blocked)
+// @Description Update wallet status (active/blocked) or settings. A reason is required to block, unblock or deactivate.
 // @Tags Wallet
 // @Accept json
 // @Produce json
 // @Param id path string true "Wallet ID"
-// @Param request body object{active=bool,blocked=bool} true "Wallet patch request"
+// @Param request body object{active=bool,blocked=bool,time_zone=string,reason=string} true "Wallet patch request"
 // @Success 200 {object} object{id=string,customer_id=string,current_amount_in_cents=int,active=bool,blocked=bool}
 // @Failure 400 {object} object{error=string,message=string}
 // @Failure 404 {object} object{error=string,message=string}
@@ -168,6 +177,11 @@ func (h *Handler) Patch(c *gin.Context) {
 		return
 	}
 
+	if err := h.authorizePatch(c.Request.Context(), walletID, patch); err != nil {
+		c.JSON(err.Code, err)
+		return
+	}
+
 	wallet, err := h.service.Patch(c.Request.Context(), walletID, patch)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
@@ -190,9 +204,14 @@ func (h *Handler) Patch(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Wallet ID"
 // @Param request body object{amount_in_cents=int} true "Deposit request"
+// @Param X-Signature-Key-Id header string false "Request signing key ID"
+// @Param X-Signature-Timestamp header string false "Unix timestamp of the signature"
+// @Param X-Signature-Nonce header string false "Single use nonce"
+// @Param X-Signature header string false "v1=<hex HMAC-SHA256>"
 // @Success 202 {object} object{message=string}
 // @Failure 400 {object} object{error=string,message=string}
 // @Failure 500 {object} object{error=string,message=string}
+// @Failure 401 {object} object{error=string,message=string}
 // @Router /wallet/{id}/deposit [post]
 func (h *Handler) Deposit(c *gin.Context) {
 	idParam := c.Param("id")
@@ -209,8 +228,10 @@ func (h *Handler) Deposit(c *gin.Context) {
 	}
 
 	message := WalletKafkaTransactionMessage{
+		OperationID:   uuid.New(),
 		WalletID:      walletID,
 		AmountInCents: request.AmountInCents,
+		Actor:         auth.ActorFromContext(c.Request.Context()),
 	}
 
 	if err := h.producer.SendMessage(c.Request.Context(), h.topicDeposit, walletID.String(), message); err != nil {
@@ -232,9 +253,14 @@ func (h *Handler) Deposit(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Wallet ID"
 // @Param request body object{amount_in_cents=int} true "Withdraw request"
+// @Param X-Signature-Key-Id header string false "Request signing key ID"
+// @Param X-Signature-Timestamp header string false "Unix timestamp of the signature"
+// @Param X-Signature-Nonce header string false "Single use nonce"
+// @Param X-Signature header string false "v1=<hex HMAC-SHA256>"
 // @Success 202 {object} object{message=string}
 // @Failure 400 {object} object{error=string,message=string}
 // @Failure 500 {object} object{error=string,message=string}
+// @Failure 401 {object} object{error=string,message=string}
 // @Router /wallet/{id}/withdraw [post]
 func (h *Handler) Withdraw(c *gin.Context) {
 	idParam := c.Param("id")
@@ -251,8 +277,10 @@ func (h *Handler) Withdraw(c *gin.Context) {
 	}
 
 	message := WalletKafkaTransactionMessage{
+		OperationID:   uuid.New(),
 		WalletID:      walletID,
 		AmountInCents: request.AmountInCents,
+		Actor:         auth.ActorFromContext(c.Request.Context()),
 	}
 
 	if err := h.producer.SendMessage(c.Request.Context(), h.topicWithdraw, walletID.String(), message); err != nil {
@@ -274,9 +302,14 @@ func (h *Handler) Withdraw(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Source Wallet ID"
 // @Param request body object{amount_in_cents=int,wallet_destination_id=string} true "Transfer request"
+// @Param X-Signature-Key-Id header string false "Request signing key ID"
+// @Param X-Signature-Timestamp header string false "Unix timestamp of the signature"
+// @Param X-Signature-Nonce header string false "Single use nonce"
+// @Param X-Signature header string false "v1=<hex HMAC-SHA256>"
 // @Success 202 {object} object{message=string}
 // @Failure 400 {object} object{error=string,message=string}
 // @Failure 500 {object} object{error=string,message=string}
+// @Failure 401 {object} object{error=string,message=string}
 // @Router /wallet/{id}/transfer [post]
 func (h *Handler) Transfer(c *gin.Context) {
 	idParam := c.Param("id")
@@ -293,9 +326,11 @@ func (h *Handler) Transfer(c *gin.Context) {
 	}
 
 	message := WalletKafkaTransactionTransferMessage{
+		OperationID:         uuid.New(),
 		WalletID:            walletID,
 		AmountInCents:       request.AmountInCents,
 		WalletDestinationID: request.WalletDestinationID,
+		Actor:               auth.ActorFromContext(c.Request.Context()),
 	}
 
 	if err := h.producer.SendMessage(c.Request.Context(), h.topicTransfer, walletID.String(), message); err != nil {
@@ -309,6 +344,35 @@ func (h *Handler) Transfer(c *gin.Context) {
 	c.JSON(http.StatusAccepted, response)
 }
 
+// authorizePatch checks the permission of each patched field: status changes
+// need the block/deactivate permissions, settings can be changed by the owner
+func (h *Handler) authorizePatch(ctx context.Context, walletID uuid.UUID, patch WalletPatch) *errors.AppError {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil
+	}
+
+	if patch.Blocked != nil && !principal.Can(auth.PermissionWalletBlock) {
+		return errors.Forbidden("Missing permission " + string(auth.PermissionWalletBlock))
+	}
+
+	if patch.Active != nil && !principal.Can(auth.PermissionWalletDeactivate) {
+		return errors.Forbidden("Missing permission " + string(auth.PermissionWalletDeactivate))
+	}
+
+	if patch.TimeZone != nil && principal.Kind == auth.PrincipalKindUser && !principal.Can(auth.PermissionWalletSettings) {
+		owner, err := h.service.WalletOwner(ctx, walletID)
+		if err != nil {
+			return errors.InternalServerError("Failed to authorize wallet update")
+		}
+		if owner != "" && owner != principal.CustomerID {
+			return errors.Forbidden("Missing permission " + string(auth.PermissionWalletSettings))
+		}
+	}
+
+	return nil
+}
+
 // mapToResponse mapper Wallet to WalletResponse
 func (h *Handler) mapToResponse(wallet *Wallet) *WalletResponse {
 	return &WalletResponse{
@@ -318,9 +382,12 @@ func (h *Handler) mapToResponse(wallet *Wallet) *WalletResponse {
 		Operations:           wallet.Operations,
 		Active:               wallet.Active,
 		Blocked:              wallet.Blocked,
+		TimeZone:             wallet.TimeZone,
 		CreatedAt:            wallet.CreatedAt,
 		UpdatedAt:            wallet.UpdatedAt,
 		BlockedAt:            wallet.BlockedAt,
 		UnblockedAt:          wallet.UnblockedAt,
+		CreatedBy:            wallet.CreatedBy,
+		UpdatedBy:            wallet.UpdatedBy,
 	}
 }
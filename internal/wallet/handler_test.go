@@ -0,0 +1,61 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandlerCreateOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	customer := &auth.Principal{Subject: "user-1", CustomerID: "customer-1", Kind: auth.PrincipalKindUser, Scopes: []string{auth.ScopeWalletWrite}}
+	billing := &auth.Principal{Subject: "billing", Kind: auth.PrincipalKindService, Scopes: []string{auth.ScopeWalletWrite}}
+	admin := &auth.Principal{Subject: "admin", Kind: auth.PrincipalKindUser, Roles: []auth.Role{auth.RoleAdmin}}
+
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		customerID string
+		status     int
+	}{
+		{"customer for itself", customer, "customer-1", http.StatusCreated},
+		{"customer for another customer", customer, "customer-2", http.StatusForbidden},
+		{"service for any customer", billing, "customer-2", http.StatusCreated},
+		{"admin for any customer", admin, "customer-3", http.StatusCreated},
+		{"authentication disabled", nil, "customer-4", http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operations := operation.NewMemoryStore()
+			service := NewService(NewMemoryStore(operations), operations, NewValidator(), utils.NewWalletLockManager())
+			handler := NewHandler(service, operation.NewService(operations, time.UTC), nil, "", "", "")
+
+			engine := gin.New()
+			engine.POST("/wallet", func(c *gin.Context) {
+				if tt.principal != nil {
+					c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), tt.principal))
+				}
+				handler.Create(c)
+			})
+
+			body, _ := json.Marshal(map[string]string{"customerId": tt.customerID})
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/wallet", bytes.NewReader(body)))
+
+			if recorder.Code != tt.status {
+				t.Fatalf("POST /wallet = %d %s, want %d", recorder.Code, recorder.Body.String(), tt.status)
+			}
+		})
+	}
+}
@@ -3,6 +3,11 @@ package wallet
 import (
 	"context"
 
+	"wallet-go/internal/approval"
+	"wallet-go/internal/audit"
+	"wallet-go/internal/operation"
+	"wallet-go/internal/risk"
+
 	"github.com/google/uuid"
 )
 
@@ -13,3 +18,30 @@ type WalletService interface {
 	Withdraw(ctx context.Context, walletID uuid.UUID, request WalletTransactionRequest) (*Wallet, error)
 	Transfer(ctx context.Context, sourceID uuid.UUID, request WalletTransactionTransferRequest) (*Wallet, error)
 }
+
+// OperationPublisher recebe as operações gravadas pelo Service (ex.: webhooks)
+type OperationPublisher interface {
+	Publish(ctx context.Context, op *operation.Operation) error
+}
+
+// AuditLogger grava as mudanças administrativas de carteiras
+type AuditLogger interface {
+	Record(ctx context.Context, entry *audit.Entry) error
+}
+
+// ApprovalQueue guarda os débitos que precisam de um segundo aprovador
+type ApprovalQueue interface {
+	RequiresApproval(amountInCents int64) bool
+	Park(ctx context.Context, approval *approval.Approval) error
+}
+
+// RiskEvaluator avalia as regras de risco antes de saques e transferências
+type RiskEvaluator interface {
+	Evaluate(ctx context.Context, tx risk.Transaction) (*operation.RiskAssessment, error)
+}
+
+// Transactor executa fn numa transação; os stores participam dela pelo ctx
+// recebido por fn, que pode ser executada mais de uma vez
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,184 @@
+package wallet
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/shared/auth"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore keeps wallets in process with the semantics of Store, for tests
+// and local runs. Customer IDs are kept in plaintext; operations are read from
+// the given operation repository.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	wallets    []*Wallet
+	operations operation.Repository
+}
+
+func NewMemoryStore(operations operation.Repository) *MemoryStore {
+	return &MemoryStore{operations: operations}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, wallet *Wallet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.wallets {
+		if existing.WalletID == wallet.WalletID || (wallet.CustomerID != "" && existing.CustomerID == wallet.CustomerID) {
+			return ErrWalletExists
+		}
+	}
+
+	wallet.CreatedAt = time.Now()
+	wallet.UpdatedAt = time.Now()
+
+	s.wallets = append(s.wallets, storedWallet(wallet))
+	return nil
+}
+
+func (s *MemoryStore) FindByID(ctx context.Context, walletID uuid.UUID) (*Wallet, error) {
+	return s.findOne(func(w *Wallet) bool { return w.WalletID == walletID }), nil
+}
+
+func (s *MemoryStore) FindByIDWithoutOperations(ctx context.Context, walletID uuid.UUID) (*Wallet, error) {
+	return s.findOne(func(w *Wallet) bool { return w.WalletID == walletID }), nil
+}
+
+func (s *MemoryStore) FindByCustomerID(ctx context.Context, customerID string) (*Wallet, error) {
+	wallet := s.findOne(func(w *Wallet) bool { return w.CustomerID == customerID })
+	if wallet == nil {
+		return nil, nil
+	}
+
+	operations, err := s.operations.FindByWalletID(ctx, wallet.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operations[i].CreatedAt.After(operations[j].CreatedAt)
+	})
+	for _, op := range operations {
+		wallet.Operations = append(wallet.Operations, *op)
+	}
+
+	return wallet, nil
+}
+
+func (s *MemoryStore) FindAll(ctx context.Context) ([]*Wallet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var wallets []*Wallet
+	for _, wallet := range s.wallets {
+		wallets = append(wallets, cloneWallet(wallet))
+	}
+	return wallets, nil
+}
+
+func (s *MemoryStore) UpdateSettings(ctx context.Context, wallet *Wallet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallet.UpdatedAt = time.Now()
+
+	for _, existing := range s.wallets {
+		if existing.WalletID == wallet.WalletID {
+			stored := storedWallet(wallet)
+			existing.Active = stored.Active
+			existing.Blocked = stored.Blocked
+			existing.TimeZone = stored.TimeZone
+			existing.UpdatedAt = stored.UpdatedAt
+			if stored.BlockedAt != nil {
+				existing.BlockedAt = stored.BlockedAt
+			}
+			if stored.UnblockedAt != nil {
+				existing.UnblockedAt = stored.UnblockedAt
+			}
+			if stored.UpdatedBy != nil {
+				existing.UpdatedBy = stored.UpdatedBy
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) AddToBalance(ctx context.Context, walletID uuid.UUID, amountInCents int64, actor *auth.Actor) (*Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.wallets {
+		if existing.WalletID != walletID {
+			continue
+		}
+		if amountInCents < 0 && existing.CurrentAmountInCents < -amountInCents {
+			return nil, ErrInsufficientBalance
+		}
+
+		existing.CurrentAmountInCents += amountInCents
+		existing.UpdatedAt = storedTime(time.Now())
+		if actor != nil {
+			existing.UpdatedBy = actor
+		}
+		return cloneWallet(existing), nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, walletID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.wallets {
+		if existing.WalletID == walletID {
+			s.wallets = append(s.wallets[:i], s.wallets[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) findOne(match func(*Wallet) bool) *Wallet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, wallet := range s.wallets {
+		if match(wallet) {
+			return cloneWallet(wallet)
+		}
+	}
+	return nil
+}
+
+// storedWallet is the copy of wallet kept by the store: without operations
+// (bson:"-") and with the times as MongoDB returns them
+func storedWallet(wallet *Wallet) *Wallet {
+	stored := cloneWallet(wallet)
+	stored.Operations = nil
+	stored.CreatedAt = storedTime(stored.CreatedAt)
+	stored.UpdatedAt = storedTime(stored.UpdatedAt)
+	if stored.BlockedAt != nil {
+		blockedAt := storedTime(*stored.BlockedAt)
+		stored.BlockedAt = &blockedAt
+	}
+	if stored.UnblockedAt != nil {
+		unblockedAt := storedTime(*stored.UnblockedAt)
+		stored.UnblockedAt = &unblockedAt
+	}
+	return stored
+}
+
+func cloneWallet(wallet *Wallet) *Wallet {
+	clone := *wallet
+	return &clone
+}
+
+func storedTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Millisecond)
+}
@@ -0,0 +1,41 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+
+	"wallet-go/internal/shared/auth"
+
+	"github.com/google/uuid"
+)
+
+// ErrWalletExists is returned by Create when the wallet ID or the customer
+// already has a wallet
+var ErrWalletExists = errors.New("wallet already exists")
+
+// ErrInsufficientBalance is returned by AddToBalance when a debit is larger
+// than the balance
+var ErrInsufficientBalance = errors.New("insufficient wallet balance")
+
+// Repository stores wallets; implemented by Store on MongoDB and by
+// MemoryStore for tests. Finders return nil, nil when nothing matches.
+type Repository interface {
+	// Create sets CreatedAt and UpdatedAt and fails with ErrWalletExists
+	Create(ctx context.Context, wallet *Wallet) error
+	FindByID(ctx context.Context, walletID uuid.UUID) (*Wallet, error)
+	FindByIDWithoutOperations(ctx context.Context, walletID uuid.UUID) (*Wallet, error)
+	// FindByCustomerID also loads the operations, newest first
+	FindByCustomerID(ctx context.Context, customerID string) (*Wallet, error)
+	FindAll(ctx context.Context) ([]*Wallet, error)
+	// UpdateSettings sets UpdatedAt and writes the status, time zone and
+	// actor of the wallet but never its balance, so it cannot undo a balance
+	// change made meanwhile by another process. A missing wallet is not an
+	// error.
+	UpdateSettings(ctx context.Context, wallet *Wallet) error
+	// AddToBalance atomically adds amountInCents, negative for a debit, to the
+	// balance and returns the wallet after the change, or nil when it is
+	// missing. A debit larger than the balance changes nothing and fails with
+	// ErrInsufficientBalance.
+	AddToBalance(ctx context.Context, walletID uuid.UUID, amountInCents int64, actor *auth.Actor) (*Wallet, error)
+	Delete(ctx context.Context, walletID uuid.UUID) error
+}
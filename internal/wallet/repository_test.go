@@ -0,0 +1,249 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/database/mongotest"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testRepository(t, func(t *testing.T) (Repository, operation.Repository) {
+		operations := operation.NewMemoryStore()
+		return NewMemoryStore(operations), operations
+	})
+}
+
+func TestStore(t *testing.T) {
+	testRepository(t, func(t *testing.T) (Repository, operation.Repository) {
+		client := mongotest.Connect(t)
+		return NewStore(client, nil), operation.NewStore(client)
+	})
+}
+
+// testRepository is the contract every Repository implementation must meet.
+// Each case gets empty repositories; the operation repository is the one the
+// wallet repository loads operations from.
+func testRepository(t *testing.T, newRepository func(t *testing.T) (Repository, operation.Repository)) {
+	ctx := context.Background()
+
+	t.Run("Create stores the wallet", func(t *testing.T) {
+		repository, _ := newRepository(t)
+		wallet := newWallet("customer-1")
+
+		if err := repository.Create(ctx, wallet); err != nil {
+			t.Fatal(err)
+		}
+		if wallet.CreatedAt.IsZero() || wallet.UpdatedAt.IsZero() {
+			t.Error("Create did not set CreatedAt and UpdatedAt")
+		}
+
+		found, err := repository.FindByID(ctx, wallet.WalletID)
+		if err != nil || found == nil {
+			t.Fatalf("FindByID = %v, %v", found, err)
+		}
+		if found.CustomerID != "customer-1" || found.CurrentAmountInCents != 250 || !found.Active || found.TimeZone != "America/Sao_Paulo" {
+			t.Errorf("FindByID = %+v, want the created wallet", found)
+		}
+		if !found.CreatedAt.Equal(wallet.CreatedAt.Truncate(time.Millisecond)) {
+			t.Errorf("CreatedAt = %s, want %s at millisecond precision", found.CreatedAt, wallet.CreatedAt)
+		}
+
+		withoutOperations, err := repository.FindByIDWithoutOperations(ctx, wallet.WalletID)
+		if err != nil || withoutOperations == nil || withoutOperations.WalletID != wallet.WalletID {
+			t.Fatalf("FindByIDWithoutOperations = %v, %v", withoutOperations, err)
+		}
+	})
+
+	t.Run("Create rejects a second wallet of the customer", func(t *testing.T) {
+		repository, _ := newRepository(t)
+
+		if err := repository.Create(ctx, newWallet("customer-1")); err != nil {
+			t.Fatal(err)
+		}
+		if err := repository.Create(ctx, newWallet("customer-1")); err != ErrWalletExists {
+			t.Fatalf("Create of the same customer = %v, want ErrWalletExists", err)
+		}
+		if err := repository.Create(ctx, newWallet("customer-2")); err != nil {
+			t.Fatalf("Create of another customer = %v", err)
+		}
+	})
+
+	t.Run("finders return nil when missing", func(t *testing.T) {
+		repository, _ := newRepository(t)
+
+		if found, err := repository.FindByID(ctx, uuid.New()); err != nil || found != nil {
+			t.Errorf("FindByID = %v, %v, want nil, nil", found, err)
+		}
+		if found, err := repository.FindByCustomerID(ctx, "nobody"); err != nil || found != nil {
+			t.Errorf("FindByCustomerID = %v, %v, want nil, nil", found, err)
+		}
+		if wallets, err := repository.FindAll(ctx); err != nil || len(wallets) != 0 {
+			t.Errorf("FindAll = %v, %v, want none", wallets, err)
+		}
+	})
+
+	t.Run("FindByCustomerID loads the operations newest first", func(t *testing.T) {
+		repository, operations := newRepository(t)
+		wallet := newWallet("customer-1")
+		if err := repository.Create(ctx, wallet); err != nil {
+			t.Fatal(err)
+		}
+
+		var created []uuid.UUID
+		for _, operationType := range []enum.OperationType{enum.OperationTypeCreated, enum.OperationTypeDeposit} {
+			op := &operation.Operation{
+				OperationID: uuid.New(),
+				WalletID:    wallet.WalletID,
+				Type:        operationType,
+				Status:      enum.OperationStatusSuccess,
+			}
+			if err := operations.Create(ctx, op); err != nil {
+				t.Fatal(err)
+			}
+			created = append(created, op.OperationID)
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		found, err := repository.FindByCustomerID(ctx, "customer-1")
+		if err != nil || found == nil {
+			t.Fatalf("FindByCustomerID = %v, %v", found, err)
+		}
+		if found.WalletID != wallet.WalletID {
+			t.Fatalf("FindByCustomerID returned wallet %s, want %s", found.WalletID, wallet.WalletID)
+		}
+		if len(found.Operations) != 2 || found.Operations[0].OperationID != created[1] {
+			t.Fatalf("FindByCustomerID loaded %d operations, want 2 newest first", len(found.Operations))
+		}
+	})
+
+	t.Run("UpdateSettings keeps the balance", func(t *testing.T) {
+		repository, _ := newRepository(t)
+		wallet := newWallet("customer-1")
+		if err := repository.Create(ctx, wallet); err != nil {
+			t.Fatal(err)
+		}
+
+		// Another process changed the balance after this copy was read
+		if _, err := repository.AddToBalance(ctx, wallet.WalletID, 100, nil); err != nil {
+			t.Fatal(err)
+		}
+		readAt := wallet.UpdatedAt
+
+		time.Sleep(5 * time.Millisecond)
+		blockedAt := time.Now()
+		wallet.Blocked = true
+		wallet.BlockedAt = &blockedAt
+		wallet.TimeZone = "UTC"
+		if err := repository.UpdateSettings(ctx, wallet); err != nil {
+			t.Fatal(err)
+		}
+		if !wallet.UpdatedAt.After(readAt) {
+			t.Error("UpdateSettings did not move UpdatedAt")
+		}
+
+		found, err := repository.FindByID(ctx, wallet.WalletID)
+		if err != nil || found == nil {
+			t.Fatalf("FindByID = %v, %v", found, err)
+		}
+		if !found.Blocked || found.BlockedAt == nil || found.TimeZone != "UTC" || found.CustomerID != "customer-1" {
+			t.Errorf("FindByID after UpdateSettings = %+v", found)
+		}
+		if found.CurrentAmountInCents != 350 {
+			t.Errorf("balance after UpdateSettings = %d, want 350", found.CurrentAmountInCents)
+		}
+
+		// Changing a returned wallet does not change the stored one
+		found.CurrentAmountInCents = 1
+		again, _ := repository.FindByID(ctx, wallet.WalletID)
+		if again.CurrentAmountInCents != 350 {
+			t.Error("the repository returned its own copy of the wallet")
+		}
+
+		if err := repository.UpdateSettings(ctx, newWallet("missing")); err != nil {
+			t.Errorf("UpdateSettings of a missing wallet = %v, want nil", err)
+		}
+		if found, _ := repository.FindByCustomerID(ctx, "missing"); found != nil {
+			t.Error("UpdateSettings created a missing wallet")
+		}
+	})
+
+	t.Run("AddToBalance credits and guards debits", func(t *testing.T) {
+		repository, _ := newRepository(t)
+		wallet := newWallet("customer-1")
+		if err := repository.Create(ctx, wallet); err != nil {
+			t.Fatal(err)
+		}
+		actor := &auth.Actor{Subject: "worker", Kind: auth.PrincipalKindService}
+
+		updated, err := repository.AddToBalance(ctx, wallet.WalletID, 50, actor)
+		if err != nil || updated == nil || updated.CurrentAmountInCents != 300 {
+			t.Fatalf("AddToBalance(50) = %v, %v, want a balance of 300", updated, err)
+		}
+		if updated.UpdatedBy == nil || updated.UpdatedBy.Subject != "worker" || updated.CustomerID != "customer-1" {
+			t.Errorf("AddToBalance returned %+v, want the wallet touched by the actor", updated)
+		}
+
+		if updated, err = repository.AddToBalance(ctx, wallet.WalletID, -300, nil); err != nil || updated.CurrentAmountInCents != 0 {
+			t.Fatalf("AddToBalance(-300) = %v, %v, want a balance of 0", updated, err)
+		}
+		if _, err := repository.AddToBalance(ctx, wallet.WalletID, -1, nil); err != ErrInsufficientBalance {
+			t.Fatalf("AddToBalance(-1) on an empty wallet = %v, want ErrInsufficientBalance", err)
+		}
+		if found, _ := repository.FindByID(ctx, wallet.WalletID); found.CurrentAmountInCents != 0 {
+			t.Errorf("a rejected debit changed the balance to %d", found.CurrentAmountInCents)
+		}
+
+		for _, amount := range []int64{10, -10} {
+			if missing, err := repository.AddToBalance(ctx, uuid.New(), amount, nil); err != nil || missing != nil {
+				t.Errorf("AddToBalance(%d) of a missing wallet = %v, %v, want nil, nil", amount, missing, err)
+			}
+		}
+	})
+
+	t.Run("FindAll and Delete", func(t *testing.T) {
+		repository, _ := newRepository(t)
+		first, second := newWallet("customer-1"), newWallet("customer-2")
+		for _, wallet := range []*Wallet{first, second} {
+			if err := repository.Create(ctx, wallet); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		wallets, err := repository.FindAll(ctx)
+		if err != nil || len(wallets) != 2 {
+			t.Fatalf("FindAll = %d wallets, %v, want 2", len(wallets), err)
+		}
+
+		if err := repository.Delete(ctx, first.WalletID); err != nil {
+			t.Fatal(err)
+		}
+		if found, _ := repository.FindByID(ctx, first.WalletID); found != nil {
+			t.Error("Delete left the wallet")
+		}
+		if err := repository.Delete(ctx, first.WalletID); err != nil {
+			t.Errorf("Delete of a missing wallet = %v, want nil", err)
+		}
+
+		wallets, _ = repository.FindAll(ctx)
+		if len(wallets) != 1 || wallets[0].WalletID != second.WalletID {
+			t.Errorf("FindAll after Delete = %d wallets, want the second one", len(wallets))
+		}
+	})
+}
+
+func newWallet(customerID string) *Wallet {
+	return &Wallet{
+		WalletID:             uuid.New(),
+		CustomerID:           customerID,
+		CurrentAmountInCents: 250,
+		Active:               true,
+		TimeZone:             "America/Sao_Paulo",
+	}
+}
@@ -3,25 +3,40 @@ package wallet
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 	"wallet-go/internal/operation/enum"
 
+	"wallet-go/internal/approval"
+	"wallet-go/internal/audit"
 	"wallet-go/internal/operation"
+	"wallet-go/internal/risk"
+	"wallet-go/internal/shared/auth"
 	"wallet-go/internal/shared/errors"
+	"wallet-go/internal/shared/metrics"
+	"wallet-go/internal/shared/requestinfo"
+	"wallet-go/internal/shared/tracing"
 	"wallet-go/internal/shared/utils"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Service struct {
-	store          *Store
-	operationStore *operation.Store
+	store          Repository
+	operationStore operation.Repository
 	validator      *Validator
 	lockManager    *utils.WalletLockManager
+	publishers     []OperationPublisher
+	auditLog       AuditLogger
+	approvals      ApprovalQueue
+	riskRules      RiskEvaluator
+	transactor     Transactor
 }
 
-func NewService(store *Store, operationStore *operation.Store, validator *Validator, lockManager *utils.WalletLockManager) *Service {
+func NewService(store Repository, operationStore operation.Repository, validator *Validator, lockManager *utils.WalletLockManager) *Service {
 	return &Service{
 		store:          store,
 		operationStore: operationStore,
@@ -30,7 +45,38 @@ func NewService(store *Store, operationStore *operation.Store, validator *Valida
 	}
 }
 
+// SetAuditLogger enables the audit log of administrative changes
+func (s *Service) SetAuditLogger(auditLog AuditLogger) {
+	s.auditLog = auditLog
+}
+
+// SetApprovalQueue enables parking of large debits for a second approver
+func (s *Service) SetApprovalQueue(approvals ApprovalQueue) {
+	s.approvals = approvals
+}
+
+// SetRiskEvaluator enables the risk rules evaluated before debits
+func (s *Service) SetRiskEvaluator(riskRules RiskEvaluator) {
+	s.riskRules = riskRules
+}
+
+// SetTransactor makes changes that span several writes atomic. Without it,
+// as with the memory stores, the writes are applied one by one.
+func (s *Service) SetTransactor(transactor Transactor) {
+	s.transactor = transactor
+}
+
+// AddOperationPublisher registers a publisher notified of every operation
+// recorded by the service, including rejected ones
+func (s *Service) AddOperationPublisher(publisher OperationPublisher) {
+	s.publishers = append(s.publishers, publisher)
+}
+
 func (s *Service) Create(ctx context.Context, request WalletRequest) (*Wallet, error) {
+	if err := s.validator.ValidateTimeZone(request.TimeZone); err != nil {
+		return nil, err
+	}
+
 	// Check if wallet already exists for customer
 	existingWallet, err := s.store.FindByCustomerID(ctx, request.CustomerID)
 	if err != nil {
@@ -44,6 +90,7 @@ func (s *Service) Create(ctx context.Context, request WalletRequest) (*Wallet, e
 	// Create new wallet
 	walletID := uuid.New()
 	now := time.Now()
+	actor := auth.ActorFromContext(ctx)
 
 	wallet := &Wallet{
 		WalletID:             walletID,
@@ -51,11 +98,21 @@ func (s *Service) Create(ctx context.Context, request WalletRequest) (*Wallet, e
 		CurrentAmountInCents: 0,
 		Active:               true,
 		Blocked:              false,
+		TimeZone:             request.TimeZone,
 		CreatedAt:            now,
 		UpdatedAt:            now,
+		CreatedBy:            actor,
+		UpdatedBy:            actor,
 	}
 
 	if err := s.store.Create(ctx, wallet); err != nil {
+		// A concurrent request created the customer's wallet first; the unique
+		// customer index rejects the second one
+		if err == ErrWalletExists {
+			if existing, findErr := s.store.FindByCustomerID(ctx, request.CustomerID); findErr == nil && existing != nil {
+				return existing, nil
+			}
+		}
 		return nil, errors.InternalServerError("Failed to create wallet")
 	}
 
@@ -70,7 +127,7 @@ func (s *Service) Create(ctx context.Context, request WalletRequest) (*Wallet, e
 		CreatedAt:     now,
 	}
 
-	if err := s.operationStore.Create(ctx, createOperation); err != nil {
+	if err := s.recordOperation(ctx, createOperation); err != nil {
 		return nil, errors.InternalServerError("Failed to create operation")
 	}
 
@@ -90,25 +147,62 @@ func (s *Service) GetByID(ctx context.Context, walletID uuid.UUID) (*Wallet, err
 	return wallet, nil
 }
 
-func (s *Service) List(ctx context.Context) ([]*Wallet, error) {
-	fmt.Printf("DEBUG: WalletService.List() - iniciando busca no store...\n")
+// WalletTimeZone implements operation.WalletTimeZoneProvider. Unknown wallets
+// have no time zone, so callers fall back to their default.
+func (s *Service) WalletTimeZone(ctx context.Context, walletID uuid.UUID) (string, error) {
+	wallet, err := s.store.FindByIDWithoutOperations(ctx, walletID)
+	if err != nil {
+		return "", err
+	}
+
+	if wallet == nil {
+		return "", nil
+	}
+
+	return wallet.TimeZone, nil
+}
+
+// WalletOwner implements auth.WalletOwnerResolver
+func (s *Service) WalletOwner(ctx context.Context, walletID uuid.UUID) (string, error) {
+	wallet, err := s.store.FindByIDWithoutOperations(ctx, walletID)
+	if err != nil {
+		return "", err
+	}
 
+	if wallet == nil {
+		return "", nil
+	}
+
+	return wallet.CustomerID, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]*Wallet, error) {
 	wallets, err := s.store.FindAll(ctx)
 	if err != nil {
-		fmt.Printf("DEBUG: WalletService.List() - erro no store.FindAll(): %v\n", err)
 		return nil, errors.InternalServerError("Failed to list wallets")
 	}
 
-	fmt.Printf("DEBUG: WalletService.List() - store retornou %d wallets\n", len(wallets))
 	return wallets, nil
 }
 
 func (s *Service) Patch(ctx context.Context, walletID uuid.UUID, patch WalletPatch) (*Wallet, error) {
-	wallet, err := s.GetByID(ctx, walletID)
+	if err := s.validator.ValidatePatchReason(patch); err != nil {
+		return nil, err
+	}
+
+	// UpdateSettings leaves the balance alone, so balance changes of other
+	// processes are kept; the lock keeps the audited before and after of
+	// patches in this process consistent
+	s.lockManager.LockWallet(walletID)
+	defer s.lockManager.UnlockWallet(walletID)
+
+	wallet, err := s.getWalletOrThrow(ctx, walletID)
 	if err != nil {
 		return nil, err
 	}
 
+	before := *wallet
+
 	if patch.Active != nil {
 		wallet.WithActive(*patch.Active)
 	}
@@ -117,14 +211,81 @@ func (s *Service) Patch(ctx context.Context, walletID uuid.UUID, patch WalletPat
 		wallet.ChangeBlock(*patch.Blocked)
 	}
 
-	if err := s.store.Update(ctx, wallet); err != nil {
+	if patch.TimeZone != nil {
+		if err := s.validator.ValidateTimeZone(*patch.TimeZone); err != nil {
+			return nil, err
+		}
+		wallet.TimeZone = *patch.TimeZone
+	}
+
+	wallet.TouchedBy(auth.ActorFromContext(ctx))
+
+	// The change and its audit entry are written together, so a block or a
+	// deactivation is never applied without being audited
+	err = s.inTransaction(ctx, func(ctx context.Context) error {
+		if err := s.store.UpdateSettings(ctx, wallet); err != nil {
+			return err
+		}
+		return s.auditPatch(ctx, &before, wallet, patch.Reason)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to update wallet", "wallet_id", walletID, "error", err)
 		return nil, errors.InternalServerError("Failed to update wallet")
 	}
 
 	return wallet, nil
 }
 
-func (s *Service) Deposit(ctx context.Context, walletID uuid.UUID, request WalletTransactionRequest) (*Wallet, error) {
+// auditPatch records the fields changed by Patch. Patches that change nothing
+// are not recorded.
+func (s *Service) auditPatch(ctx context.Context, before, after *Wallet, reason string) error {
+	if s.auditLog == nil {
+		return nil
+	}
+
+	var changes []audit.Change
+	if before.Active != after.Active {
+		changes = append(changes, audit.Change{Field: "active", Before: before.Active, After: after.Active})
+	}
+	if before.Blocked != after.Blocked {
+		changes = append(changes, audit.Change{Field: "blocked", Before: before.Blocked, After: after.Blocked})
+	}
+	if before.TimeZone != after.TimeZone {
+		changes = append(changes, audit.Change{Field: "timeZone", Before: before.TimeZone, After: after.TimeZone})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return s.auditLog.Record(ctx, &audit.Entry{
+		Action:       patchAction(before, after),
+		ResourceType: audit.ResourceWallet,
+		ResourceID:   after.WalletID.String(),
+		Reason:       reason,
+		Changes:      changes,
+	})
+}
+
+// patchAction names the most significant change of a patch
+func patchAction(before, after *Wallet) string {
+	switch {
+	case before.Active && !after.Active:
+		return audit.ActionWalletDeactivate
+	case !before.Active && after.Active:
+		return audit.ActionWalletActivate
+	case !before.Blocked && after.Blocked:
+		return audit.ActionWalletBlock
+	case before.Blocked && !after.Blocked:
+		return audit.ActionWalletUnblock
+	default:
+		return audit.ActionWalletUpdate
+	}
+}
+
+func (s *Service) Deposit(ctx context.Context, walletID uuid.UUID, request WalletTransactionRequest) (_ *Wallet, err error) {
+	ctx, span := startSpan(ctx, "wallet.Deposit", walletID, request.AmountInCents)
+	defer func() { tracing.End(span, err) }()
+
 	s.lockManager.LockWallet(walletID)
 	defer s.lockManager.UnlockWallet(walletID)
 
@@ -133,16 +294,20 @@ func (s *Service) Deposit(ctx context.Context, walletID uuid.UUID, request Walle
 		return nil, err
 	}
 
-	validatedWallet, err := s.validator.EnsureValidForOperation(wallet, "Wallet")
-	if err != nil {
-		s.handleErrorOperation(ctx, wallet, enum.OperationTypeDeposit, request.AmountInCents, err.(*errors.AppError).Message)
-		return nil, err
+	if rejection := s.validator.EnsureValidForOperation(wallet, "Wallet"); rejection != nil {
+		if err := s.handleErrorOperation(ctx, wallet, enum.OperationTypeDeposit, request.AmountInCents, rejection); err != nil {
+			return nil, err
+		}
+		return nil, rejection.Err
 	}
 
-	return s.executeDeposit(ctx, validatedWallet, request)
+	return s.executeDeposit(ctx, wallet, request)
 }
 
-func (s *Service) Withdraw(ctx context.Context, walletID uuid.UUID, request WalletTransactionRequest) (*Wallet, error) {
+func (s *Service) Withdraw(ctx context.Context, walletID uuid.UUID, request WalletTransactionRequest) (_ *Wallet, err error) {
+	ctx, span := startSpan(ctx, "wallet.Withdraw", walletID, request.AmountInCents)
+	defer func() { tracing.End(span, err) }()
+
 	s.lockManager.LockWallet(walletID)
 	defer s.lockManager.UnlockWallet(walletID)
 
@@ -151,31 +316,49 @@ func (s *Service) Withdraw(ctx context.Context, walletID uuid.UUID, request Wall
 		return nil, err
 	}
 
-	if err := s.validator.ValidateForDebitOperation(wallet, "Source wallet", request.AmountInCents); err != nil {
-		s.handleErrorOperation(ctx, wallet, enum.OperationTypeWithdraw, -request.AmountInCents, err.(*errors.AppError).Message)
+	if rejection := s.validator.ValidateForDebitOperation(wallet, "Source wallet", request.AmountInCents); rejection != nil {
+		if err := s.handleErrorOperation(ctx, wallet, enum.OperationTypeWithdraw, -request.AmountInCents, rejection); err != nil {
+			return nil, err
+		}
+		return nil, rejection.Err
+	}
+
+	assessment, err := s.assessRisk(ctx, risk.Transaction{
+		Type:          enum.OperationTypeWithdraw,
+		WalletID:      walletID,
+		AmountInCents: request.AmountInCents,
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return s.executeWithdraw(ctx, wallet, request)
+	if stop, err := s.enforceRisk(ctx, wallet, enum.OperationTypeWithdraw, nil, request.AmountInCents, assessment); stop {
+		if err != nil {
+			return nil, err
+		}
+		// Parked for review, nothing changed yet
+		return wallet, nil
+	}
+
+	return s.executeWithdraw(ctx, wallet, request, assessment)
 }
 
-func (s *Service) Transfer(ctx context.Context, sourceID uuid.UUID, request WalletTransactionTransferRequest) (*Wallet, error) {
-	log.Printf("Transfer started - sourceID: %s, destinationID: %s, amount: %d", sourceID, request.WalletDestinationID, request.AmountInCents)
+func (s *Service) Transfer(ctx context.Context, sourceID uuid.UUID, request WalletTransactionTransferRequest) (_ *Wallet, err error) {
+	ctx, span := startSpan(ctx, "wallet.Transfer", sourceID, request.AmountInCents)
+	span.SetAttributes(attribute.String("wallet.destination_id", request.WalletDestinationID.String()))
+	defer func() { tracing.End(span, err) }()
 
 	// ✅ VERIFICAR WALLETS IGUAIS ANTES DOS LOCKS
 	if sourceID == request.WalletDestinationID {
-		log.Printf("Same wallet transfer detected - getting wallet for error operation")
-
 		// Buscar a wallet apenas para criar a operação de erro
 		sourceWallet, err := s.getWalletOrThrow(ctx, sourceID)
 		if err != nil {
 			return nil, err
 		}
 
-		log.Printf("Creating error operation for same wallet transfer")
-		s.handleErrorOperation(ctx, sourceWallet, enum.OperationTypeTransfer, -request.AmountInCents,
-			"Cannot process transaction. The source and destination wallets must be different!")
-		log.Printf("Error operation created, returning error")
+		if err := s.handleErrorOperation(ctx, sourceWallet, enum.OperationTypeTransfer, -request.AmountInCents, sameWalletRejection()); err != nil {
+			return nil, err
+		}
 		return nil, errors.SameWalletTransferNotAllowed()
 	}
 
@@ -187,12 +370,10 @@ func (s *Service) Transfer(ctx context.Context, sourceID uuid.UUID, request Wall
 		firstID, secondID = request.WalletDestinationID, sourceID
 	}
 
-	log.Printf("Locking wallets - first: %s, second: %s", firstID, secondID)
 	s.lockManager.LockWallet(firstID)
 	defer s.lockManager.UnlockWallet(firstID)
 	s.lockManager.LockWallet(secondID)
 	defer s.lockManager.UnlockWallet(secondID)
-	log.Printf("Wallets locked successfully")
 
 	sourceWallet, err := s.getWalletOrThrow(ctx, sourceID)
 	if err != nil {
@@ -206,22 +387,419 @@ func (s *Service) Transfer(ctx context.Context, sourceID uuid.UUID, request Wall
 
 	// Esta validação agora é redundante, mas posso manter por segurança
 	if sourceWallet.WalletID == destinationWallet.WalletID {
-		s.handleErrorOperation(ctx, sourceWallet, enum.OperationTypeTransfer, -request.AmountInCents,
-			"Cannot process transaction. The source and destination wallets must be different!")
+		if err := s.handleErrorOperation(ctx, sourceWallet, enum.OperationTypeTransfer, -request.AmountInCents, sameWalletRejection()); err != nil {
+			return nil, err
+		}
 		return nil, errors.SameWalletTransferNotAllowed()
 	}
 
-	if err := s.validator.ValidateForDebitOperation(sourceWallet, "Source wallet", request.AmountInCents); err != nil {
-		s.handleErrorOperation(ctx, sourceWallet, enum.OperationTypeTransfer, -request.AmountInCents, err.(*errors.AppError).Message)
+	if rejection := s.validator.ValidateForDebitOperation(sourceWallet, "Source wallet", request.AmountInCents); rejection != nil {
+		if err := s.handleErrorOperation(ctx, sourceWallet, enum.OperationTypeTransfer, -request.AmountInCents, rejection); err != nil {
+			return nil, err
+		}
+		return nil, rejection.Err
+	}
+
+	if rejection := s.validator.EnsureValidForOperation(destinationWallet, "Destination wallet"); rejection != nil {
+		if err := s.handleErrorOperation(ctx, sourceWallet, enum.OperationTypeTransfer, -request.AmountInCents, rejection); err != nil {
+			return nil, err
+		}
+		return nil, rejection.Err
+	}
+
+	assessment, err := s.assessRisk(ctx, risk.Transaction{
+		Type:           enum.OperationTypeTransfer,
+		WalletID:       sourceID,
+		CounterpartyID: &request.WalletDestinationID,
+		AmountInCents:  request.AmountInCents,
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if _, err := s.validator.EnsureValidForOperation(destinationWallet, "Destination wallet"); err != nil {
-		s.handleErrorOperation(ctx, sourceWallet, enum.OperationTypeTransfer, -request.AmountInCents, err.(*errors.AppError).Message)
+	if stop, err := s.enforceRisk(ctx, sourceWallet, enum.OperationTypeTransfer, &request.WalletDestinationID, request.AmountInCents, assessment); stop {
+		if err != nil {
+			return nil, err
+		}
+		// Parked for review, nothing changed yet
+		return sourceWallet, nil
+	}
+
+	return s.executeTransfer(ctx, sourceWallet, destinationWallet, request, assessment)
+}
+
+// Adjust credits (positive amount) or debits (negative amount) a wallet to
+// correct its balance. It is meant for support and skips the approval and
+// risk rules, and applies to blocked or inactive wallets too; the reason is
+// recorded on the operation and in the audit log.
+func (s *Service) Adjust(ctx context.Context, walletID uuid.UUID, request AdjustmentRequest) (_ *Wallet, err error) {
+	ctx, span := startSpan(ctx, "wallet.Adjust", walletID, request.AmountInCents)
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.validator.ValidateAdjustment(request); err != nil {
+		return nil, err
+	}
+
+	s.lockManager.LockWallet(walletID)
+	defer s.lockManager.UnlockWallet(walletID)
+
+	wallet, err := s.getWalletOrThrow(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.AmountInCents < 0 {
+		if rejection := s.validator.HasBalanceToDebit(wallet, "Wallet", -request.AmountInCents); rejection != nil {
+			return nil, rejection.Err
+		}
+	}
+
+	op := &operation.Operation{
+		OperationID:   uuid.New(),
+		WalletID:      walletID,
+		Type:          enum.OperationTypeAdjustment,
+		Status:        enum.OperationStatusSuccess,
+		AmountInCents: request.AmountInCents,
+		Reason:        request.Reason,
+		CreatedAt:     time.Now(),
+	}
+
+	// The balance, the operation and the audit entry are written together, so
+	// the ledger never misses an adjustment that was applied
+	var adjusted *Wallet
+	err = s.inTransaction(ctx, func(ctx context.Context) error {
+		updated, err := s.store.AddToBalance(ctx, walletID, request.AmountInCents, auth.ActorFromContext(ctx))
+		if err != nil {
+			return err
+		}
+		if updated == nil {
+			return errors.WalletNotFound()
+		}
+		adjusted = updated
+
+		if err := s.storeOperation(ctx, op); err != nil {
+			return err
+		}
+
+		if s.auditLog == nil {
+			return nil
+		}
+		before := updated.CurrentAmountInCents - request.AmountInCents
+		return s.auditLog.Record(ctx, &audit.Entry{
+			Action:       audit.ActionWalletAdjust,
+			ResourceType: audit.ResourceWallet,
+			ResourceID:   walletID.String(),
+			Reason:       request.Reason,
+			Changes: []audit.Change{
+				{Field: "currentAmountInCents", Before: before, After: updated.CurrentAmountInCents},
+			},
+		})
+	})
+	if err == ErrInsufficientBalance {
+		return nil, s.validator.InsufficientBalance("Wallet").Err
+	}
+	if appErr, ok := err.(*errors.AppError); ok {
+		return nil, appErr
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to adjust wallet", "wallet_id", walletID, "error", err)
+		return nil, errors.InternalServerError("Failed to adjust wallet")
+	}
+
+	s.publishOperation(ctx, op)
+	return adjusted, nil
+}
+
+// Reconcile compares the balance of each wallet with the sum of its
+// successful operations. walletIDs limits the run; empty checks every wallet.
+func (s *Service) Reconcile(ctx context.Context, walletIDs []uuid.UUID) (*Reconciliation, error) {
+	var wallets []*Wallet
+	if len(walletIDs) == 0 {
+		all, err := s.store.FindAll(ctx)
+		if err != nil {
+			return nil, errors.InternalServerError("Failed to list wallets")
+		}
+		wallets = all
+	} else {
+		for _, walletID := range walletIDs {
+			wallet, err := s.getWalletOrThrow(ctx, walletID)
+			if err != nil {
+				return nil, err
+			}
+			wallets = append(wallets, wallet)
+		}
+	}
+
+	balances, err := s.operationStore.LedgerBalances(ctx, walletIDs)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to sum operations")
+	}
+	ledger := make(map[uuid.UUID]operation.LedgerBalance, len(balances))
+	for _, balance := range balances {
+		ledger[balance.WalletID] = balance
+	}
+
+	result := &Reconciliation{
+		CheckedAt:   time.Now(),
+		WalletCount: len(wallets),
+		Balanced:    true,
+		Wallets:     []WalletReconciliation{},
+	}
+	for _, wallet := range wallets {
+		entry := ledger[wallet.WalletID]
+		item := WalletReconciliation{
+			WalletID:          wallet.WalletID,
+			BalanceInCents:    wallet.CurrentAmountInCents,
+			LedgerInCents:     entry.AmountInCents,
+			OperationCount:    entry.OperationCount,
+			DifferenceInCents: wallet.CurrentAmountInCents - entry.AmountInCents,
+		}
+		item.Balanced = item.DifferenceInCents == 0
+		if !item.Balanced {
+			result.Balanced = false
+			result.MismatchCount++
+		}
+		result.Wallets = append(result.Wallets, item)
+	}
+
+	return result, nil
+}
+
+// RequiresApproval reports whether a debit of the amount must be parked for a
+// second approver
+func (s *Service) RequiresApproval(amountInCents int64) bool {
+	return s.approvals != nil && s.approvals.RequiresApproval(amountInCents)
+}
+
+// RequestApproval parks a withdrawal or transfer instead of executing it. A
+// PENDING_APPROVAL operation is recorded on the source wallet; the outcome is
+// recorded as a new operation once the approval is decided.
+func (s *Service) RequestApproval(ctx context.Context, opType enum.OperationType, walletID uuid.UUID, destinationID *uuid.UUID, amountInCents int64) (*approval.Approval, error) {
+	wallet, err := s.getWalletOrThrow(ctx, walletID)
+	if err != nil {
 		return nil, err
 	}
 
-	return s.executeTransfer(ctx, sourceWallet, destinationWallet, request)
+	if destinationID != nil && *destinationID == walletID {
+		if err := s.handleErrorOperation(ctx, wallet, opType, -amountInCents, sameWalletRejection()); err != nil {
+			return nil, err
+		}
+		return nil, errors.SameWalletTransferNotAllowed()
+	}
+
+	return s.parkForApproval(ctx, opType, wallet, destinationID, amountInCents, nil)
+}
+
+// parkForApproval stores the approval and records the PENDING_APPROVAL
+// operation on the source wallet. It returns nil when another delivery of the
+// message parked the debit first.
+func (s *Service) parkForApproval(ctx context.Context, opType enum.OperationType, wallet *Wallet, destinationID *uuid.UUID, amountInCents int64, assessment *operation.RiskAssessment) (*approval.Approval, error) {
+	request := &approval.Approval{
+		Type:                opType,
+		WalletID:            wallet.WalletID,
+		WalletDestinationID: destinationID,
+		AmountInCents:       amountInCents,
+		OperationID:         outcomeOperationID(ctx),
+		Risk:                assessment,
+	}
+
+	pendingOp := &operation.Operation{
+		OperationID:         request.OperationID,
+		WalletID:            wallet.WalletID,
+		Type:                opType,
+		Status:              enum.OperationStatusPendingApproval,
+		AmountInCents:       -amountInCents,
+		WalletTransactionID: destinationID,
+		Reason:              "Waiting for approval",
+		Risk:                assessment,
+		CreatedAt:           time.Now(),
+	}
+
+	// The approval and its operation are written together and keyed by the
+	// operation ID, so a redelivered message cannot park the debit twice. The
+	// operation goes first, so without a transactor a duplicate stops before
+	// the approval is stored.
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		if err := s.storeOperation(ctx, pendingOp); err != nil {
+			return err
+		}
+		return s.approvals.Park(ctx, request)
+	})
+	if err == operation.ErrOperationExists {
+		slog.InfoContext(ctx, "Debit already parked, skipping", "wallet_id", wallet.WalletID, "operation_id", pendingOp.OperationID)
+		return nil, nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to park operation for approval", "wallet_id", wallet.WalletID, "error", err)
+		return nil, errors.InternalServerError("Failed to park operation for approval")
+	}
+
+	s.publishOperation(ctx, pendingOp)
+	return request, nil
+}
+
+// ExecuteApproval implements approval.Executor. The debit goes through the
+// same validations as an unparked one, so it can still fail; the risk rules
+// are skipped because a person already approved it.
+func (s *Service) ExecuteApproval(ctx context.Context, request *approval.Approval) error {
+	ctx = context.WithValue(ctx, approvedKey{}, true)
+
+	switch request.Type {
+	case enum.OperationTypeWithdraw:
+		_, err := s.Withdraw(ctx, request.WalletID, WalletTransactionRequest{
+			AmountInCents: request.AmountInCents,
+		})
+		return err
+	case enum.OperationTypeTransfer:
+		if request.WalletDestinationID == nil {
+			return errors.WalletBadRequest("Transfer approval without destination wallet")
+		}
+		_, err := s.Transfer(ctx, request.WalletID, WalletTransactionTransferRequest{
+			AmountInCents:       request.AmountInCents,
+			WalletDestinationID: *request.WalletDestinationID,
+		})
+		return err
+	default:
+		return errors.WalletBadRequest(fmt.Sprintf("Operation type %s cannot be approved", request.Type))
+	}
+}
+
+// CancelApproval implements approval.Executor by recording the rejected or
+// expired debit as an error operation
+func (s *Service) CancelApproval(ctx context.Context, request *approval.Approval) error {
+	wallet, err := s.getWalletOrThrow(ctx, request.WalletID)
+	if err != nil {
+		return err
+	}
+
+	rejection := newRejection(enum.RejectionReasonApprovalExpired, "Operation approval expired")
+	if request.Status == approval.StatusRejected {
+		rejection = newRejection(enum.RejectionReasonApprovalRejected, "Operation rejected by approver: "+request.DecisionReason)
+	}
+
+	return s.handleErrorOperation(ctx, wallet, request.Type, -request.AmountInCents, rejection)
+}
+
+// approvedKey marks debits executed after an approval
+type approvedKey struct{}
+
+// operationIDKey carries the ID a Kafka message assigns to the operation
+// recording its outcome
+type operationIDKey struct{}
+
+// withOperationID makes operationID the ID of the operation recording the
+// outcome of the request: the success, the rejection or the parked debit. The
+// operation IDs are unique, so a redelivered message cannot apply twice.
+func withOperationID(ctx context.Context, operationID uuid.UUID) context.Context {
+	if operationID == uuid.Nil {
+		return ctx
+	}
+	return context.WithValue(ctx, operationIDKey{}, operationID)
+}
+
+// outcomeOperationID returns the ID set by withOperationID, or a new one.
+// Approvals executed later start from a fresh context and get new IDs.
+func outcomeOperationID(ctx context.Context) uuid.UUID {
+	if operationID, ok := ctx.Value(operationIDKey{}).(uuid.UUID); ok {
+		return operationID
+	}
+	return uuid.New()
+}
+
+// OperationRecorded reports whether an operation with the ID is stored, that
+// is, whether the message that assigned it was already processed
+func (s *Service) OperationRecorded(ctx context.Context, operationID uuid.UUID) (bool, error) {
+	if operationID == uuid.Nil {
+		return false, nil
+	}
+	op, err := s.operationStore.FindByID(ctx, operationID)
+	if err != nil {
+		return false, err
+	}
+	return op != nil, nil
+}
+
+// assessRisk evaluates the risk rules for a debit. It returns nil without
+// rules or for approved debits.
+func (s *Service) assessRisk(ctx context.Context, tx risk.Transaction) (*operation.RiskAssessment, error) {
+	if s.riskRules == nil {
+		return nil, nil
+	}
+	if approved, _ := ctx.Value(approvedKey{}).(bool); approved {
+		return nil, nil
+	}
+
+	assessment, err := s.riskRules.Evaluate(ctx, tx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to evaluate risk rules", "wallet_id", tx.WalletID, "error", err)
+		return nil, errors.InternalServerError("Failed to evaluate risk rules")
+	}
+
+	return assessment, nil
+}
+
+// enforceRisk records denied debits as errors and parks debits under review
+// for approval. It reports whether the debit must stop; a parked debit stops
+// without error.
+func (s *Service) enforceRisk(ctx context.Context, wallet *Wallet, opType enum.OperationType, destinationID *uuid.UUID, amountInCents int64, assessment *operation.RiskAssessment) (bool, error) {
+	if assessment == nil {
+		return false, nil
+	}
+
+	switch assessment.Action {
+	case operation.RiskActionDeny:
+		err := errors.RiskDenied(hitNames(assessment))
+		recordErr := s.recordOperation(ctx, &operation.Operation{
+			OperationID:         outcomeOperationID(ctx),
+			WalletID:            wallet.WalletID,
+			Type:                opType,
+			Status:              enum.OperationStatusError,
+			AmountInCents:       -amountInCents,
+			WalletTransactionID: destinationID,
+			Reason:              err.Message,
+			Risk:                assessment,
+			Rejection:           enum.RejectionReasonRiskDenied,
+			CreatedAt:           time.Now(),
+		})
+		if recordErr != nil {
+			slog.ErrorContext(ctx, "Failed to record risk denial", "wallet_id", wallet.WalletID, "type", opType, "error", recordErr)
+			return true, errors.InternalServerError("Failed to create operation")
+		}
+		return true, err
+
+	case operation.RiskActionReview:
+		if s.approvals == nil {
+			return true, errors.RiskDenied(hitNames(assessment))
+		}
+		request, err := s.parkForApproval(ctx, opType, wallet, destinationID, amountInCents, assessment)
+		if err != nil {
+			return true, err
+		}
+		if request != nil {
+			slog.InfoContext(ctx, "Debit parked for risk review", "type", opType, "wallet_id", wallet.WalletID, "approval_id", request.ApprovalID)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func hitNames(assessment *operation.RiskAssessment) string {
+	names := make([]string, 0, len(assessment.Hits))
+	for _, hit := range assessment.Hits {
+		if hit.Action == assessment.Action {
+			names = append(names, hit.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// startSpan starts the span of a balance change
+func startSpan(ctx context.Context, name string, walletID uuid.UUID, amountInCents int64) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name, trace.WithAttributes(
+		attribute.String("wallet.id", walletID.String()),
+		attribute.Int64("wallet.amount_in_cents", amountInCents),
+	))
 }
 
 func (s *Service) getWalletOrThrow(ctx context.Context, walletID uuid.UUID) (*Wallet, error) {
@@ -238,14 +816,8 @@ func (s *Service) getWalletOrThrow(ctx context.Context, walletID uuid.UUID) (*Wa
 }
 
 func (s *Service) executeDeposit(ctx context.Context, wallet *Wallet, request WalletTransactionRequest) (*Wallet, error) {
-	wallet.IncrementCurrentAmountInCents(request.AmountInCents)
-
-	if err := s.store.Update(ctx, wallet); err != nil {
-		return nil, errors.InternalServerError("Failed to update wallet")
-	}
-
 	op := &operation.Operation{
-		OperationID:   uuid.New(),
+		OperationID:   outcomeOperationID(ctx),
 		WalletID:      wallet.WalletID,
 		Type:          enum.OperationTypeDeposit,
 		Status:        enum.OperationStatusSuccess,
@@ -254,52 +826,69 @@ func (s *Service) executeDeposit(ctx context.Context, wallet *Wallet, request Wa
 		CreatedAt:     time.Now(),
 	}
 
-	if err := s.operationStore.Create(ctx, op); err != nil {
-		return nil, errors.InternalServerError("Failed to create operation")
+	// The balance and the operation are written together, so an operation ID
+	// is recorded exactly when its change was applied
+	var deposited *Wallet
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		updated, err := s.addToBalance(ctx, wallet.WalletID, request.AmountInCents)
+		if err != nil {
+			return err
+		}
+		deposited = updated
+		return s.storeOperation(ctx, op)
+	})
+	if err == operation.ErrOperationExists {
+		return s.alreadyApplied(ctx, wallet.WalletID, op.OperationID)
 	}
-
-	return wallet, nil
-}
-
-func (s *Service) executeWithdraw(ctx context.Context, wallet *Wallet, request WalletTransactionRequest) (*Wallet, error) {
-	wallet.DecreaseCurrentAmountInCents(request.AmountInCents)
-
-	if err := s.store.Update(ctx, wallet); err != nil {
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to deposit", "wallet_id", wallet.WalletID, "error", err)
 		return nil, errors.InternalServerError("Failed to update wallet")
 	}
 
+	s.publishOperation(ctx, op)
+	return deposited, nil
+}
+
+func (s *Service) executeWithdraw(ctx context.Context, wallet *Wallet, request WalletTransactionRequest, assessment *operation.RiskAssessment) (*Wallet, error) {
 	op := &operation.Operation{
-		OperationID:   uuid.New(),
+		OperationID:   outcomeOperationID(ctx),
 		WalletID:      wallet.WalletID,
 		Type:          enum.OperationTypeWithdraw,
 		Status:        enum.OperationStatusSuccess,
 		AmountInCents: -request.AmountInCents,
 		Reason:        "Withdraw success!",
+		Risk:          assessment,
 		CreatedAt:     time.Now(),
 	}
 
-	if err := s.operationStore.Create(ctx, op); err != nil {
-		return nil, errors.InternalServerError("Failed to create operation")
+	var withdrawn *Wallet
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		updated, err := s.addToBalance(ctx, wallet.WalletID, -request.AmountInCents)
+		if err != nil {
+			return err
+		}
+		withdrawn = updated
+		return s.storeOperation(ctx, op)
+	})
+	if err == ErrInsufficientBalance {
+		return nil, s.rejectDebit(ctx, wallet, enum.OperationTypeWithdraw, "Source wallet", request.AmountInCents)
+	}
+	if err == operation.ErrOperationExists {
+		return s.alreadyApplied(ctx, wallet.WalletID, op.OperationID)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to withdraw", "wallet_id", wallet.WalletID, "error", err)
+		return nil, errors.InternalServerError("Failed to update wallet")
 	}
 
-	return wallet, nil
+	s.publishOperation(ctx, op)
+	return withdrawn, nil
 }
 
-func (s *Service) executeTransfer(ctx context.Context, sourceWallet, destinationWallet *Wallet, request WalletTransactionTransferRequest) (*Wallet, error) {
-	operationIDSource := uuid.New()
+func (s *Service) executeTransfer(ctx context.Context, sourceWallet, destinationWallet *Wallet, request WalletTransactionTransferRequest, assessment *operation.RiskAssessment) (*Wallet, error) {
+	operationIDSource := outcomeOperationID(ctx)
 	operationIDDestination := uuid.New()
 
-	sourceWallet.DecreaseCurrentAmountInCents(request.AmountInCents)
-	destinationWallet.IncrementCurrentAmountInCents(request.AmountInCents)
-
-	if err := s.store.Update(ctx, sourceWallet); err != nil {
-		return nil, errors.InternalServerError("Failed to update source wallet")
-	}
-
-	if err := s.store.Update(ctx, destinationWallet); err != nil {
-		return nil, errors.InternalServerError("Failed to update destination wallet")
-	}
-
 	transferOp := &operation.Operation{
 		OperationID:            operationIDSource,
 		WalletID:               sourceWallet.WalletID,
@@ -309,6 +898,7 @@ func (s *Service) executeTransfer(ctx context.Context, sourceWallet, destination
 		WalletTransactionID:    &destinationWallet.WalletID,
 		OperationTransactionID: &operationIDDestination,
 		Reason:                 "Transfer success!",
+		Risk:                   assessment,
 		CreatedAt:              time.Now(),
 	}
 
@@ -324,32 +914,162 @@ func (s *Service) executeTransfer(ctx context.Context, sourceWallet, destination
 		CreatedAt:              time.Now(),
 	}
 
-	if err := s.operationStore.Create(ctx, transferOp); err != nil {
-		return nil, errors.InternalServerError("Failed to create transfer operation")
+	// Both balances and both operations are written together, so no money is
+	// lost between the wallets
+	var debited *Wallet
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		updated, err := s.addToBalance(ctx, sourceWallet.WalletID, -request.AmountInCents)
+		if err != nil {
+			return err
+		}
+		debited = updated
+
+		if _, err := s.addToBalance(ctx, destinationWallet.WalletID, request.AmountInCents); err != nil {
+			return err
+		}
+		if err := s.storeOperation(ctx, transferOp); err != nil {
+			return err
+		}
+		return s.storeOperation(ctx, receiveOp)
+	})
+	if err == ErrInsufficientBalance {
+		return nil, s.rejectDebit(ctx, sourceWallet, enum.OperationTypeTransfer, "Source wallet", request.AmountInCents)
+	}
+	if err == operation.ErrOperationExists {
+		return s.alreadyApplied(ctx, sourceWallet.WalletID, transferOp.OperationID)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to transfer", "wallet_id", sourceWallet.WalletID,
+			"destination_wallet_id", destinationWallet.WalletID, "error", err)
+		return nil, errors.InternalServerError("Failed to transfer between wallets")
+	}
+
+	s.publishOperation(ctx, transferOp)
+	s.publishOperation(ctx, receiveOp)
+	return debited, nil
+}
+
+// addToBalance changes the balance atomically in the store; a missing wallet
+// is an error, since it was read before
+func (s *Service) addToBalance(ctx context.Context, walletID uuid.UUID, amountInCents int64) (*Wallet, error) {
+	wallet, err := s.store.AddToBalance(ctx, walletID, amountInCents, auth.ActorFromContext(ctx))
+	if err != nil {
+		return nil, err
 	}
+	if wallet == nil {
+		return nil, fmt.Errorf("wallet %s not found", walletID)
+	}
+	return wallet, nil
+}
 
-	if err := s.operationStore.Create(ctx, receiveOp); err != nil {
-		return nil, errors.InternalServerError("Failed to create receive operation")
+// alreadyApplied answers a change whose operation ID another delivery of the
+// message recorded first. The transaction was rolled back, so the wallet is
+// returned as it is.
+func (s *Service) alreadyApplied(ctx context.Context, walletID, operationID uuid.UUID) (*Wallet, error) {
+	slog.InfoContext(ctx, "Operation already recorded, skipping", "wallet_id", walletID, "operation_id", operationID)
+	return s.getWalletOrThrow(ctx, walletID)
+}
+
+// rejectDebit records a debit the store rejected. The balance was validated
+// before, but a debit of another process may have spent it meanwhile.
+func (s *Service) rejectDebit(ctx context.Context, wallet *Wallet, opType enum.OperationType, context string, amountInCents int64) error {
+	rejection := s.validator.InsufficientBalance(context)
+	if err := s.handleErrorOperation(ctx, wallet, opType, -amountInCents, rejection); err != nil {
+		return err
 	}
+	return rejection.Err
+}
 
-	return sourceWallet, nil
+// sameWalletRejection records a transfer to its own source wallet; the caller
+// answers errors.SameWalletTransferNotAllowed
+func sameWalletRejection() *Rejection {
+	return newRejection(enum.RejectionReasonSameWallet, "Cannot process transaction. The source and destination wallets must be different!")
 }
 
-func (s *Service) handleErrorOperation(ctx context.Context, wallet *Wallet, opType enum.OperationType, amountInCents int64, message string) {
+// handleErrorOperation records a rejected request as an error operation. A
+// failed write is a server error, so the caller does not answer the rejection
+// and a Kafka message is retried until its outcome is recorded.
+func (s *Service) handleErrorOperation(ctx context.Context, wallet *Wallet, opType enum.OperationType, amountInCents int64, rejection *Rejection) error {
 	if wallet == nil {
-		return
+		return nil
 	}
 
 	errorOp := &operation.Operation{
-		OperationID:   uuid.New(),
+		OperationID:   outcomeOperationID(ctx),
 		WalletID:      wallet.WalletID,
 		Type:          opType,
 		Status:        enum.OperationStatusError,
 		AmountInCents: amountInCents,
-		Reason:        message,
+		Reason:        rejection.Err.Message,
+		Rejection:     rejection.Reason,
 		CreatedAt:     time.Now(),
 	}
 
-	s.operationStore.Create(ctx, errorOp)
-	s.store.Update(ctx, wallet)
+	if err := s.recordOperation(ctx, errorOp); err != nil {
+		slog.ErrorContext(ctx, "Failed to record error operation", "wallet_id", wallet.WalletID, "type", opType, "error", err)
+		return errors.InternalServerError("Failed to create operation")
+	}
+	return nil
+}
+
+// recordOperation stores the operation and then notifies publishers. The
+// notification happens after the write, so publishers never see operations
+// that were not stored.
+func (s *Service) recordOperation(ctx context.Context, op *operation.Operation) error {
+	err := s.storeOperation(ctx, op)
+	if err == operation.ErrOperationExists {
+		// Another delivery of the message recorded the outcome first
+		slog.InfoContext(ctx, "Operation already recorded, skipping", "wallet_id", op.WalletID, "operation_id", op.OperationID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.publishOperation(ctx, op)
+	return nil
+}
+
+// storeOperation stamps the acting principal and request ID and persists the
+// operation. Inside a transaction, publishOperation is called once it commits.
+func (s *Service) storeOperation(ctx context.Context, op *operation.Operation) error {
+	if op.Actor == nil {
+		op.Actor = auth.ActorFromContext(ctx)
+	}
+	if op.RequestID == "" {
+		op.RequestID = requestinfo.FromContext(ctx).RequestID
+	}
+
+	return s.operationStore.Create(ctx, op)
+}
+
+// publishOperation counts a stored operation and notifies the publishers
+func (s *Service) publishOperation(ctx context.Context, op *operation.Operation) {
+	metrics.ObserveOperation(string(op.Type), string(op.Status))
+	if op.Status == enum.OperationStatusError {
+		metrics.ObserveRejection(string(op.Type), rejectionReason(op))
+	}
+
+	for _, publisher := range s.publishers {
+		if err := publisher.Publish(ctx, op); err != nil {
+			slog.ErrorContext(ctx, "Failed to publish operation", "operation_id", op.OperationID, "error", err)
+		}
+	}
+}
+
+// inTransaction runs fn in a transaction, or directly without a transactor
+func (s *Service) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.transactor == nil {
+		return fn(ctx)
+	}
+	return s.transactor.WithTransaction(ctx, fn)
+}
+
+// rejectionReason is the metric label of an error operation. Operations
+// recorded without a reason count as other.
+func rejectionReason(op *operation.Operation) string {
+	if op.Rejection == "" {
+		return string(enum.RejectionReasonOther)
+	}
+	return string(op.Rejection)
 }
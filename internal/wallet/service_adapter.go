@@ -2,7 +2,10 @@ package wallet
 
 import (
 	"context"
-	"log"
+	"log/slog"
+
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/errors"
 
 	"github.com/google/uuid"
 )
@@ -35,38 +38,71 @@ func (sa *ServiceAdapter) Transfer(ctx context.Context, sourceID uuid.UUID, requ
 	return err
 }
 
-func (sa *ServiceAdapter) DepositFromKafka(ctx context.Context, walletID uuid.UUID, amountInCents int64) error {
+func (sa *ServiceAdapter) DepositFromKafka(ctx context.Context, operationID, walletID uuid.UUID, amountInCents int64) error {
+	ctx, skip, err := sa.begin(ctx, operationID)
+	if skip || err != nil {
+		return err
+	}
+
 	request := WalletTransactionRequest{
 		AmountInCents: amountInCents,
 	}
 	return sa.Deposit(ctx, walletID, request)
 }
 
-func (sa *ServiceAdapter) WithdrawFromKafka(ctx context.Context, walletID uuid.UUID, amountInCents int64) error {
-	log.Printf("ServiceAdapter.WithdrawFromKafka - walletID: %s, amount: %d", walletID, amountInCents)
+func (sa *ServiceAdapter) WithdrawFromKafka(ctx context.Context, operationID, walletID uuid.UUID, amountInCents int64) error {
+	ctx, skip, err := sa.begin(ctx, operationID)
+	if skip || err != nil {
+		return err
+	}
+
+	if sa.service.RequiresApproval(amountInCents) {
+		return sa.park(ctx, enum.OperationTypeWithdraw, walletID, nil, amountInCents)
+	}
+
 	request := WalletTransactionRequest{
 		AmountInCents: amountInCents,
 	}
-	err := sa.Withdraw(ctx, walletID, request)
-	if err != nil {
-		log.Printf("ServiceAdapter.WithdrawFromKafka - ERROR: %v", err)
-	} else {
-		log.Printf("ServiceAdapter.WithdrawFromKafka - SUCCESS")
-	}
-	return err
+	return sa.Withdraw(ctx, walletID, request)
 }
 
-func (sa *ServiceAdapter) TransferFromKafka(ctx context.Context, sourceID uuid.UUID, amountInCents int64, destinationID uuid.UUID) error {
-	log.Printf("ServiceAdapter.TransferFromKafka - sourceID: %s, amount: %d, destinationID: %s", sourceID, amountInCents, destinationID)
+func (sa *ServiceAdapter) TransferFromKafka(ctx context.Context, operationID, sourceID uuid.UUID, amountInCents int64, destinationID uuid.UUID) error {
+	ctx, skip, err := sa.begin(ctx, operationID)
+	if skip || err != nil {
+		return err
+	}
+
+	if sa.service.RequiresApproval(amountInCents) {
+		return sa.park(ctx, enum.OperationTypeTransfer, sourceID, &destinationID, amountInCents)
+	}
+
 	request := WalletTransactionTransferRequest{
 		AmountInCents:       amountInCents,
 		WalletDestinationID: destinationID,
 	}
-	err := sa.Transfer(ctx, sourceID, request)
+	return sa.Transfer(ctx, sourceID, request)
+}
+
+// begin pula mensagens cuja operação já foi gravada (reentrega ou replay da
+// DLQ) e passa o ID da mensagem para a operação que registra o resultado
+func (sa *ServiceAdapter) begin(ctx context.Context, operationID uuid.UUID) (context.Context, bool, error) {
+	recorded, err := sa.service.OperationRecorded(ctx, operationID)
 	if err != nil {
-		log.Printf("ServiceAdapter.TransferFromKafka - ERROR: %v", err)
-	} else {
-		log.Printf("ServiceAdapter.TransferFromKafka - SUCCESS")
+		return ctx, false, errors.InternalServerError("Failed to check operation")
 	}
-	return err
+	if recorded {
+		slog.InfoContext(ctx, "Skipping Kafka message already applied", "operation_id", operationID)
+		return ctx, true, nil
+	}
+	return withOperationID(ctx, operationID), false, nil
+}
+
+// park guarda o débito acima do limite até um segundo aprovador decidir
+func (sa *ServiceAdapter) park(ctx context.Context, opType enum.OperationType, walletID uuid.UUID, destinationID *uuid.UUID, amountInCents int64) error {
+	request, err := sa.service.RequestApproval(ctx, opType, walletID, destinationID, amountInCents)
+	if err != nil || request == nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Debit parked for approval", "type", opType, "wallet_id", walletID, "approval_id", request.ApprovalID)
+	return nil
 }
@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"wallet-go/internal/approval"
+	"wallet-go/internal/operation"
+	"wallet-go/internal/operation/enum"
+	"wallet-go/internal/shared/errors"
+	"wallet-go/internal/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+type recordingQueue struct {
+	parked []*approval.Approval
+}
+
+func (q *recordingQueue) RequiresApproval(amountInCents int64) bool { return true }
+
+func (q *recordingQueue) Park(ctx context.Context, request *approval.Approval) error {
+	q.parked = append(q.parked, request)
+	return nil
+}
+
+func TestRedeliveredApprovalParksOnce(t *testing.T) {
+	ctx := context.Background()
+	operations := operation.NewMemoryStore()
+	service := NewService(NewMemoryStore(operations), operations, NewValidator(), utils.NewWalletLockManager())
+	queue := &recordingQueue{}
+	service.SetApprovalQueue(queue)
+
+	wallet, err := service.Create(ctx, WalletRequest{CustomerID: "customer-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two deliveries of the same message, both past the OperationRecorded check
+	messageCtx := withOperationID(ctx, uuid.New())
+	first, err := service.RequestApproval(messageCtx, enum.OperationTypeWithdraw, wallet.WalletID, nil, 5000)
+	if err != nil || first == nil {
+		t.Fatalf("first delivery = %v, %v; want a parked approval", first, err)
+	}
+	second, err := service.RequestApproval(messageCtx, enum.OperationTypeWithdraw, wallet.WalletID, nil, 5000)
+	if err != nil || second != nil {
+		t.Fatalf("second delivery = %v, %v; want nil, nil", second, err)
+	}
+
+	if len(queue.parked) != 1 {
+		t.Fatalf("parked %d approvals, want 1", len(queue.parked))
+	}
+}
+
+// failingErrorOperations fails to store error operations
+type failingErrorOperations struct {
+	operation.Repository
+}
+
+func (f failingErrorOperations) Create(ctx context.Context, op *operation.Operation) error {
+	if op.Status == enum.OperationStatusError {
+		return fmt.Errorf("write failed")
+	}
+	return f.Repository.Create(ctx, op)
+}
+
+func TestUnrecordedRejectionIsServerError(t *testing.T) {
+	ctx := context.Background()
+	operations := operation.NewMemoryStore()
+	service := NewService(NewMemoryStore(operations), failingErrorOperations{operations}, NewValidator(), utils.NewWalletLockManager())
+
+	wallet, err := service.Create(ctx, WalletRequest{CustomerID: "customer-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An insufficient balance is a 422 only once its error operation is stored;
+	// otherwise the consumer must retry the message
+	_, err = service.Withdraw(ctx, wallet.WalletID, WalletTransactionRequest{AmountInCents: 100})
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != http.StatusInternalServerError {
+		t.Fatalf("Withdraw = %v, want a server error", err)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"wallet-go/internal/operation"
+	"wallet-go/internal/shared/auth"
 	"wallet-go/internal/shared/database"
+	"wallet-go/internal/shared/encryption"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
@@ -14,15 +16,21 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// customerIDField names the blind index of Wallet.CustomerID
+const customerIDField = "wallet.customerId"
+
 type Store struct {
 	collection          *mongo.Collection
 	operationCollection *mongo.Collection
+	// cipher encrypts CustomerID; nil stores it in plaintext
+	cipher *encryption.FieldCipher
 }
 
-func NewStore(db *database.MongoClient) *Store {
+func NewStore(db *database.MongoClient, cipher *encryption.FieldCipher) *Store {
 	return &Store{
 		collection:          db.GetCollection("wallet"),
 		operationCollection: db.GetCollection("operation"),
+		cipher:              cipher,
 	}
 }
 
@@ -30,7 +38,15 @@ func (s *Store) Create(ctx context.Context, wallet *Wallet) error {
 	wallet.CreatedAt = time.Now()
 	wallet.UpdatedAt = time.Now()
 
-	_, err := s.collection.InsertOne(ctx, wallet)
+	document, err := s.encode(ctx, wallet)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.InsertOne(ctx, document)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrWalletExists
+	}
 	return err
 }
 
@@ -46,13 +62,30 @@ func (s *Store) FindByID(ctx context.Context, walletID uuid.UUID) (*Wallet, erro
 		return nil, err
 	}
 
+	if err := s.decode(ctx, &wallet); err != nil {
+		return nil, err
+	}
+
 	return &wallet, nil
 }
 
+// FindByCustomerID looks the wallet up by blind index. Legacy documents that
+// still hold the plaintext are matched as well until they are rewrapped.
 func (s *Store) FindByCustomerID(ctx context.Context, customerID string) (*Wallet, error) {
 	var wallet Wallet
 	filter := bson.M{"customerId": customerID}
 
+	if s.cipher != nil {
+		index, err := s.cipher.BlindIndex(ctx, customerIDField, customerID)
+		if err != nil {
+			return nil, err
+		}
+		filter = bson.M{"$or": bson.A{
+			bson.M{"customerIdIndex": index},
+			bson.M{"customerId": customerID},
+		}}
+	}
+
 	err := s.collection.FindOne(ctx, filter).Decode(&wallet)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -61,6 +94,10 @@ func (s *Store) FindByCustomerID(ctx context.Context, customerID string) (*Walle
 		return nil, err
 	}
 
+	if err := s.decode(ctx, &wallet); err != nil {
+		return nil, err
+	}
+
 	// Carregar operações da carteira
 	if err := s.loadOperations(ctx, &wallet); err != nil {
 		return nil, err
@@ -70,43 +107,97 @@ func (s *Store) FindByCustomerID(ctx context.Context, customerID string) (*Walle
 }
 
 func (s *Store) FindAll(ctx context.Context) ([]*Wallet, error) {
-	fmt.Printf("DEBUG: Store.FindAll() - iniciando busca no MongoDB...\n")
-
 	cursor, err := s.collection.Find(ctx, bson.M{})
 	if err != nil {
-		fmt.Printf("DEBUG: Store.FindAll() - erro na query MongoDB: %v\n", err)
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	fmt.Printf("DEBUG: Store.FindAll() - cursor criado com sucesso, iterando...\n")
-
 	var wallets []*Wallet
 	for cursor.Next(ctx) {
 		var wallet Wallet
 		if err := cursor.Decode(&wallet); err != nil {
-			fmt.Printf("DEBUG: Store.FindAll() - erro ao decodificar wallet: %v\n", err)
 			return nil, err
 		}
 
-		fmt.Printf("DEBUG: Store.FindAll() - wallet decodificada: %s\n", wallet.WalletID)
+		if err := s.decode(ctx, &wallet); err != nil {
+			return nil, err
+		}
+
 		wallets = append(wallets, &wallet)
 	}
 
-	fmt.Printf("DEBUG: Store.FindAll() - finalizando, total de wallets: %d\n", len(wallets))
 	return wallets, cursor.Err()
 }
 
-func (s *Store) Update(ctx context.Context, wallet *Wallet) error {
+// UpdateSettings writes only the fields changed by a patch
+func (s *Store) UpdateSettings(ctx context.Context, wallet *Wallet) error {
 	wallet.UpdatedAt = time.Now()
 
-	filter := bson.M{"walletId": wallet.WalletID}
-	update := bson.M{"$set": wallet}
+	set := bson.M{
+		"active":    wallet.Active,
+		"blocked":   wallet.Blocked,
+		"timeZone":  wallet.TimeZone,
+		"updatedAt": wallet.UpdatedAt,
+	}
+	if wallet.BlockedAt != nil {
+		set["blockedAt"] = wallet.BlockedAt
+	}
+	if wallet.UnblockedAt != nil {
+		set["unblockedAt"] = wallet.UnblockedAt
+	}
+	if wallet.UpdatedBy != nil {
+		set["updatedBy"] = wallet.UpdatedBy
+	}
 
-	_, err := s.collection.UpdateOne(ctx, filter, update)
+	filter := bson.M{"walletId": wallet.WalletID}
+	_, err := s.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
 	return err
 }
 
+// AddToBalance applies the change with $inc; a debit only matches while the
+// balance covers it, so concurrent debits from other processes cannot take
+// the balance below zero
+func (s *Store) AddToBalance(ctx context.Context, walletID uuid.UUID, amountInCents int64, actor *auth.Actor) (*Wallet, error) {
+	filter := bson.M{"walletId": walletID}
+	if amountInCents < 0 {
+		filter["currentAmountInCents"] = bson.M{"$gte": -amountInCents}
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	if actor != nil {
+		set["updatedBy"] = actor
+	}
+	update := bson.M{
+		"$inc": bson.M{"currentAmountInCents": amountInCents},
+		"$set": set,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var wallet Wallet
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&wallet)
+	if err == mongo.ErrNoDocuments {
+		if amountInCents >= 0 {
+			return nil, nil
+		}
+		// Tell a missing wallet from a balance that does not cover the debit
+		existing, findErr := s.FindByIDWithoutOperations(ctx, walletID)
+		if findErr != nil || existing == nil {
+			return nil, findErr
+		}
+		return nil, ErrInsufficientBalance
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.decode(ctx, &wallet); err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
 func (s *Store) Delete(ctx context.Context, walletID uuid.UUID) error {
 	filter := bson.M{"walletId": walletID}
 
@@ -153,5 +244,120 @@ func (s *Store) FindByIDWithoutOperations(ctx context.Context, walletID uuid.UUI
 		return nil, err
 	}
 
+	if err := s.decode(ctx, &wallet); err != nil {
+		return nil, err
+	}
+
 	return &wallet, nil
 }
+
+// encode returns the document written to MongoDB: CustomerID is replaced by
+// its envelope and blind index. The envelope is kept on the wallet, so later
+// updates do not re-encrypt.
+func (s *Store) encode(ctx context.Context, wallet *Wallet) (*Wallet, error) {
+	if s.cipher == nil {
+		return wallet, nil
+	}
+
+	if wallet.CustomerIDEncrypted == nil {
+		envelope, err := s.cipher.Encrypt(ctx, wallet.CustomerID, wallet.WalletID.String())
+		if err != nil {
+			return nil, fmt.Errorf("encrypt customer ID: %w", err)
+		}
+		index, err := s.cipher.BlindIndex(ctx, customerIDField, wallet.CustomerID)
+		if err != nil {
+			return nil, fmt.Errorf("index customer ID: %w", err)
+		}
+		wallet.CustomerIDEncrypted = envelope
+		wallet.CustomerIDIndex = index
+	}
+
+	document := *wallet
+	document.CustomerID = ""
+	return &document, nil
+}
+
+// decode restores CustomerID from its envelope
+func (s *Store) decode(ctx context.Context, wallet *Wallet) error {
+	if wallet.CustomerIDEncrypted == nil {
+		return nil
+	}
+
+	if s.cipher == nil {
+		return fmt.Errorf("wallet %s has an encrypted customer ID but no encryption key is configured", wallet.WalletID)
+	}
+
+	customerID, err := s.cipher.Decrypt(ctx, wallet.CustomerIDEncrypted, wallet.WalletID.String())
+	if err != nil {
+		return fmt.Errorf("decrypt customer ID of wallet %s: %w", wallet.WalletID, err)
+	}
+
+	wallet.CustomerID = customerID
+	return nil
+}
+
+// RewrapResult counts the documents changed by RewrapCustomerIDs
+type RewrapResult struct {
+	Scanned   int `json:"scanned"`
+	Encrypted int `json:"encrypted"`
+	Rewrapped int `json:"rewrapped"`
+}
+
+// RewrapCustomerIDs encrypts legacy plaintext customer IDs and rewraps data
+// keys wrapped with retired keys. Only the encryption fields are written, so
+// it is safe to run while the API is serving traffic.
+func (s *Store) RewrapCustomerIDs(ctx context.Context) (*RewrapResult, error) {
+	if s.cipher == nil {
+		return nil, fmt.Errorf("no encryption key is configured")
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := &RewrapResult{}
+	for cursor.Next(ctx) {
+		var wallet Wallet
+		if err := cursor.Decode(&wallet); err != nil {
+			return nil, err
+		}
+		result.Scanned++
+
+		filter := bson.M{"walletId": wallet.WalletID}
+		var update bson.M
+
+		switch {
+		case wallet.CustomerIDEncrypted == nil:
+			if _, err := s.encode(ctx, &wallet); err != nil {
+				return nil, err
+			}
+			update = bson.M{
+				"$set": bson.M{
+					"customerIdEnc":   wallet.CustomerIDEncrypted,
+					"customerIdIndex": wallet.CustomerIDIndex,
+				},
+				"$unset": bson.M{"customerId": ""},
+			}
+			result.Encrypted++
+
+		case s.cipher.NeedsRewrap(wallet.CustomerIDEncrypted):
+			envelope, err := s.cipher.Rewrap(ctx, wallet.CustomerIDEncrypted)
+			if err != nil {
+				return nil, fmt.Errorf("rewrap wallet %s: %w", wallet.WalletID, err)
+			}
+			update = bson.M{"$set": bson.M{"customerIdEnc": envelope}}
+			result.Rewrapped++
+
+		default:
+			continue
+		}
+
+		if _, err := s.collection.UpdateOne(ctx, filter, update); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, cursor.Err()
+}
@@ -4,30 +4,42 @@ import (
 	"time"
 
 	"wallet-go/internal/operation"
+	"wallet-go/internal/shared/auth"
+	"wallet-go/internal/shared/encryption"
 
 	"github.com/google/uuid"
 )
 
 type Wallet struct {
 	WalletID             uuid.UUID             `bson:"walletId" json:"walletId"`
-	CustomerID           string                `bson:"customerId" json:"customerId"`
+	CustomerID           string                `bson:"customerId,omitempty" json:"customerId"` // plaintext only in legacy documents or without encryption
+	CustomerIDEncrypted  *encryption.Envelope  `bson:"customerIdEnc,omitempty" json:"-"`
+	CustomerIDIndex      string                `bson:"customerIdIndex,omitempty" json:"-"`
 	CurrentAmountInCents int64                 `bson:"currentAmountInCents" json:"currentAmountInCents"`
 	Operations           []operation.Operation `bson:"-" json:"operations,omitempty"` // ← NÃO salvar no MongoDB (bson:"-")
 	Active               bool                  `bson:"active" json:"active"`
 	Blocked              bool                  `bson:"blocked" json:"blocked"`
+	TimeZone             string                `bson:"timeZone,omitempty" json:"timeZone,omitempty"`
 	CreatedAt            time.Time             `bson:"createdAt" json:"createdAt"`
 	UpdatedAt            time.Time             `bson:"updatedAt" json:"updatedAt"`
 	BlockedAt            *time.Time            `bson:"blockedAt,omitempty" json:"blockedAt,omitempty"`
 	UnblockedAt          *time.Time            `bson:"unblockedAt,omitempty" json:"unblockedAt,omitempty"`
+	CreatedBy            *auth.Actor           `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	UpdatedBy            *auth.Actor           `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
 }
 
 type WalletRequest struct {
 	CustomerID string `json:"customerId" validate:"required" binding:"required"`
+	TimeZone   string `json:"timeZone,omitempty"`
 }
 
 type WalletPatch struct {
-	Active  *bool `json:"active,omitempty"`
-	Blocked *bool `json:"blocked,omitempty"`
+	Active   *bool   `json:"active,omitempty"`
+	Blocked  *bool   `json:"blocked,omitempty"`
+	TimeZone *string `json:"timeZone,omitempty"`
+	// Reason is required to block, unblock or deactivate and is stored in the
+	// audit log
+	Reason string `json:"reason,omitempty"`
 }
 
 type WalletTransactionRequest struct {
@@ -39,15 +51,45 @@ type WalletTransactionTransferRequest struct {
 	WalletDestinationID uuid.UUID `json:"walletDestinationId" validate:"required" binding:"required"`
 }
 
+// AdjustmentRequest is a manual correction of a balance. A positive amount
+// credits the wallet and a negative one debits it.
+type AdjustmentRequest struct {
+	AmountInCents int64  `json:"amountInCents"`
+	Reason        string `json:"reason"`
+}
+
+// Reconciliation is the result of comparing wallet balances with their
+// operations
+type Reconciliation struct {
+	CheckedAt     time.Time              `json:"checkedAt"`
+	WalletCount   int                    `json:"walletCount"`
+	MismatchCount int                    `json:"mismatchCount"`
+	Balanced      bool                   `json:"balanced"`
+	Wallets       []WalletReconciliation `json:"wallets"`
+}
+
+type WalletReconciliation struct {
+	WalletID          uuid.UUID `json:"walletId"`
+	BalanceInCents    int64     `json:"balanceInCents"`
+	LedgerInCents     int64     `json:"ledgerInCents"`
+	OperationCount    int64     `json:"operationCount"`
+	DifferenceInCents int64     `json:"differenceInCents"`
+	Balanced          bool      `json:"balanced"`
+}
+
 type WalletKafkaTransactionMessage struct {
-	WalletID      uuid.UUID `json:"walletId"`
-	AmountInCents int64     `json:"amountInCents"`
+	OperationID   uuid.UUID   `json:"operationId"`
+	WalletID      uuid.UUID   `json:"walletId"`
+	AmountInCents int64       `json:"amountInCents"`
+	Actor         *auth.Actor `json:"actor,omitempty"`
 }
 
 type WalletKafkaTransactionTransferMessage struct {
-	WalletID            uuid.UUID `json:"walletId"`
-	AmountInCents       int64     `json:"amountInCents"`
-	WalletDestinationID uuid.UUID `json:"walletDestinationId"`
+	OperationID         uuid.UUID   `json:"operationId"`
+	WalletID            uuid.UUID   `json:"walletId"`
+	AmountInCents       int64       `json:"amountInCents"`
+	WalletDestinationID uuid.UUID   `json:"walletDestinationId"`
+	Actor               *auth.Actor `json:"actor,omitempty"`
 }
 
 type WalletResponse struct {
@@ -57,10 +99,13 @@ type WalletResponse struct {
 	Operations           []operation.Operation `json:"operations,omitempty"`
 	Active               bool                  `json:"active"`
 	Blocked              bool                  `json:"blocked"`
+	TimeZone             string                `json:"timeZone,omitempty"`
 	CreatedAt            time.Time             `json:"createdAt"`
 	UpdatedAt            time.Time             `json:"updatedAt"`
 	BlockedAt            *time.Time            `json:"blockedAt,omitempty"`
 	UnblockedAt          *time.Time            `json:"unblockedAt,omitempty"`
+	CreatedBy            *auth.Actor           `json:"createdBy,omitempty"`
+	UpdatedBy            *auth.Actor           `json:"updatedBy,omitempty"`
 }
 
 // Wallet methods
@@ -72,6 +117,11 @@ func (w *Wallet) IsBlocked() bool {
 	return w.Blocked
 }
 
+// TouchedBy records the actor of the latest change
+func (w *Wallet) TouchedBy(actor *auth.Actor) {
+	w.UpdatedBy = actor
+}
+
 func (w *Wallet) WithActive(status bool) {
 	w.Active = status
 	w.UpdatedAt = time.Now()
@@ -2,7 +2,10 @@ package wallet
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"wallet-go/internal/operation/enum"
 	"wallet-go/internal/shared/errors"
 )
 
@@ -12,44 +15,86 @@ func NewValidator() *Validator {
 	return &Validator{}
 }
 
-func (v *Validator) EnsureValidForOperation(wallet *Wallet, context string) (*Wallet, error) {
+// Rejection is a business rule a transaction broke: the error answered to the
+// caller and the reason recorded on the error operation
+type Rejection struct {
+	Reason enum.RejectionReason
+	Err    *errors.AppError
+}
+
+func newRejection(reason enum.RejectionReason, message string) *Rejection {
+	return &Rejection{
+		Reason: reason,
+		Err: &errors.AppError{
+			Code:    422,
+			Type:    "Unprocessable Entity",
+			Message: message,
+		},
+	}
+}
+
+func (v *Validator) EnsureValidForOperation(wallet *Wallet, context string) *Rejection {
 	if wallet == nil {
-		return nil, errors.WalletNotFound()
+		return &Rejection{Reason: enum.RejectionReasonOther, Err: errors.WalletNotFound()}
 	}
 
 	if !wallet.IsActive() {
-		return nil, &errors.AppError{
-			Code:    422,
-			Type:    "Unprocessable Entity",
-			Message: fmt.Sprintf("Cannot process transaction. %s is inactive!", context),
-		}
+		return newRejection(enum.RejectionReasonInactive, fmt.Sprintf("Cannot process transaction. %s is inactive!", context))
 	}
 
 	if wallet.IsBlocked() {
-		return nil, &errors.AppError{
-			Code:    422,
-			Type:    "Unprocessable Entity",
-			Message: fmt.Sprintf("Cannot process transaction. %s is blocked!", context),
-		}
+		return newRejection(enum.RejectionReasonBlocked, fmt.Sprintf("Cannot process transaction. %s is blocked!", context))
 	}
 
-	return wallet, nil
+	return nil
 }
 
-func (v *Validator) HasBalanceToDebit(wallet *Wallet, context string, amountInCents int64) error {
+func (v *Validator) HasBalanceToDebit(wallet *Wallet, context string, amountInCents int64) *Rejection {
 	if !wallet.HasBalanceToDebit(amountInCents) {
-		return &errors.AppError{
-			Code:    422,
-			Type:    "Unprocessable Entity",
-			Message: fmt.Sprintf("Cannot process transaction. Insufficient balance %s!", context),
-		}
+		return v.InsufficientBalance(context)
 	}
 	return nil
 }
 
-func (v *Validator) ValidateForDebitOperation(wallet *Wallet, context string, amountInCents int64) error {
-	if _, err := v.EnsureValidForOperation(wallet, context); err != nil {
-		return err
+// InsufficientBalance is the rejection of a debit the balance does not
+// cover, also when the store finds it out after the validation
+func (v *Validator) InsufficientBalance(context string) *Rejection {
+	return newRejection(enum.RejectionReasonInsufficientFunds, fmt.Sprintf("Cannot process transaction. Insufficient balance %s!", context))
+}
+
+func (v *Validator) ValidateForDebitOperation(wallet *Wallet, context string, amountInCents int64) *Rejection {
+	if rejection := v.EnsureValidForOperation(wallet, context); rejection != nil {
+		return rejection
 	}
 	return v.HasBalanceToDebit(wallet, context, amountInCents)
 }
+
+func (v *Validator) ValidateTimeZone(timeZone string) error {
+	if timeZone == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return errors.BadRequest("Invalid time zone. Use an IANA name such as America/Sao_Paulo")
+	}
+	return nil
+}
+
+// ValidateAdjustment requires a non-zero amount and a reason
+func (v *Validator) ValidateAdjustment(request AdjustmentRequest) error {
+	if request.AmountInCents == 0 {
+		return errors.BadRequest("The adjustment amount must not be zero")
+	}
+	if strings.TrimSpace(request.Reason) == "" {
+		return errors.BadRequest("A reason is required to adjust a wallet")
+	}
+	return nil
+}
+
+// ValidatePatchReason requires a reason to block, unblock or deactivate
+func (v *Validator) ValidatePatchReason(patch WalletPatch) error {
+	statusChange := patch.Blocked != nil || (patch.Active != nil && !*patch.Active)
+	if statusChange && strings.TrimSpace(patch.Reason) == "" {
+		return errors.BadRequest("A reason is required to block, unblock or deactivate a wallet")
+	}
+	return nil
+}
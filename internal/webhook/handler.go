@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"net/http"
+
+	"wallet-go/internal/shared/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// CreateSubscription godoc
+// @Summary Create webhook subscription
+// @Description Subscribe a URL to wallet events such as deposit.success, transfer.* or *.error. The signing secret is only returned here.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param request body SubscriptionRequest true "Subscription request"
+// @Success 201 {object} SubscriptionResponse
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /webhooks/subscriptions [post]
+func (h *Handler) CreateSubscription(c *gin.Context) {
+	var request SubscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	subscription, err := h.service.CreateSubscription(c.Request.Context(), request)
+	if err != nil {
+		h.handleError(c, err, "Failed to create webhook subscription")
+		return
+	}
+
+	response := h.mapToResponse(subscription)
+	response.Secret = subscription.Secret
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Tags Webhook
+// @Produce json
+// @Success 200 {array} SubscriptionResponse
+// @Failure 500 {object} object{error=string,message=string}
+// @Router /webhooks/subscriptions [get]
+func (h *Handler) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.service.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to list webhook subscriptions")
+		return
+	}
+
+	responses := make([]SubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		responses[i] = *h.mapToResponse(subscription)
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetSubscription godoc
+// @Summary Get webhook subscription
+// @Tags Webhook
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /webhooks/subscriptions/{id} [get]
+func (h *Handler) GetSubscription(c *gin.Context) {
+	subscriptionID, ok := h.parseID(c, "id", "Invalid subscription ID")
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.GetSubscription(c.Request.Context(), subscriptionID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.mapToResponse(subscription))
+}
+
+// PatchSubscription godoc
+// @Summary Update webhook subscription
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param request body SubscriptionPatch true "Subscription patch"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /webhooks/subscriptions/{id} [patch]
+func (h *Handler) PatchSubscription(c *gin.Context) {
+	subscriptionID, ok := h.parseID(c, "id", "Invalid subscription ID")
+	if !ok {
+		return
+	}
+
+	var patch SubscriptionPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	subscription, err := h.service.PatchSubscription(c.Request.Context(), subscriptionID, patch)
+	if err != nil {
+		h.handleError(c, err, "Failed to update webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.mapToResponse(subscription))
+}
+
+// DeleteSubscription godoc
+// @Summary Delete webhook subscription
+// @Tags Webhook
+// @Param id path string true "Subscription ID"
+// @Success 204
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /webhooks/subscriptions/{id} [delete]
+func (h *Handler) DeleteSubscription(c *gin.Context) {
+	subscriptionID, ok := h.parseID(c, "id", "Invalid subscription ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSubscription(c.Request.Context(), subscriptionID); err != nil {
+		h.handleError(c, err, "Failed to delete webhook subscription")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Latest deliveries of a subscription, with the log of every attempt
+// @Tags Webhook
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {array} Delivery
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /webhooks/subscriptions/{id}/deliveries [get]
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	subscriptionID, ok := h.parseID(c, "id", "Invalid subscription ID")
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.service.ListDeliveries(c.Request.Context(), subscriptionID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list webhook deliveries")
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []*Delivery{}
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetDelivery godoc
+// @Summary Get webhook delivery
+// @Tags Webhook
+// @Produce json
+// @Param deliveryId path string true "Delivery ID"
+// @Success 200 {object} Delivery
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /webhooks/deliveries/{deliveryId} [get]
+func (h *Handler) GetDelivery(c *gin.Context) {
+	deliveryID, ok := h.parseID(c, "deliveryId", "Invalid delivery ID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.GetDelivery(c.Request.Context(), deliveryID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get webhook delivery")
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver godoc
+// @Summary Redeliver webhook
+// @Description Queue a delivery again with a fresh retry budget
+// @Tags Webhook
+// @Produce json
+// @Param deliveryId path string true "Delivery ID"
+// @Success 202 {object} Delivery
+// @Failure 400 {object} object{error=string,message=string}
+// @Failure 404 {object} object{error=string,message=string}
+// @Router /webhooks/deliveries/{deliveryId}/redeliver [post]
+func (h *Handler) Redeliver(c *gin.Context) {
+	deliveryID, ok := h.parseID(c, "deliveryId", "Invalid delivery ID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.Redeliver(c.Request.Context(), deliveryID)
+	if err != nil {
+		h.handleError(c, err, "Failed to redeliver webhook")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func (h *Handler) parseID(c *gin.Context, param, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.BadRequest(message))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *Handler) handleError(c *gin.Context, err error, message string) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.Code, appErr)
+		return
+	}
+	c.JSON(http.StatusInternalServerError, errors.InternalServerError(message))
+}
+
+func (h *Handler) mapToResponse(subscription *Subscription) *SubscriptionResponse {
+	return &SubscriptionResponse{
+		ID:        subscription.SubscriptionID,
+		URL:       subscription.URL,
+		Events:    subscription.Events,
+		WalletID:  subscription.WalletID,
+		Active:    subscription.Active,
+		CreatedAt: subscription.CreatedAt,
+		UpdatedAt: subscription.UpdatedAt,
+	}
+}
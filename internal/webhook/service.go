@@ -0,0 +1,219 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"wallet-go/internal/operation"
+	"wallet-go/internal/shared/errors"
+
+	"github.com/google/uuid"
+)
+
+const deliveryListLimit = 100
+
+type Service struct {
+	store *Store
+}
+
+func NewService(store *Store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+func (s *Service) CreateSubscription(ctx context.Context, request SubscriptionRequest) (*Subscription, error) {
+	secret := request.Secret
+	if secret == "" {
+		generated, err := generateSecret()
+		if err != nil {
+			return nil, errors.InternalServerError("Failed to generate webhook secret")
+		}
+		secret = generated
+	}
+
+	subscription := &Subscription{
+		SubscriptionID: uuid.New(),
+		URL:            request.URL,
+		Events:         request.Events,
+		WalletID:       request.WalletID,
+		Secret:         secret,
+		Active:         true,
+	}
+
+	if err := s.store.CreateSubscription(ctx, subscription); err != nil {
+		return nil, errors.InternalServerError("Failed to create webhook subscription")
+	}
+
+	return subscription, nil
+}
+
+func (s *Service) GetSubscription(ctx context.Context, subscriptionID uuid.UUID) (*Subscription, error) {
+	subscription, err := s.store.FindSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to get webhook subscription")
+	}
+
+	if subscription == nil {
+		return nil, errors.WebhookSubscriptionNotFound()
+	}
+
+	return subscription, nil
+}
+
+func (s *Service) ListSubscriptions(ctx context.Context) ([]*Subscription, error) {
+	subscriptions, err := s.store.FindSubscriptions(ctx, false)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to list webhook subscriptions")
+	}
+
+	return subscriptions, nil
+}
+
+func (s *Service) PatchSubscription(ctx context.Context, subscriptionID uuid.UUID, patch SubscriptionPatch) (*Subscription, error) {
+	subscription, err := s.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.URL != nil {
+		subscription.URL = *patch.URL
+	}
+
+	if len(patch.Events) > 0 {
+		subscription.Events = patch.Events
+	}
+
+	if patch.Active != nil {
+		subscription.Active = *patch.Active
+	}
+
+	if err := s.store.UpdateSubscription(ctx, subscription); err != nil {
+		return nil, errors.InternalServerError("Failed to update webhook subscription")
+	}
+
+	return subscription, nil
+}
+
+func (s *Service) DeleteSubscription(ctx context.Context, subscriptionID uuid.UUID) error {
+	if _, err := s.GetSubscription(ctx, subscriptionID); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteSubscription(ctx, subscriptionID); err != nil {
+		return errors.InternalServerError("Failed to delete webhook subscription")
+	}
+
+	return nil
+}
+
+func (s *Service) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID) ([]*Delivery, error) {
+	if _, err := s.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.store.FindDeliveriesBySubscriptionID(ctx, subscriptionID, deliveryListLimit)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to list webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+func (s *Service) GetDelivery(ctx context.Context, deliveryID uuid.UUID) (*Delivery, error) {
+	delivery, err := s.store.FindDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, errors.InternalServerError("Failed to get webhook delivery")
+	}
+
+	if delivery == nil {
+		return nil, errors.WebhookDeliveryNotFound()
+	}
+
+	return delivery, nil
+}
+
+func (s *Service) Redeliver(ctx context.Context, deliveryID uuid.UUID) (*Delivery, error) {
+	if _, err := s.GetDelivery(ctx, deliveryID); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.ResetForRedelivery(ctx, deliveryID); err != nil {
+		return nil, errors.InternalServerError("Failed to schedule webhook redelivery")
+	}
+
+	return s.GetDelivery(ctx, deliveryID)
+}
+
+// Publish implements wallet.OperationPublisher by queueing one delivery per
+// matching subscription. Sending happens in the Worker.
+func (s *Service) Publish(ctx context.Context, op *operation.Operation) error {
+	subscriptions, err := s.store.FindSubscriptions(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	eventType := EventTypeFor(op)
+
+	var body []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(eventType, op.WalletID) {
+			continue
+		}
+
+		if body == nil {
+			body, err = json.Marshal(buildPayload(op, eventType))
+			if err != nil {
+				return err
+			}
+		}
+
+		delivery := &Delivery{
+			DeliveryID:     uuid.New(),
+			SubscriptionID: subscription.SubscriptionID,
+			EventID:        op.OperationID,
+			EventType:      eventType,
+			Payload:        string(body),
+			Status:         DeliveryStatusPending,
+			NextAttemptAt:  time.Now(),
+			Log:            []DeliveryAttempt{},
+		}
+
+		if err := s.store.CreateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func buildPayload(op *operation.Operation, eventType string) EventPayload {
+	return EventPayload{
+		ID:        op.OperationID,
+		Type:      eventType,
+		CreatedAt: op.CreatedAt,
+		Data: operation.OperationResponse{
+			ID:                     op.OperationID,
+			WalletID:               op.WalletID,
+			Type:                   op.Type,
+			Status:                 op.Status,
+			AmountInCents:          op.AmountInCents,
+			WalletTransactionID:    op.WalletTransactionID,
+			OperationTransactionID: op.OperationTransactionID,
+			Reason:                 op.Reason,
+			CreatedAt:              op.CreatedAt,
+			UpdatedAt:              op.UpdatedAt,
+		},
+	}
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,201 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"wallet-go/internal/shared/database"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Store struct {
+	subscriptions *mongo.Collection
+	deliveries    *mongo.Collection
+}
+
+func NewStore(db *database.MongoClient) *Store {
+	return &Store{
+		subscriptions: db.GetCollection("webhook_subscription"),
+		deliveries:    db.GetCollection("webhook_delivery"),
+	}
+}
+
+func (s *Store) CreateSubscription(ctx context.Context, subscription *Subscription) error {
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+
+	_, err := s.subscriptions.InsertOne(ctx, subscription)
+	return err
+}
+
+func (s *Store) FindSubscriptionByID(ctx context.Context, subscriptionID uuid.UUID) (*Subscription, error) {
+	var subscription Subscription
+	filter := bson.M{"subscriptionId": subscriptionID}
+
+	err := s.subscriptions.FindOne(ctx, filter).Decode(&subscription)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (s *Store) FindSubscriptions(ctx context.Context, activeOnly bool) ([]*Subscription, error) {
+	filter := bson.M{}
+	if activeOnly {
+		filter["active"] = true
+	}
+
+	cursor, err := s.subscriptions.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptions []*Subscription
+	for cursor.Next(ctx) {
+		var subscription Subscription
+		if err := cursor.Decode(&subscription); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	return subscriptions, cursor.Err()
+}
+
+func (s *Store) UpdateSubscription(ctx context.Context, subscription *Subscription) error {
+	subscription.UpdatedAt = time.Now()
+
+	filter := bson.M{"subscriptionId": subscription.SubscriptionID}
+	update := bson.M{"$set": subscription}
+
+	_, err := s.subscriptions.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (s *Store) DeleteSubscription(ctx context.Context, subscriptionID uuid.UUID) error {
+	filter := bson.M{"subscriptionId": subscriptionID}
+
+	_, err := s.subscriptions.DeleteOne(ctx, filter)
+	return err
+}
+
+func (s *Store) CreateDelivery(ctx context.Context, delivery *Delivery) error {
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = delivery.CreatedAt
+
+	_, err := s.deliveries.InsertOne(ctx, delivery)
+	return err
+}
+
+func (s *Store) FindDeliveryByID(ctx context.Context, deliveryID uuid.UUID) (*Delivery, error) {
+	var delivery Delivery
+	filter := bson.M{"deliveryId": deliveryID}
+
+	err := s.deliveries.FindOne(ctx, filter).Decode(&delivery)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (s *Store) FindDeliveriesBySubscriptionID(ctx context.Context, subscriptionID uuid.UUID, limit int64) ([]*Delivery, error) {
+	filter := bson.M{"subscriptionId": subscriptionID}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
+
+	cursor, err := s.deliveries.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []*Delivery
+	for cursor.Next(ctx) {
+		var delivery Delivery
+		if err := cursor.Decode(&delivery); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, cursor.Err()
+}
+
+// ClaimDueDelivery atomically leases one pending delivery whose next attempt is
+// due, so several workers can poll the same collection without double sends
+func (s *Store) ClaimDueDelivery(ctx context.Context, now time.Time, lease time.Duration) (*Delivery, error) {
+	filter := bson.M{
+		"status":        DeliveryStatusPending,
+		"nextAttemptAt": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"lockedUntil": bson.M{"$exists": false}},
+			bson.M{"lockedUntil": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"lockedUntil": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery Delivery
+	err := s.deliveries.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// RecordAttempt appends to the delivery log and moves the delivery to its next
+// state, releasing the lease taken by ClaimDueDelivery
+func (s *Store) RecordAttempt(ctx context.Context, deliveryID uuid.UUID, attempt DeliveryAttempt, status DeliveryStatus, nextAttemptAt time.Time) error {
+	filter := bson.M{"deliveryId": deliveryID}
+	update := bson.M{
+		"$push":  bson.M{"log": attempt},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"lockedUntil": ""},
+		"$set": bson.M{
+			"status":        status,
+			"nextAttemptAt": nextAttemptAt,
+			"updatedAt":     time.Now(),
+		},
+	}
+
+	_, err := s.deliveries.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// ResetForRedelivery puts a delivery back in the queue with a fresh attempt
+// budget while keeping its log
+func (s *Store) ResetForRedelivery(ctx context.Context, deliveryID uuid.UUID) error {
+	now := time.Now()
+	filter := bson.M{"deliveryId": deliveryID}
+	update := bson.M{
+		"$unset": bson.M{"lockedUntil": ""},
+		"$push":  bson.M{"log": DeliveryAttempt{AttemptedAt: now, Redelivery: true}},
+		"$set": bson.M{
+			"status":        DeliveryStatusPending,
+			"attempts":      0,
+			"nextAttemptAt": now,
+			"updatedAt":     now,
+		},
+	}
+
+	_, err := s.deliveries.UpdateOne(ctx, filter, update)
+	return err
+}
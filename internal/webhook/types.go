@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"wallet-go/internal/operation"
+
+	"github.com/google/uuid"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "PENDING"
+	DeliveryStatusSucceeded DeliveryStatus = "SUCCEEDED"
+	DeliveryStatusFailed    DeliveryStatus = "FAILED"
+)
+
+type Subscription struct {
+	SubscriptionID uuid.UUID  `bson:"subscriptionId" json:"subscriptionId"`
+	URL            string     `bson:"url" json:"url"`
+	Events         []string   `bson:"events" json:"events"`
+	WalletID       *uuid.UUID `bson:"walletId,omitempty" json:"walletId,omitempty"`
+	Secret         string     `bson:"secret" json:"-"`
+	Active         bool       `bson:"active" json:"active"`
+	CreatedAt      time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time  `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Matches reports whether the subscription wants an event of the given type
+// for the given wallet. Filters are "<type>.<status>" names where either part
+// may be "*", e.g. "deposit.*" or "*.error".
+func (s *Subscription) Matches(eventType string, walletID uuid.UUID) bool {
+	if !s.Active {
+		return false
+	}
+
+	if s.WalletID != nil && *s.WalletID != walletID {
+		return false
+	}
+
+	eventKind, eventStatus, _ := strings.Cut(eventType, ".")
+	for _, filter := range s.Events {
+		kind, status, _ := strings.Cut(filter, ".")
+		if (kind == "*" || kind == eventKind) && (status == "" || status == "*" || status == eventStatus) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Delivery struct {
+	DeliveryID     uuid.UUID         `bson:"deliveryId" json:"deliveryId"`
+	SubscriptionID uuid.UUID         `bson:"subscriptionId" json:"subscriptionId"`
+	EventID        uuid.UUID         `bson:"eventId" json:"eventId"`
+	EventType      string            `bson:"eventType" json:"eventType"`
+	Payload        string            `bson:"payload" json:"payload"`
+	Status         DeliveryStatus    `bson:"status" json:"status"`
+	Attempts       int               `bson:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time         `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LockedUntil    *time.Time        `bson:"lockedUntil,omitempty" json:"-"`
+	Log            []DeliveryAttempt `bson:"log" json:"log"`
+	CreatedAt      time.Time         `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time         `bson:"updatedAt" json:"updatedAt"`
+}
+
+type DeliveryAttempt struct {
+	AttemptedAt time.Time `bson:"attemptedAt" json:"attemptedAt"`
+	StatusCode  int       `bson:"statusCode,omitempty" json:"statusCode,omitempty"`
+	Error       string    `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMs  int64     `bson:"durationMs" json:"durationMs"`
+	Redelivery  bool      `bson:"redelivery,omitempty" json:"redelivery,omitempty"`
+}
+
+type SubscriptionRequest struct {
+	URL      string     `json:"url" binding:"required,url"`
+	Events   []string   `json:"events" binding:"required,min=1"`
+	WalletID *uuid.UUID `json:"walletId,omitempty"`
+	Secret   string     `json:"secret,omitempty"`
+}
+
+type SubscriptionPatch struct {
+	URL    *string  `json:"url,omitempty" binding:"omitempty,url"`
+	Events []string `json:"events,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+type SubscriptionResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	URL       string     `json:"url"`
+	Events    []string   `json:"events"`
+	WalletID  *uuid.UUID `json:"walletId,omitempty"`
+	Active    bool       `json:"active"`
+	Secret    string     `json:"secret,omitempty"` // only returned on creation
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// EventPayload is the JSON body posted to subscribers
+type EventPayload struct {
+	ID        uuid.UUID                   `json:"id"`
+	Type      string                      `json:"type"`
+	CreatedAt time.Time                   `json:"createdAt"`
+	Data      operation.OperationResponse `json:"data"`
+}
+
+// EventTypeFor names the webhook event of an operation, e.g. "transfer.error"
+func EventTypeFor(op *operation.Operation) string {
+	return fmt.Sprintf("%s.%s", strings.ToLower(string(op.Type)), strings.ToLower(string(op.Status)))
+}
@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wallet-go/internal/shared/config"
+)
+
+const maxBackoff = time.Hour
+
+// Worker sends pending deliveries, retrying failures with exponential backoff
+// until MaxAttempts is reached
+type Worker struct {
+	store  *Store
+	client *http.Client
+	config config.WebhookConfig
+}
+
+func NewWorker(store *Store, cfg config.WebhookConfig) *Worker {
+	return &Worker{
+		store:  store,
+		client: &http.Client{Timeout: cfg.RequestTimeout},
+		config: cfg,
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) {
+	slog.Info("Starting webhook delivery worker")
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Webhook delivery worker stopped")
+			return
+		case <-ticker.C:
+			w.drain(ctx)
+		}
+	}
+}
+
+// drain sends every delivery that is currently due
+func (w *Worker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		delivery, err := w.store.ClaimDueDelivery(ctx, time.Now(), 2*w.config.RequestTimeout)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error claiming webhook delivery", "error", err)
+			return
+		}
+		if delivery == nil {
+			return
+		}
+
+		w.deliver(ctx, delivery)
+	}
+}
+
+func (w *Worker) deliver(ctx context.Context, delivery *Delivery) {
+	subscription, err := w.store.FindSubscriptionByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error loading webhook subscription", "subscription_id", delivery.SubscriptionID, "error", err)
+		return
+	}
+
+	attempt := DeliveryAttempt{AttemptedAt: time.Now()}
+
+	if subscription == nil || !subscription.Active {
+		attempt.Error = "subscription deleted or inactive"
+		w.record(ctx, delivery, attempt, DeliveryStatusFailed, attempt.AttemptedAt)
+		return
+	}
+
+	statusCode, sendErr := w.send(ctx, subscription, delivery)
+	attempt.StatusCode = statusCode
+	attempt.DurationMs = time.Since(attempt.AttemptedAt).Milliseconds()
+
+	if sendErr == nil {
+		w.record(ctx, delivery, attempt, DeliveryStatusSucceeded, attempt.AttemptedAt)
+		return
+	}
+
+	attempt.Error = sendErr.Error()
+	attempts := delivery.Attempts + 1
+	if attempts >= w.config.MaxAttempts {
+		slog.WarnContext(ctx, "Webhook delivery failed permanently", "delivery_id", delivery.DeliveryID, "attempts", attempts, "error", sendErr)
+		w.record(ctx, delivery, attempt, DeliveryStatusFailed, attempt.AttemptedAt)
+		return
+	}
+
+	w.record(ctx, delivery, attempt, DeliveryStatusPending, attempt.AttemptedAt.Add(w.backoff(attempts)))
+}
+
+func (w *Worker) record(ctx context.Context, delivery *Delivery, attempt DeliveryAttempt, status DeliveryStatus, nextAttemptAt time.Time) {
+	if err := w.store.RecordAttempt(ctx, delivery.DeliveryID, attempt, status, nextAttemptAt); err != nil {
+		slog.ErrorContext(ctx, "Error recording webhook delivery", "delivery_id", delivery.DeliveryID, "error", err)
+	}
+}
+
+func (w *Worker) send(ctx context.Context, subscription *Subscription, delivery *Delivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "wallet-go-webhooks/1.0")
+	req.Header.Set("X-Wallet-Event", delivery.EventType)
+	req.Header.Set("X-Wallet-Delivery", delivery.DeliveryID.String())
+	req.Header.Set("X-Wallet-Signature", Sign(subscription.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff doubles the base delay for every failed attempt, capped at maxBackoff
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// Sign builds the X-Wallet-Signature header: "t=<unix>,v1=<hex>" where v1 is
+// HMAC-SHA256 of "<unix>.<body>" keyed with the subscription secret.
+// Receivers should recompute it and reject stale timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}